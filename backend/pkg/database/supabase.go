@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -113,10 +114,11 @@ func (p *PlaidItem) ToJSON() PlaidItemJSON {
 	}
 
 	return PlaidItemJSON{
-		ItemID:          p.ItemID,
-		InstitutionName: name,
-		Status:          p.Status,
-		LastUpdated:     p.LastUpdated,
+		ItemID:               p.ItemID,
+		InstitutionName:      name,
+		Status:               p.Status,
+		LastUpdated:          p.LastUpdated,
+		NewAccountsAvailable: p.NewAccountsAvailable,
 	}
 }
 
@@ -169,11 +171,13 @@ func (c *Client) UpdatePlaidItemAfterReconnect(ctx context.Context, existing *Pl
 	}
 
 	// Creates the payload for the update.
+	// Re-linking through Link picks up any new accounts, so the flag is cleared.
 	payload := map[string]interface{}{
-		"item_id":      newItemID,
-		"access_token": accessToken,
-		"status":       status,
-		"last_updated": lastUpdated,
+		"item_id":                newItemID,
+		"access_token":           accessToken,
+		"status":                 status,
+		"last_updated":           lastUpdated,
+		"new_accounts_available": false,
 	}
 	if institutionID != nil {
 		payload["institution_id"] = *institutionID
@@ -595,6 +599,57 @@ func (c *Client) SetItemNewTransactionsPending(ctx context.Context, itemID strin
 	return nil
 }
 
+// Sets new_accounts_available flag for a Plaid item (used by webhook).
+func (c *Client) SetItemNewAccountsAvailable(ctx context.Context, itemID string, available bool) error {
+	payload := map[string]interface{}{"new_accounts_available": available}
+	url := c.restURL("plaid_items") + "?item_id=eq." + url.QueryEscape(itemID)
+	resp, err := c.doRequest(ctx, http.MethodPatch, url, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase set new_accounts_available failed: %s", string(body))
+	}
+	return nil
+}
+
+// Sets holdings_refresh_pending flag for a Plaid item (used by webhook and holdings refresh).
+func (c *Client) SetItemHoldingsRefreshPending(ctx context.Context, itemID string, pending bool) error {
+	payload := map[string]interface{}{"holdings_refresh_pending": pending}
+	url := c.restURL("plaid_items") + "?item_id=eq." + url.QueryEscape(itemID)
+	resp, err := c.doRequest(ctx, http.MethodPatch, url, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase set holdings_refresh_pending failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns all Plaid items with a queued holdings refresh.
+func (c *Client) ListPlaidItemsWithHoldingsRefreshPending(ctx context.Context) ([]PlaidItem, error) {
+	url := c.restURL("plaid_items") + "?holdings_refresh_pending=eq.true"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list plaid_items holdings refresh pending failed: %s", string(body))
+	}
+	var items []PlaidItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 // Returns all Plaid items that received new transactions.
 func (c *Client) ListPlaidItemsWithPendingTransactions(ctx context.Context) ([]PlaidItem, error) {
 	url := c.restURL("plaid_items") + "?new_transactions_pending=eq.true"
@@ -656,21 +711,8 @@ func (c *Client) DeleteTransactionsByPlaidIDs(ctx context.Context, plaidIDs []st
 	if len(plaidIDs) == 0 {
 		return nil
 	}
-	// Constructs the query
-	var b strings.Builder
-	b.WriteString("in.(")
-	for i, id := range plaidIDs {
-		if i > 0 {
-			b.WriteString(",")
-		}
-		escaped := strings.ReplaceAll(id, "\\", "\\\\")
-		escaped = strings.ReplaceAll(escaped, "\"", "\\\"")
-		b.WriteString("\"" + escaped + "\"")
-	}
-	b.WriteString(")")
-
 	// Deletes the transactions
-	url := c.restURL("transactions") + "?plaid_transaction_id=" + url.QueryEscape(b.String())
+	url := c.restURL("transactions") + "?plaid_transaction_id=" + url.QueryEscape(inFilter(plaidIDs))
 	resp, err := c.doRequest(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
@@ -683,6 +725,110 @@ func (c *Client) DeleteTransactionsByPlaidIDs(ctx context.Context, plaidIDs []st
 	return nil
 }
 
+// Returns the stored transactions with the given Plaid transaction IDs.
+func (c *Client) ListTransactionsByPlaidIDs(ctx context.Context, plaidIDs []string) ([]Transaction, error) {
+	if len(plaidIDs) == 0 {
+		return nil, nil
+	}
+	url := c.restURL("transactions") + "?plaid_transaction_id=" + url.QueryEscape(inFilter(plaidIDs))
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list transactions by plaid ids failed: %s", string(body))
+	}
+
+	var list []Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Returns all transactions for the given accounts on or after a date, paging past the PostgREST row limit.
+func (c *Client) ListTransactionsForAccountsSince(ctx context.Context, accountIDs []string, since time.Time) ([]Transaction, error) {
+	if len(accountIDs) == 0 {
+		return nil, nil
+	}
+	const pageSize = 1000
+	baseURL := c.restURL("transactions") + "?plaid_account_id=" + url.QueryEscape(inFilter(accountIDs)) +
+		"&date=gte." + since.Format("2006-01-02") + "&order=id.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []Transaction
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list transactions for accounts failed: %s", string(body))
+		}
+
+		var page []Transaction
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Returns all transactions dated on or after start and before end, paging through the results.
+func (c *Client) ListTransactionsBetween(ctx context.Context, start, end time.Time) ([]Transaction, error) {
+	const pageSize = 1000
+	baseURL := c.restURL("transactions") + "?date=gte." + start.Format("2006-01-02") + "&date=lt." + end.Format("2006-01-02") +
+		"&order=id.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []Transaction
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list transactions between failed: %s", string(body))
+		}
+
+		var page []Transaction
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Builds a PostgREST in.(...) filter value with each entry quoted.
+func inFilter(values []string) string {
+	var b strings.Builder
+	b.WriteString("in.(")
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		escaped := strings.ReplaceAll(value, "\\", "\\\\")
+		escaped = strings.ReplaceAll(escaped, "\"", "\\\"")
+		b.WriteString("\"" + escaped + "\"")
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
 // Returns transactions for the given month, optionally filtered by category and search.
 func (c *Client) ListTransactions(ctx context.Context, f ListTransactionsFilter) ([]Transaction, error) {
 	// Build query: order by date desc
@@ -740,9 +886,9 @@ func endOfMonth(month string) string {
 	return t.Format("2006-01-02")
 }
 
-// Returns the current budget.
-func (c *Client) GetBudget(ctx context.Context) (*Budget, error) {
-	url := c.restURL("budgets") + "?id=eq.1&limit=1"
+// Returns the budget version in effect for the given month (latest effective_month on or before it), or nil if none.
+func (c *Client) GetBudgetVersionForMonth(ctx context.Context, month time.Time) (*BudgetVersion, error) {
+	url := c.restURL("budget_versions") + "?effective_month=lte." + month.Format("2006-01-02") + "&order=effective_month.desc&limit=1"
 
 	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -752,28 +898,50 @@ func (c *Client) GetBudget(ctx context.Context) (*Budget, error) {
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("supabase get budget failed: %s", string(body))
+		return nil, fmt.Errorf("supabase get budget version failed: %s", string(body))
 	}
 
-	// Decodes the response body into a Budget slice
-	var budgets []Budget
-	if err := json.NewDecoder(resp.Body).Decode(&budgets); err != nil {
+	// Decodes the response body into a BudgetVersion slice
+	var versions []BudgetVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
 		return nil, err
 	}
-	if len(budgets) == 0 {
+	if len(versions) == 0 {
 		return nil, nil
 	}
-	return &budgets[0], nil
+	return &versions[0], nil
+}
+
+// Returns every budget version, oldest effective month first.
+func (c *Client) ListBudgetVersions(ctx context.Context) ([]BudgetVersion, error) {
+	url := c.restURL("budget_versions") + "?order=effective_month.asc"
+
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list budget versions failed: %s", string(body))
+	}
+
+	var versions []BudgetVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
 }
 
-// Inserts or updates the current budget.
-func (c *Client) UpsertBudget(ctx context.Context, budget *Budget) error {
-	if budget == nil {
-		return errors.New("budget is nil")
+// Inserts or replaces the budget version effective from the version's month.
+func (c *Client) UpsertBudgetVersion(ctx context.Context, version *BudgetVersion) error {
+	if version == nil {
+		return errors.New("budget version is nil")
 	}
 
-	url := c.restURL("budgets") + "?id=eq." + fmt.Sprintf("%d", budget.ID)
-	resp, err := c.doRequest(ctx, http.MethodPatch, url, budget)
+	url := c.restURL("budget_versions") + "?on_conflict=effective_month"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, []BudgetVersion{*version})
 	if err != nil {
 		return err
 	}
@@ -781,7 +949,7 @@ func (c *Client) UpsertBudget(ctx context.Context, budget *Budget) error {
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("supabase upsert budget failed: %s", string(body))
+		return fmt.Errorf("supabase upsert budget version failed: %s", string(body))
 	}
 	return nil
 }
@@ -1242,6 +1410,48 @@ func (c *Client) ListMonthlyExpenseSummaries(ctx context.Context, startDate, end
 	return summaries, nil
 }
 
+// Upserts a monthly transfer summary.
+func (c *Client) UpsertMonthlyTransferSummary(ctx context.Context, summary *MonthlyTransferSummary) error {
+	if summary == nil {
+		return errors.New("summary is nil")
+	}
+
+	upsertURL := c.restURL("monthly_transfer_summary") + "?on_conflict=month,category_id"
+	resp, err := c.doRequest(ctx, http.MethodPost, upsertURL, summary)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert monthly_transfer_summary failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists monthly transfer summaries within a date range.
+func (c *Client) ListMonthlyTransferSummaries(ctx context.Context, startDate, endDate time.Time) ([]MonthlyTransferSummary, error) {
+	getURL := c.restURL("monthly_transfer_summary") + fmt.Sprintf("?month=gte.%s&month=lte.%s&order=month.asc", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	resp, err := c.doRequest(ctx, http.MethodGet, getURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list monthly_transfer_summary failed: %s", string(body))
+	}
+
+	var summaries []MonthlyTransferSummary
+	err = json.NewDecoder(resp.Body).Decode(&summaries)
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
 // Lists yearly expense summaries for a given year.
 func (c *Client) ListYearlyExpenseSummaries(ctx context.Context, year int) ([]YearlyExpenseSummary, error) {
 	url := c.restURL("yearly_expense_summary") + fmt.Sprintf("?year=eq.%d&order=category_id.asc", year)
@@ -1350,109 +1560,1253 @@ func (c *Client) DeleteDailyHoldingsByAccountAndDate(ctx context.Context, accoun
 	return nil
 }
 
-// Represents a row in the plaid_items table.
-type PlaidItem struct {
-	ID                     int64      `json:"id,omitempty"`
-	ItemID                 string     `json:"item_id"`
-	AccessToken            string     `json:"access_token"`
-	InstitutionID          *string    `json:"institution_id,omitempty"`
-	InstitutionName        *string    `json:"institution_name,omitempty"`
-	Status                 string     `json:"status"`
-	LastUpdated            time.Time  `json:"last_updated"`
-	CreatedAt              *time.Time `json:"created_at,omitempty"`
-	TransactionsCursor     *string    `json:"transactions_cursor,omitempty"`
-	NewTransactionsPending bool       `json:"new_transactions_pending"`
-}
+// Inserts a webhook event and returns the stored row (with its id).
+func (c *Client) InsertWebhookEvent(ctx context.Context, event *WebhookEvent) (*WebhookEvent, error) {
+	if event == nil {
+		return nil, errors.New("webhook event is nil")
+	}
 
-// The JSON-safe representation for API responses (hides access_token).
-type PlaidItemJSON struct {
-	ItemID          string    `json:"itemId"`
-	InstitutionName string    `json:"institutionName,omitempty"`
-	Status          string    `json:"status"`
-	LastUpdated     time.Time `json:"lastUpdated"`
-}
+	url := c.restURL("webhook_events")
+	resp, err := c.doRequest(ctx, http.MethodPost, url, event)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-// Represents a row in the plaid_accounts table.
-type PlaidAccount struct {
-	ID             int64      `json:"id,omitempty"`
-	PlaidItemID    string     `json:"plaid_item_id"`
-	AccountID      string     `json:"account_id"`
-	Name           string     `json:"name"`
-	Mask           *string    `json:"mask,omitempty"`
-	Type           string     `json:"type"`
-	Subtype        *string    `json:"subtype,omitempty"`
-	CurrentBalance float64    `json:"current_balance"`
-	CreatedAt      *time.Time `json:"created_at,omitempty"`
-}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase insert webhook_events failed: %s", string(body))
+	}
 
-/*
-// Represents a row in the snaptrade_user table.
-type SnaptradeUser struct {
-	ID         int64  `json:"id,omitempty"`
-	UserID     string `json:"user_id"`
-	UserSecret string `json:"user_secret"`
+	// Decodes the inserted row (Prefer: return=representation).
+	var events []WebhookEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, errors.New("supabase insert webhook_events returned no rows")
+	}
+	return &events[0], nil
 }
 
-// Represents a row in the snaptrade_connections table.
-type SnaptradeConnection struct {
-	ID         int64      `json:"id,omitempty"`
-	ConnID     string     `json:"conn_id"`
-	Brokerage  string     `json:"brokerage"`
-	Status     string     `json:"status"`
-	LastSynced *time.Time `json:"last_synced,omitempty"`
+// Returns a webhook event by its id.
+func (c *Client) GetWebhookEvent(ctx context.Context, id int64) (*WebhookEvent, error) {
+	url := c.restURL("webhook_events") + fmt.Sprintf("?id=eq.%d&limit=1", id)
+	return c.getWebhookEvent(ctx, url)
 }
 
-// The JSON-safe representation for API responses (hides conn_id).
-type SnaptradeConnectionJSON struct {
-	ID         string     `json:"id"`
-	Brokerage  string     `json:"brokerage"`
-	Status     string     `json:"status"`
-	LastSynced *time.Time `json:"lastSynced,omitempty"`
+// Returns the most recent webhook event with the given dedupe key received at or after since.
+func (c *Client) GetLatestWebhookEventByDedupeKey(ctx context.Context, dedupeKey string, since time.Time) (*WebhookEvent, error) {
+	getURL := c.restURL("webhook_events") + "?dedupe_key=eq." + url.QueryEscape(dedupeKey) +
+		"&received_at=gte." + url.QueryEscape(since.Format(time.RFC3339)) + "&order=received_at.desc&limit=1"
+	return c.getWebhookEvent(ctx, getURL)
 }
-*/
 
-// Represents a row in the categories table.
-type Category struct {
-	ID        int64   `json:"id,omitempty"`
-	Name      string  `json:"name"`
-	PlaidName *string `json:"plaid_name,omitempty"`
-	Expense   bool    `json:"expense"`
-}
+// Fetches a single webhook event for the given query URL.
+func (c *Client) getWebhookEvent(ctx context.Context, url string) (*WebhookEvent, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-// Category rule maps transaction name/merchant substring to a category.
-type CategoryRule struct {
-	ID          int64  `json:"id,omitempty"`
-	MatchString string `json:"match_string"`
-	CategoryID  int64  `json:"category_id"`
-}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase get webhook_event failed: %s", string(body))
+	}
 
-// Represents a row in the transactions table.
-type Transaction struct {
-	ID                 int64     `json:"id,omitempty"`
-	PlaidAccountID     string    `json:"plaid_account_id"`
-	PlaidTransactionID string    `json:"plaid_transaction_id"`
-	Date               DateOnly  `json:"date"`
-	AmountCents        int64     `json:"amount_cents"`
-	Name               string    `json:"name"`
-	MerchantName       *string   `json:"merchant_name"`
-	CategoryID         *int64    `json:"category_id"`
-	Pending            bool      `json:"pending"`
-	CreatedAt          time.Time `json:"created_at,omitempty"`
-	UpdatedAt          time.Time `json:"updated_at,omitempty"`
+	var events []WebhookEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0], nil
 }
 
-// Holds optional filters for listing transactions.
-type ListTransactionsFilter struct {
-	Month      string
-	CategoryID *int64
+// Lists webhook events, newest first, optionally filtered by outcome and item.
+func (c *Client) ListWebhookEvents(ctx context.Context, f ListWebhookEventsFilter) ([]WebhookEvent, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	reqURL := c.restURL("webhook_events") + fmt.Sprintf("?order=received_at.desc&limit=%d", limit)
+	if f.Outcome != "" {
+		reqURL += "&outcome=eq." + url.QueryEscape(f.Outcome)
+	}
+	if f.ItemID != "" {
+		reqURL += "&item_id=eq." + url.QueryEscape(f.ItemID)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list webhook_events failed: %s", string(body))
+	}
+
+	var events []WebhookEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Updates the processing outcome fields of a webhook event.
+func (c *Client) UpdateWebhookEventOutcome(ctx context.Context, event *WebhookEvent) error {
+	if event == nil {
+		return errors.New("webhook event is nil")
+	}
+
+	payload := map[string]interface{}{
+		"outcome":         event.Outcome,
+		"error":           event.Error,
+		"processed_at":    event.ProcessedAt,
+		"attempts":        event.Attempts,
+		"duplicate_count": event.DuplicateCount,
+	}
+
+	url := c.restURL("webhook_events") + fmt.Sprintf("?id=eq.%d", event.ID)
+	resp, err := c.doRequest(ctx, http.MethodPatch, url, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase update webhook_event failed: %s", string(body))
+	}
+	return nil
+}
+
+// Deletes webhook events received before the given date.
+func (c *Client) DeleteWebhookEventsOlderThan(ctx context.Context, cutoffDate time.Time) error {
+	cutoffStr := cutoffDate.Format("2006-01-02")
+	url := c.restURL("webhook_events") + "?received_at=lt." + cutoffStr
+
+	resp, err := c.doRequest(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete webhook_events older than failed: %s", string(body))
+	}
+	return nil
+}
+
+// Upserts investment transactions by their Plaid investment_transaction_id.
+func (c *Client) UpsertInvestmentTransactions(ctx context.Context, txns []InvestmentTransaction) error {
+	if len(txns) == 0 {
+		return nil
+	}
+	url := c.restURL("investment_transactions") + "?on_conflict=plaid_investment_transaction_id"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, txns)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert investment_transactions failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists investment transactions newest first, optionally filtered by account, symbol, type and date range.
+func (c *Client) ListInvestmentTransactions(ctx context.Context, f ListInvestmentTransactionsFilter) ([]InvestmentTransaction, error) {
+	reqURL := c.restURL("investment_transactions") + "?order=date.desc,id.desc"
+	if f.AccountID != "" {
+		reqURL += "&account_id=eq." + url.QueryEscape(f.AccountID)
+	}
+	if len(f.AccountIDs) > 0 {
+		reqURL += "&account_id=" + url.QueryEscape(inFilter(f.AccountIDs))
+	}
+	if f.Symbol != "" {
+		reqURL += "&symbol=eq." + url.QueryEscape(f.Symbol)
+	}
+	if f.Type != "" {
+		reqURL += "&type=eq." + url.QueryEscape(f.Type)
+	}
+	if f.From != nil {
+		reqURL += "&date=gte." + f.From.Format("2006-01-02")
+	}
+	if f.To != nil {
+		reqURL += "&date=lte." + f.To.Format("2006-01-02")
+	}
+	if f.Limit > 0 {
+		reqURL += "&limit=" + strconv.Itoa(f.Limit)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list investment_transactions failed: %s", string(body))
+	}
+
+	// Decodes the response body into a slice of investment transactions.
+	var list []InvestmentTransaction
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Upserts liabilities by account_id.
+func (c *Client) UpsertLiabilities(ctx context.Context, liabilities []Liability) error {
+	if len(liabilities) == 0 {
+		return nil
+	}
+	url := c.restURL("liabilities") + "?on_conflict=account_id"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, liabilities)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert liabilities failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists all stored liabilities.
+func (c *Client) ListLiabilities(ctx context.Context) ([]Liability, error) {
+	url := c.restURL("liabilities") + "?order=liability_type.asc,account_id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list liabilities failed: %s", string(body))
+	}
+
+	// Decodes the response body into a slice of liabilities.
+	var list []Liability
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Returns the per-category budget settings (rollover mode, carryover cap, reset month).
+func (c *Client) ListBudgetCategorySettings(ctx context.Context) ([]BudgetCategorySetting, error) {
+	url := c.restURL("budget_category_settings") + "?order=category_id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list budget category settings failed: %s", string(body))
+	}
+	var settings []BudgetCategorySetting
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Upserts per-category budget settings by category_id.
+func (c *Client) UpsertBudgetCategorySettings(ctx context.Context, settings []BudgetCategorySetting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	url := c.restURL("budget_category_settings") + "?on_conflict=category_id"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, settings)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert budget category settings failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns the budget alerts already sent for a month.
+func (c *Client) ListBudgetAlertsSent(ctx context.Context, month time.Time) ([]BudgetAlertSent, error) {
+	url := c.restURL("budget_alerts_sent") + "?month=eq." + month.Format("2006-01-02")
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list budget_alerts_sent failed: %s", string(body))
+	}
+	var alerts []BudgetAlertSent
+	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Records a budget alert before it is sent. Returns false if the same (month, category, threshold) alert was already recorded.
+func (c *Client) InsertBudgetAlertSent(ctx context.Context, alert *BudgetAlertSent) (bool, error) {
+	if alert == nil {
+		return false, errors.New("budget alert is nil")
+	}
+
+	url := c.restURL("budget_alerts_sent")
+	resp, err := c.doRequest(ctx, http.MethodPost, url, alert)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	// The unique (month, category_id, threshold_percent) constraint rejects repeats.
+	if resp.StatusCode == http.StatusConflict {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("supabase insert budget_alerts_sent failed: %s", string(body))
+	}
+	return true, nil
+}
+
+// Deletes a recorded budget alert (used when sending the email fails so the alert is retried).
+func (c *Client) DeleteBudgetAlertSent(ctx context.Context, month time.Time, categoryID int64, thresholdPercent int) error {
+	url := c.restURL("budget_alerts_sent") + "?month=eq." + month.Format("2006-01-02") +
+		"&category_id=eq." + strconv.FormatInt(categoryID, 10) + "&threshold_percent=eq." + strconv.Itoa(thresholdPercent)
+	resp, err := c.doRequest(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete budget_alerts_sent failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns all sinking funds, soonest due first.
+func (c *Client) ListSinkingFunds(ctx context.Context) ([]SinkingFund, error) {
+	url := c.restURL("sinking_funds") + "?order=due_date.asc,id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list sinking_funds failed: %s", string(body))
+	}
+	var funds []SinkingFund
+	if err := json.NewDecoder(resp.Body).Decode(&funds); err != nil {
+		return nil, err
+	}
+	return funds, nil
+}
+
+// Returns a sinking fund by id, or nil if not found.
+func (c *Client) GetSinkingFund(ctx context.Context, id int64) (*SinkingFund, error) {
+	url := c.restURL("sinking_funds") + "?id=eq." + strconv.FormatInt(id, 10) + "&limit=1"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase get sinking_fund failed: %s", string(body))
+	}
+	var funds []SinkingFund
+	if err := json.NewDecoder(resp.Body).Decode(&funds); err != nil {
+		return nil, err
+	}
+	if len(funds) == 0 {
+		return nil, nil
+	}
+	return &funds[0], nil
+}
+
+// Inserts a sinking fund, or updates it when ID is set. Returns the stored row.
+func (c *Client) SaveSinkingFund(ctx context.Context, fund *SinkingFund) (*SinkingFund, error) {
+	if fund == nil {
+		return nil, errors.New("sinking fund is nil")
+	}
+
+	method, url := http.MethodPost, c.restURL("sinking_funds")
+	if fund.ID != 0 {
+		method, url = http.MethodPatch, url+"?id=eq."+strconv.FormatInt(fund.ID, 10)
+	}
+	resp, err := c.doRequest(ctx, method, url, fund)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase save sinking_fund failed: %s", string(body))
+	}
+
+	// Decodes the stored row (Prefer: return=representation).
+	var funds []SinkingFund
+	if err := json.NewDecoder(resp.Body).Decode(&funds); err != nil {
+		return nil, err
+	}
+	if len(funds) == 0 {
+		return nil, errors.New("supabase save sinking_fund returned no rows")
+	}
+	return &funds[0], nil
+}
+
+// Deletes a sinking fund and its contributions.
+func (c *Client) DeleteSinkingFund(ctx context.Context, id int64) error {
+	url := c.restURL("sinking_funds") + "?id=eq." + strconv.FormatInt(id, 10)
+	resp, err := c.doRequest(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete sinking_fund failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns all manual sinking fund contributions, oldest first.
+func (c *Client) ListSinkingFundContributions(ctx context.Context) ([]SinkingFundContribution, error) {
+	url := c.restURL("sinking_fund_contributions") + "?order=date.asc,id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list sinking_fund_contributions failed: %s", string(body))
+	}
+	var contributions []SinkingFundContribution
+	if err := json.NewDecoder(resp.Body).Decode(&contributions); err != nil {
+		return nil, err
+	}
+	return contributions, nil
+}
+
+// Inserts a manual sinking fund contribution.
+func (c *Client) InsertSinkingFundContribution(ctx context.Context, contribution *SinkingFundContribution) error {
+	if contribution == nil {
+		return errors.New("sinking fund contribution is nil")
+	}
+	url := c.restURL("sinking_fund_contributions")
+	resp, err := c.doRequest(ctx, http.MethodPost, url, contribution)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase insert sinking_fund_contribution failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists every yearly portfolio summary, oldest year first.
+func (c *Client) ListAllYearlyPortfolioSummaries(ctx context.Context) ([]YearlyPortfolioSummary, error) {
+	url := c.restURL("yearly_portfolio_summary") + "?order=year.asc,account_id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list all yearly_portfolio_summary failed: %s", string(body))
+	}
+
+	var summaries []YearlyPortfolioSummary
+	err = json.NewDecoder(resp.Body).Decode(&summaries)
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// Returns all investment transactions dated on or after since, oldest first, paging past the PostgREST row limit.
+func (c *Client) ListInvestmentTransactionsSince(ctx context.Context, since time.Time) ([]InvestmentTransaction, error) {
+	const pageSize = 1000
+	baseURL := c.restURL("investment_transactions") + "?date=gte." + since.Format("2006-01-02") +
+		"&order=date.asc,id.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []InvestmentTransaction
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list investment_transactions since failed: %s", string(body))
+		}
+
+		var page []InvestmentTransaction
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Returns every lot imported from broker lot CSVs.
+func (c *Client) ListBrokerTaxLots(ctx context.Context) ([]BrokerTaxLot, error) {
+	url := c.restURL("broker_tax_lots") + "?order=account_id.asc,symbol.asc,acquired_date.asc,id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list broker_tax_lots failed: %s", string(body))
+	}
+	var lots []BrokerTaxLot
+	if err := json.NewDecoder(resp.Body).Decode(&lots); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// Replaces an account's imported lots with the given ones.
+func (c *Client) ReplaceBrokerTaxLots(ctx context.Context, accountID string, lots []BrokerTaxLot) error {
+	deleteURL := c.restURL("broker_tax_lots") + "?account_id=eq." + url.QueryEscape(accountID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return fmt.Errorf("supabase delete broker_tax_lots failed: %s", string(body))
+	}
+	resp.Body.Close()
+	if len(lots) == 0 {
+		return nil
+	}
+
+	resp, err = c.doRequest(ctx, http.MethodPost, c.restURL("broker_tax_lots"), lots)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase insert broker_tax_lots failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns the lot selection method configured for each account.
+func (c *Client) ListTaxLotSettings(ctx context.Context) ([]TaxLotSetting, error) {
+	url := c.restURL("tax_lot_settings") + "?order=account_id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list tax_lot_settings failed: %s", string(body))
+	}
+	var settings []TaxLotSetting
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Upserts an account's lot selection method by account_id.
+func (c *Client) UpsertTaxLotSetting(ctx context.Context, setting *TaxLotSetting) error {
+	url := c.restURL("tax_lot_settings") + "?on_conflict=account_id"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, []TaxLotSetting{*setting})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert tax_lot_settings failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns every specific-identification lot selection.
+func (c *Client) ListTaxLotSelections(ctx context.Context) ([]TaxLotSelection, error) {
+	url := c.restURL("tax_lot_selections") + "?order=sale_transaction_id.asc,id.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list tax_lot_selections failed: %s", string(body))
+	}
+	var selections []TaxLotSelection
+	if err := json.NewDecoder(resp.Body).Decode(&selections); err != nil {
+		return nil, err
+	}
+	return selections, nil
+}
+
+// Points every lot selection on oldLotID at newLotID.
+func (c *Client) RenameTaxLotSelections(ctx context.Context, oldLotID, newLotID string) error {
+	patchURL := c.restURL("tax_lot_selections") + "?lot_id=eq." + url.QueryEscape(oldLotID)
+	resp, err := c.doRequest(ctx, http.MethodPatch, patchURL, map[string]string{"lot_id": newLotID})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase update tax_lot_selections failed: %s", string(body))
+	}
+	return nil
+}
+
+// Replaces the lot selections for one sale.
+func (c *Client) ReplaceTaxLotSelections(ctx context.Context, saleTransactionID string, selections []TaxLotSelection) error {
+	deleteURL := c.restURL("tax_lot_selections") + "?sale_transaction_id=eq." + url.QueryEscape(saleTransactionID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return fmt.Errorf("supabase delete tax_lot_selections failed: %s", string(body))
+	}
+	resp.Body.Close()
+	if len(selections) == 0 {
+		return nil
+	}
+
+	resp, err = c.doRequest(ctx, http.MethodPost, c.restURL("tax_lot_selections"), selections)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase insert tax_lot_selections failed: %s", string(body))
+	}
+	return nil
+}
+
+// Upserts securities by symbol.
+func (c *Client) UpsertSecurities(ctx context.Context, securities []Security) error {
+	if len(securities) == 0 {
+		return nil
+	}
+	url := c.restURL("securities") + "?on_conflict=symbol"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, securities)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert securities failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns every security.
+func (c *Client) ListSecurities(ctx context.Context) ([]Security, error) {
+	url := c.restURL("securities") + "?order=symbol.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list securities failed: %s", string(body))
+	}
+	var securities []Security
+	if err := json.NewDecoder(resp.Body).Decode(&securities); err != nil {
+		return nil, err
+	}
+	return securities, nil
+}
+
+// Returns every asset class override.
+func (c *Client) ListAssetClassOverrides(ctx context.Context) ([]AssetClassOverride, error) {
+	url := c.restURL("asset_class_overrides") + "?order=symbol.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list asset_class_overrides failed: %s", string(body))
+	}
+	var overrides []AssetClassOverride
+	if err := json.NewDecoder(resp.Body).Decode(&overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// Upserts a symbol's asset class override.
+func (c *Client) UpsertAssetClassOverride(ctx context.Context, override *AssetClassOverride) error {
+	url := c.restURL("asset_class_overrides") + "?on_conflict=symbol"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, []AssetClassOverride{*override})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert asset_class_overrides failed: %s", string(body))
+	}
+	return nil
+}
+
+// Deletes a symbol's asset class override.
+func (c *Client) DeleteAssetClassOverride(ctx context.Context, symbol string) error {
+	deleteURL := c.restURL("asset_class_overrides") + "?symbol=eq." + url.QueryEscape(symbol)
+	resp, err := c.doRequest(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete asset_class_overrides failed: %s", string(body))
+	}
+	return nil
+}
+
+// Returns the target allocation.
+func (c *Client) ListAllocationTargets(ctx context.Context) ([]AllocationTarget, error) {
+	url := c.restURL("allocation_targets") + "?order=asset_class.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list allocation_targets failed: %s", string(body))
+	}
+	var targets []AllocationTarget
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// Replaces the target allocation.
+func (c *Client) ReplaceAllocationTargets(ctx context.Context, targets []AllocationTarget) error {
+	resp, err := c.doRequest(ctx, http.MethodDelete, c.restURL("allocation_targets")+"?asset_class=not.is.null", nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return fmt.Errorf("supabase delete allocation_targets failed: %s", string(body))
+	}
+	resp.Body.Close()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	resp, err = c.doRequest(ctx, http.MethodPost, c.restURL("allocation_targets"), targets)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase insert allocation_targets failed: %s", string(body))
+	}
+	return nil
+}
+
+// Upserts daily closes by symbol and date.
+func (c *Client) UpsertPrices(ctx context.Context, prices []Price) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	url := c.restURL("prices") + "?on_conflict=symbol,date"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, prices)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert prices failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists a symbol's daily closes between two dates (inclusive), oldest first, paging until all are read.
+func (c *Client) ListPrices(ctx context.Context, symbol string, start, end time.Time) ([]Price, error) {
+	const pageSize = 1000
+	baseURL := c.restURL("prices") + "?symbol=eq." + url.QueryEscape(symbol) +
+		"&date=gte." + start.Format("2006-01-02") + "&date=lte." + end.Format("2006-01-02") +
+		"&order=date.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []Price
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list prices failed: %s", string(body))
+		}
+
+		var page []Price
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Returns the date of a symbol's latest stored close, or nil if it has none.
+func (c *Client) GetLatestPriceDate(ctx context.Context, symbol string) (*time.Time, error) {
+	reqURL := c.restURL("prices") + "?select=date&symbol=eq." + url.QueryEscape(symbol) + "&order=date.desc&limit=1"
+	resp, err := c.doRequest(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase get latest price date failed: %s", string(body))
+	}
+	var rows []Price
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0].Date.Time, nil
+}
+
+// Returns every benchmark.
+func (c *Client) ListBenchmarks(ctx context.Context) ([]Benchmark, error) {
+	url := c.restURL("benchmarks") + "?order=symbol.asc"
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase list benchmarks failed: %s", string(body))
+	}
+	var benchmarks []Benchmark
+	if err := json.NewDecoder(resp.Body).Decode(&benchmarks); err != nil {
+		return nil, err
+	}
+	return benchmarks, nil
+}
+
+// Upserts a benchmark by symbol.
+func (c *Client) UpsertBenchmark(ctx context.Context, benchmark *Benchmark) error {
+	url := c.restURL("benchmarks") + "?on_conflict=symbol"
+	resp, err := c.doRequest(ctx, http.MethodPost, url, []Benchmark{*benchmark})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert benchmarks failed: %s", string(body))
+	}
+	return nil
+}
+
+// Deletes a benchmark. Its stored prices are kept.
+func (c *Client) DeleteBenchmark(ctx context.Context, symbol string) error {
+	deleteURL := c.restURL("benchmarks") + "?symbol=eq." + url.QueryEscape(symbol)
+	resp, err := c.doRequest(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete benchmarks failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists daily closes for several symbols between two dates (inclusive), by symbol then date, paging until all are read.
+func (c *Client) ListPricesForSymbols(ctx context.Context, symbols []string, start, end time.Time) ([]Price, error) {
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	const pageSize = 1000
+	baseURL := c.restURL("prices") + "?symbol=" + url.QueryEscape(inFilter(symbols)) +
+		"&date=gte." + start.Format("2006-01-02") + "&date=lte." + end.Format("2006-01-02") +
+		"&order=symbol.asc,date.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []Price
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list prices for symbols failed: %s", string(body))
+		}
+
+		var page []Price
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Returns a background job's state by name, or nil if the job has never recorded one.
+func (c *Client) GetJobState(ctx context.Context, name string) (*JobState, error) {
+	getURL := c.restURL("job_state") + "?name=eq." + url.QueryEscape(name)
+	resp, err := c.doRequest(ctx, http.MethodGet, getURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("supabase get job_state failed: %s", string(body))
+	}
+
+	var states []JobState
+	if err := json.NewDecoder(resp.Body).Decode(&states); err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return &states[0], nil
+}
+
+// Upserts a background job's state by name.
+func (c *Client) UpsertJobState(ctx context.Context, state *JobState) error {
+	upsertURL := c.restURL("job_state") + "?on_conflict=name"
+	resp, err := c.doRequest(ctx, http.MethodPost, upsertURL, []JobState{*state})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert job_state failed: %s", string(body))
+	}
+	return nil
+}
+
+// Upserts weekly or monthly holdings rollups, one row per tier, period, account and symbol.
+func (c *Client) UpsertHoldingRollups(ctx context.Context, rollups []HoldingRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	upsertURL := c.restURL("holdings_rollups") + "?on_conflict=tier,period_start,account_id,symbol"
+	resp, err := c.doRequest(ctx, http.MethodPost, upsertURL, rollups)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase upsert holdings_rollups failed: %s", string(body))
+	}
+	return nil
+}
+
+// Deletes an account's rollup rows for one period that were taken from a day before asOf (symbols no longer
+// held on the period's latest day).
+func (c *Client) DeleteStaleHoldingRollups(ctx context.Context, tier string, periodStart time.Time, accountID string, asOf time.Time) error {
+	deleteURL := c.restURL("holdings_rollups") + "?tier=eq." + url.QueryEscape(tier) +
+		"&period_start=eq." + periodStart.Format("2006-01-02") + "&account_id=eq." + url.QueryEscape(accountID) +
+		"&as_of=lt." + asOf.Format("2006-01-02")
+	resp, err := c.doRequest(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete stale holdings_rollups failed: %s", string(body))
+	}
+	return nil
+}
+
+// Deletes a tier's rollups for periods starting before the cutoff date.
+func (c *Client) DeleteHoldingRollupsBefore(ctx context.Context, tier string, cutoffDate time.Time) error {
+	deleteURL := c.restURL("holdings_rollups") + "?tier=eq." + url.QueryEscape(tier) +
+		"&period_start=lt." + cutoffDate.Format("2006-01-02")
+	resp, err := c.doRequest(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("supabase delete holdings_rollups before failed: %s", string(body))
+	}
+	return nil
+}
+
+// Lists a tier's rollups taken between two dates (inclusive), oldest first, optionally for one account or one
+// symbol, paging until all are read.
+func (c *Client) ListHoldingRollups(ctx context.Context, tier string, start, end time.Time, accountID, symbol string) ([]HoldingRollup, error) {
+	const pageSize = 1000
+	baseURL := c.restURL("holdings_rollups") + "?tier=eq." + url.QueryEscape(tier) +
+		"&as_of=gte." + start.Format("2006-01-02") + "&as_of=lte." + end.Format("2006-01-02")
+	if accountID != "" {
+		baseURL += "&account_id=eq." + url.QueryEscape(accountID)
+	}
+	if symbol != "" {
+		baseURL += "&symbol=eq." + url.QueryEscape(symbol)
+	}
+	baseURL += "&order=as_of.asc,id.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []HoldingRollup
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list holdings_rollups failed: %s", string(body))
+		}
+
+		var page []HoldingRollup
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Lists every daily holding dated before the cutoff date, oldest first, paging until all are read.
+func (c *Client) ListDailyHoldingsBefore(ctx context.Context, cutoffDate time.Time) ([]DailyHolding, error) {
+	const pageSize = 1000
+	baseURL := c.restURL("daily_holdings") + "?date=lt." + cutoffDate.Format("2006-01-02") +
+		"&order=date.asc,id.asc&limit=" + strconv.Itoa(pageSize)
+
+	var list []DailyHolding
+	for offset := 0; ; offset += pageSize {
+		resp, err := c.doRequest(ctx, http.MethodGet, baseURL+"&offset="+strconv.Itoa(offset), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("supabase list daily_holdings before failed: %s", string(body))
+		}
+
+		var page []DailyHolding
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, page...)
+		if len(page) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// Sets a transaction's category by hand and marks it overridden so syncs keep it. Reports whether the
+// transaction exists.
+func (c *Client) UpdateTransactionCategory(ctx context.Context, id int64, categoryID int64) (bool, error) {
+	payload := map[string]interface{}{"category_id": categoryID, "category_overridden": true}
+	patchURL := c.restURL("transactions") + "?id=eq." + strconv.FormatInt(id, 10)
+	resp, err := c.doRequest(ctx, http.MethodPatch, patchURL, payload)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("supabase update transaction category failed: %s", string(body))
+	}
+
+	// Decodes the updated rows (Prefer: return=representation); none means no such transaction.
+	var updated []Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return false, err
+	}
+	return len(updated) > 0, nil
+}
+
+// Represents a row in the plaid_items table.
+type PlaidItem struct {
+	ID                     int64      `json:"id,omitempty"`
+	ItemID                 string     `json:"item_id"`
+	AccessToken            string     `json:"access_token"`
+	InstitutionID          *string    `json:"institution_id,omitempty"`
+	InstitutionName        *string    `json:"institution_name,omitempty"`
+	Status                 string     `json:"status"`
+	LastUpdated            time.Time  `json:"last_updated"`
+	CreatedAt              *time.Time `json:"created_at,omitempty"`
+	TransactionsCursor     *string    `json:"transactions_cursor,omitempty"`
+	NewTransactionsPending bool       `json:"new_transactions_pending"`
+	HoldingsRefreshPending bool       `json:"holdings_refresh_pending"`
+	NewAccountsAvailable   bool       `json:"new_accounts_available"`
+}
+
+// The JSON-safe representation for API responses (hides access_token).
+type PlaidItemJSON struct {
+	ItemID               string    `json:"itemId"`
+	InstitutionName      string    `json:"institutionName,omitempty"`
+	Status               string    `json:"status"`
+	LastUpdated          time.Time `json:"lastUpdated"`
+	NewAccountsAvailable bool      `json:"newAccountsAvailable"`
+}
+
+// Represents a row in the plaid_accounts table. BalancesAsOf is always sent, so writes without a real-time refresh
+// clear the stored refresh time.
+type PlaidAccount struct {
+	ID               int64      `json:"id,omitempty"`
+	PlaidItemID      string     `json:"plaid_item_id"`
+	AccountID        string     `json:"account_id"`
+	Name             string     `json:"name"`
+	Mask             *string    `json:"mask,omitempty"`
+	Type             string     `json:"type"`
+	Subtype          *string    `json:"subtype,omitempty"`
+	CurrentBalance   float64    `json:"current_balance"`
+	AvailableBalance *float64   `json:"available_balance"`
+	BalanceLimit     *float64   `json:"balance_limit"`
+	BalancesAsOf     *time.Time `json:"balances_as_of"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+}
+
+/*
+// Represents a row in the snaptrade_user table.
+type SnaptradeUser struct {
+	ID         int64  `json:"id,omitempty"`
+	UserID     string `json:"user_id"`
+	UserSecret string `json:"user_secret"`
+}
+
+// Represents a row in the snaptrade_connections table.
+type SnaptradeConnection struct {
+	ID         int64      `json:"id,omitempty"`
+	ConnID     string     `json:"conn_id"`
+	Brokerage  string     `json:"brokerage"`
+	Status     string     `json:"status"`
+	LastSynced *time.Time `json:"last_synced,omitempty"`
+}
+
+// The JSON-safe representation for API responses (hides conn_id).
+type SnaptradeConnectionJSON struct {
+	ID         string     `json:"id"`
+	Brokerage  string     `json:"brokerage"`
+	Status     string     `json:"status"`
+	LastSynced *time.Time `json:"lastSynced,omitempty"`
+}
+*/
+
+// Represents a row in the categories table.
+type Category struct {
+	ID        int64   `json:"id,omitempty"`
+	Name      string  `json:"name"`
+	PlaidName *string `json:"plaid_name,omitempty"`
+	Expense   bool    `json:"expense"`
+}
+
+// Category rule maps transaction name/merchant substring to a category.
+type CategoryRule struct {
+	ID          int64  `json:"id,omitempty"`
+	MatchString string `json:"match_string"`
+	CategoryID  int64  `json:"category_id"`
+}
+
+// Represents a row in the transactions table.
+type Transaction struct {
+	ID                 int64     `json:"id,omitempty"`
+	PlaidAccountID     string    `json:"plaid_account_id"`
+	PlaidTransactionID string    `json:"plaid_transaction_id"`
+	Date               DateOnly  `json:"date"`
+	AmountCents        int64     `json:"amount_cents"`
+	Name               string    `json:"name"`
+	MerchantName       *string   `json:"merchant_name"`
+	CategoryID         *int64    `json:"category_id"`
+	CategoryOverridden bool      `json:"category_overridden"`
+	Pending            bool      `json:"pending"`
+	CreatedAt          time.Time `json:"created_at,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at,omitempty"`
+}
+
+// Holds optional filters for listing transactions.
+type ListTransactionsFilter struct {
+	Month      string
+	CategoryID *int64
 	Search     string
 }
 
-// Represents a row in the budgets table.
-type Budget struct {
-	ID          int64            `json:"id,omitempty"`
-	Allocations map[string]int64 `json:"allocations"`
-	UpdatedAt   time.Time        `json:"updated_at,omitempty"`
+// Represents a row in the budget_versions table (allocations keyed by category ID, in effect from effective_month onward).
+type BudgetVersion struct {
+	ID             int64           `json:"id,omitempty"`
+	EffectiveMonth DateOnly        `json:"effective_month"`
+	Allocations    map[int64]int64 `json:"allocations"`
+	TotalCents     int64           `json:"total_cents"`
+	CreatedAt      *time.Time      `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time      `json:"updated_at,omitempty"`
 }
 
 // Represents a row in the daily_snapshots table
@@ -1497,6 +2851,17 @@ type MonthlyNetWorth struct {
 
 // Represents a row in the monthly_expense_summary table.
 type MonthlyExpenseSummary struct {
+	ID               int64      `json:"id,omitempty"`
+	Month            DateOnly   `json:"month"`
+	CategoryID       int64      `json:"category_id"`
+	TotalCents       int64      `json:"total_cents"`
+	TransactionCount int        `json:"transaction_count"`
+	DailyCents       []int64    `json:"daily_cents"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+}
+
+// Represents a row in the monthly_transfer_summary table.
+type MonthlyTransferSummary struct {
 	ID               int64      `json:"id,omitempty"`
 	Month            DateOnly   `json:"month"`
 	CategoryID       int64      `json:"category_id"`
@@ -1523,3 +2888,223 @@ type YearlyPortfolioSummary struct {
 	PortfolioValueCents int64      `json:"portfolio_value_cents"`
 	CreatedAt           *time.Time `json:"created_at,omitempty"`
 }
+
+// Represents a row in the webhook_events table.
+type WebhookEvent struct {
+	ID             int64             `json:"id,omitempty"`
+	Source         string            `json:"source"`
+	DedupeKey      string            `json:"dedupe_key"`
+	WebhookType    string            `json:"webhook_type"`
+	WebhookCode    string            `json:"webhook_code"`
+	ItemID         *string           `json:"item_id"`
+	RawBody        string            `json:"raw_body"`
+	Headers        map[string]string `json:"headers"`
+	ReceivedAt     time.Time         `json:"received_at"`
+	ProcessedAt    *time.Time        `json:"processed_at"`
+	Outcome        string            `json:"outcome"`
+	Error          *string           `json:"error"`
+	Attempts       int               `json:"attempts"`
+	DuplicateCount int               `json:"duplicate_count"`
+}
+
+// Holds optional filters for listing webhook events.
+type ListWebhookEventsFilter struct {
+	Outcome string
+	ItemID  string
+	Limit   int
+}
+
+// Represents a row in the investment_transactions table.
+// Amounts follow the app convention: inflow to the account is positive, outflow is negative.
+type InvestmentTransaction struct {
+	ID                           int64      `json:"id,omitempty"`
+	PlaidInvestmentTransactionID string     `json:"plaid_investment_transaction_id"`
+	AccountID                    string     `json:"account_id"`
+	SecurityID                   *string    `json:"security_id"`
+	Symbol                       *string    `json:"symbol"`
+	SecurityName                 *string    `json:"security_name"`
+	Date                         DateOnly   `json:"date"`
+	Name                         string     `json:"name"`
+	Type                         string     `json:"type"`
+	Subtype                      string     `json:"subtype"`
+	Quantity                     float64    `json:"quantity"`
+	PriceCents                   int64      `json:"price_cents"`
+	FeesCents                    int64      `json:"fees_cents"`
+	AmountCents                  int64      `json:"amount_cents"`
+	CreatedAt                    *time.Time `json:"created_at,omitempty"`
+}
+
+// Holds optional filters for listing investment transactions.
+type ListInvestmentTransactionsFilter struct {
+	AccountID  string
+	AccountIDs []string
+	Symbol     string
+	Type       string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+// Represents a row in the liabilities table (credit card, student loan or mortgage details for one account).
+type Liability struct {
+	ID                        int64          `json:"id,omitempty"`
+	AccountID                 string         `json:"account_id"`
+	LiabilityType             string         `json:"liability_type"`
+	APRs                      []LiabilityAPR `json:"aprs"`
+	InterestRatePercentage    *float64       `json:"interest_rate_percentage"`
+	InterestRateType          *string        `json:"interest_rate_type"`
+	LastStatementBalanceCents *int64         `json:"last_statement_balance_cents"`
+	LastStatementIssueDate    *DateOnly      `json:"last_statement_issue_date"`
+	MinimumPaymentCents       *int64         `json:"minimum_payment_cents"`
+	NextPaymentDueDate        *DateOnly      `json:"next_payment_due_date"`
+	LastPaymentAmountCents    *int64         `json:"last_payment_amount_cents"`
+	LastPaymentDate           *DateOnly      `json:"last_payment_date"`
+	IsOverdue                 *bool          `json:"is_overdue"`
+	CreditLimitCents          *int64         `json:"credit_limit_cents"`
+	OriginationPrincipalCents *int64         `json:"origination_principal_cents"`
+	OutstandingInterestCents  *int64         `json:"outstanding_interest_cents"`
+	PayoffDate                *DateOnly      `json:"payoff_date"`
+	LoanName                  *string        `json:"loan_name"`
+	UpdatedAt                 time.Time      `json:"updated_at"`
+}
+
+// Represents one APR on a credit card, stored in liabilities.aprs.
+type LiabilityAPR struct {
+	APRPercentage            float64 `json:"apr_percentage"`
+	APRType                  string  `json:"apr_type"`
+	BalanceSubjectToAPRCents *int64  `json:"balance_subject_to_apr_cents"`
+	InterestChargeCents      *int64  `json:"interest_charge_cents"`
+}
+
+// Represents a row in the budget_category_settings table.
+type BudgetCategorySetting struct {
+	CategoryID        int64      `json:"category_id"`
+	Rollover          bool       `json:"rollover"`
+	MaxCarryoverCents *int64     `json:"max_carryover_cents"`
+	ResetMonth        *int       `json:"reset_month"`
+	StartMonth        *DateOnly  `json:"start_month"`
+	AlertThresholds   []int      `json:"alert_thresholds"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the budget_alerts_sent table.
+type BudgetAlertSent struct {
+	ID               int64      `json:"id,omitempty"`
+	Month            DateOnly   `json:"month"`
+	CategoryID       int64      `json:"category_id"`
+	ThresholdPercent int        `json:"threshold_percent"`
+	SpentCents       int64      `json:"spent_cents"`
+	AllocationCents  int64      `json:"allocation_cents"`
+	SentAt           *time.Time `json:"sent_at,omitempty"`
+}
+
+// Represents a row in the sinking_funds table.
+type SinkingFund struct {
+	ID          int64      `json:"id,omitempty"`
+	Name        string     `json:"name"`
+	TargetCents int64      `json:"target_cents"`
+	DueDate     DateOnly   `json:"due_date"`
+	StartMonth  DateOnly   `json:"start_month"`
+	CategoryIDs []int64    `json:"category_ids"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the sinking_fund_contributions table.
+type SinkingFundContribution struct {
+	ID          int64      `json:"id,omitempty"`
+	FundID      int64      `json:"fund_id"`
+	Date        DateOnly   `json:"date"`
+	AmountCents int64      `json:"amount_cents"`
+	Note        *string    `json:"note"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// Represents a row in the broker_tax_lots table (an open lot imported from a broker CSV).
+type BrokerTaxLot struct {
+	ID             int64      `json:"id,omitempty"`
+	AccountID      string     `json:"account_id"`
+	Symbol         string     `json:"symbol"`
+	AcquiredDate   DateOnly   `json:"acquired_date"`
+	Quantity       float64    `json:"quantity"`
+	CostBasisCents int64      `json:"cost_basis_cents"`
+	LotKey         string     `json:"lot_key,omitempty"`
+	ImportedAt     *time.Time `json:"imported_at,omitempty"`
+}
+
+// Represents a row in the tax_lot_settings table (fifo, lifo, hifo or specific).
+type TaxLotSetting struct {
+	AccountID string     `json:"account_id"`
+	Method    string     `json:"method"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the tax_lot_selections table (shares of a lot chosen for a sale).
+type TaxLotSelection struct {
+	ID                int64   `json:"id,omitempty"`
+	SaleTransactionID string  `json:"sale_transaction_id"`
+	LotID             string  `json:"lot_id"`
+	Quantity          float64 `json:"quantity"`
+}
+
+// Represents a row in the securities table.
+type Security struct {
+	Symbol     string     `json:"symbol"`
+	SecurityID *string    `json:"security_id"`
+	Name       *string    `json:"name"`
+	PlaidType  *string    `json:"plaid_type"`
+	CUSIP      *string    `json:"cusip"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the asset_class_overrides table.
+type AssetClassOverride struct {
+	Symbol     string     `json:"symbol"`
+	AssetClass string     `json:"asset_class"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the allocation_targets table.
+type AllocationTarget struct {
+	AssetClass    string     `json:"asset_class"`
+	TargetPercent float64    `json:"target_percent"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the prices table.
+type Price struct {
+	ID         int64      `json:"id,omitempty"`
+	Symbol     string     `json:"symbol"`
+	Date       DateOnly   `json:"date"`
+	CloseCents int64      `json:"close_cents"`
+	Source     string     `json:"source"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+// Represents a row in the benchmarks table.
+type Benchmark struct {
+	Symbol    string     `json:"symbol"`
+	Name      *string    `json:"name"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Represents a row in the job_state table.
+type JobState struct {
+	Name              string     `json:"name"`
+	LastProcessedDate *DateOnly  `json:"last_processed_date"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+// Represents a row in the holdings_rollups table.
+type HoldingRollup struct {
+	ID             int64      `json:"id,omitempty"`
+	Tier           string     `json:"tier"`
+	PeriodStart    DateOnly   `json:"period_start"`
+	AsOf           DateOnly   `json:"as_of"`
+	AccountID      string     `json:"account_id"`
+	Symbol         string     `json:"symbol"`
+	Quantity       float64    `json:"quantity"`
+	ValueCents     int64      `json:"value_cents"`
+	CostBasisCents *int64     `json:"cost_basis_cents"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+}
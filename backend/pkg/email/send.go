@@ -30,3 +30,20 @@ func SendCSV(ctx context.Context, to, subject, filename string, csvContent []byt
 	_, err := client.Emails.SendWithContext(ctx, params)
 	return err
 }
+
+// Sends a plain-text email to the given address.
+func SendText(ctx context.Context, to, subject, text string) error {
+	secretKey := os.Getenv("RESEND_API_KEY")
+	fromEmail := os.Getenv("RESEND_FROM")
+	client := resend.NewClient(secretKey)
+	// Creates a new email request.
+	params := &resend.SendEmailRequest{
+		From:    fromEmail,
+		To:      []string{to},
+		Subject: subject,
+		Text:    text,
+	}
+	// Sends the email.
+	_, err := client.Emails.SendWithContext(ctx, params)
+	return err
+}
@@ -0,0 +1,227 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date layouts accepted in price CSVs.
+var closeDateLayouts = []string{"2006-01-02", "01/02/2006", "1/2/2006"}
+
+// Constructs a price provider from environment variables. PRICE_PROVIDER_URL (with optional
+// PRICE_PROVIDER_API_KEY) selects the HTTP provider; otherwise PRICE_CSV_DIR points at a directory of
+// <SYMBOL>.csv files of daily closes.
+func NewProviderFromEnv() (PriceProvider, error) {
+	if urlTemplate := os.Getenv("PRICE_PROVIDER_URL"); urlTemplate != "" {
+		return NewHTTPProvider(urlTemplate, os.Getenv("PRICE_PROVIDER_API_KEY"))
+	}
+	dir := os.Getenv("PRICE_CSV_DIR")
+	if dir == "" {
+		return nil, errors.New("PRICE_PROVIDER_URL or PRICE_CSV_DIR must be set")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("PRICE_CSV_DIR %s is not a directory", dir)
+	}
+	return NewCSVDirProvider(dir), nil
+}
+
+// Constructs a provider that downloads a CSV of daily closes per symbol. The URL template's {symbol},
+// {from} and {to} placeholders are replaced with the symbol and the YYYY-MM-DD dates, e.g.
+// "https://stooq.com/q/d/l/?s={symbol}.us&i=d". A non-empty API key is sent as a Bearer token.
+func NewHTTPProvider(urlTemplate, apiKey string) (*HTTPProvider, error) {
+	if !strings.Contains(urlTemplate, "{symbol}") {
+		return nil, errors.New("PRICE_PROVIDER_URL must contain {symbol}")
+	}
+	return &HTTPProvider{
+		httpClient:  &http.Client{Timeout: 15 * time.Second},
+		urlTemplate: urlTemplate,
+		apiKey:      apiKey,
+	}, nil
+}
+
+// Downloads the symbol's closes and returns those between the dates (inclusive).
+func (p *HTTPProvider) DailyCloses(ctx context.Context, symbol string, from, to time.Time) ([]DailyClose, error) {
+	requestURL := strings.NewReplacer(
+		"{symbol}", url.QueryEscape(symbol),
+		"{from}", from.Format("2006-01-02"),
+		"{to}", to.Format("2006-01-02"),
+	).Replace(p.urlTemplate)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("price provider returned %d for %s: %s", resp.StatusCode, symbol, string(body))
+	}
+
+	closes, err := ParseClosesCSV(resp.Body, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("price provider response for %s: %w", symbol, err)
+	}
+	return closesBetween(closes, from, to), nil
+}
+
+// Constructs a provider that reads daily closes from <dir>/<SYMBOL>.csv.
+func NewCSVDirProvider(dir string) *CSVDirProvider {
+	return &CSVDirProvider{dir: dir}
+}
+
+// Reads the symbol's CSV and returns its closes between the dates (inclusive).
+func (p *CSVDirProvider) DailyCloses(ctx context.Context, symbol string, from, to time.Time) ([]DailyClose, error) {
+	file, err := os.Open(filepath.Join(p.dir, strings.ToUpper(filepath.Base(symbol))+".csv"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	closes, err := ParseClosesCSV(file, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return closesBetween(closes, from, to), nil
+}
+
+// Parses a CSV of daily closes with a header row, such as a Yahoo Finance or Stooq export. The date column
+// is "Date"; the price is "Adj Close" when present (so dividends are included), else "Close" or "Price".
+// Rows without a price (holidays, "null") are skipped. Closes are returned oldest first.
+func ParseClosesCSV(r io.Reader, symbol string) ([]DailyClose, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	dateColumn, priceColumn, adjustedColumn := -1, -1, -1
+	for i, cell := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))) {
+		case "date":
+			dateColumn = i
+		case "adj close", "adj. close", "adjusted close", "adj_close":
+			adjustedColumn = i
+		case "close", "price", "last", "close/last":
+			priceColumn = i
+		}
+	}
+	if adjustedColumn >= 0 {
+		priceColumn = adjustedColumn
+	}
+	if dateColumn < 0 || priceColumn < 0 {
+		return nil, errors.New("missing Date and Close columns")
+	}
+
+	symbol = strings.ToUpper(symbol)
+	byDate := make(map[time.Time]DailyClose)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if dateColumn >= len(row) || priceColumn >= len(row) {
+			continue
+		}
+		dateValue := strings.TrimSpace(row[dateColumn])
+		priceValue := strings.NewReplacer("$", "", ",", "").Replace(strings.TrimSpace(row[priceColumn]))
+		if dateValue == "" || priceValue == "" || strings.EqualFold(priceValue, "null") {
+			continue
+		}
+
+		date, err := parseCloseDate(dateValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		price, err := strconv.ParseFloat(priceValue, 64)
+		if err != nil || price <= 0 || math.IsInf(price, 0) {
+			return nil, fmt.Errorf("line %d: invalid close %q", line, row[priceColumn])
+		}
+		byDate[date] = DailyClose{Symbol: symbol, Date: date, CloseCents: int64(math.Round(price * 100))}
+	}
+
+	closes := make([]DailyClose, 0, len(byDate))
+	for _, dailyClose := range byDate {
+		closes = append(closes, dailyClose)
+	}
+	sort.Slice(closes, func(i, j int) bool {
+		return closes[i].Date.Before(closes[j].Date)
+	})
+	return closes, nil
+}
+
+// Returns the closes between the dates (inclusive).
+func closesBetween(closes []DailyClose, from, to time.Time) []DailyClose {
+	inRange := closes[:0]
+	for _, dailyClose := range closes {
+		if !dailyClose.Date.Before(from) && !dailyClose.Date.After(to) {
+			inRange = append(inRange, dailyClose)
+		}
+	}
+	return inRange
+}
+
+// Parses a close date as midnight UTC.
+func parseCloseDate(s string) (time.Time, error) {
+	if len(s) > 10 && s[4] == '-' {
+		// Drops a time of day, e.g. "2026-01-02 00:00:00-05:00".
+		s = s[:10]
+	}
+	for _, layout := range closeDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// Fetches daily closing prices.
+type PriceProvider interface {
+	// Returns the symbol's daily closes between the dates (inclusive), oldest first.
+	DailyCloses(ctx context.Context, symbol string, from, to time.Time) ([]DailyClose, error)
+}
+
+// Reads daily closes from a directory of CSV files.
+type CSVDirProvider struct {
+	dir string
+}
+
+// Downloads daily closes over HTTP.
+type HTTPProvider struct {
+	httpClient  *http.Client
+	urlTemplate string
+	apiKey      string
+}
+
+// One symbol's closing price on one day. Date is midnight UTC.
+type DailyClose struct {
+	Symbol     string
+	Date       time.Time
+	CloseCents int64
+}
@@ -0,0 +1,78 @@
+package marketdata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseClosesCSV(t *testing.T) {
+	csvData := `Date,Open,High,Low,Close,Adj Close,Volume
+2026-01-05,121.00,122.00,120.00,121.50,121.00,1000
+2026-01-02,110.00,111.00,109.00,110.50,110.00,1000
+2026-01-03,null,null,null,null,null,null
+2026-01-01,100.00,101.00,99.00,100.50,100.00,1000
+`
+
+	closes, err := ParseClosesCSV(strings.NewReader(csvData), "vti")
+	if err != nil {
+		t.Fatalf("ParseClosesCSV() error = %v", err)
+	}
+	want := []DailyClose{
+		{Symbol: "VTI", Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), CloseCents: 10000},
+		{Symbol: "VTI", Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), CloseCents: 11000},
+		{Symbol: "VTI", Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), CloseCents: 12100},
+	}
+	if len(closes) != len(want) {
+		t.Fatalf("closes = %+v, want %+v", closes, want)
+	}
+	for i := range want {
+		if closes[i] != want[i] {
+			t.Errorf("close %d = %+v, want %+v", i, closes[i], want[i])
+		}
+	}
+
+	// Without an adjusted column the close is used, and US dates are accepted.
+	closes, err = ParseClosesCSV(strings.NewReader("Date,Close/Last\n01/02/2026,$48.25\n"), "AGG")
+	if err != nil || len(closes) != 1 || closes[0].CloseCents != 4825 || closes[0].Date.Day() != 2 {
+		t.Errorf("US format = %+v, %v", closes, err)
+	}
+
+	if _, err := ParseClosesCSV(strings.NewReader("Day,Value\n2026-01-01,1\n"), "X"); err == nil {
+		t.Error("expected an error without Date and Close columns")
+	}
+	if _, err := ParseClosesCSV(strings.NewReader("Date,Close\nyesterday,1\n"), "X"); err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func TestHTTPProviderDailyCloses(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.RequestURI(), r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("Date,Close\n2025-12-31,99.00\n2026-01-02,110.00\n2026-01-05,121.00\n"))
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(server.URL+"/q?s={symbol}&from={from}&to={to}", "secret")
+	if err != nil {
+		t.Fatalf("NewHTTPProvider() error = %v", err)
+	}
+	closes, err := provider.DailyCloses(context.Background(), "VTI", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("DailyCloses() error = %v", err)
+	}
+	if gotPath != "/q?s=VTI&from=2026-01-01&to=2026-01-05" || gotAuth != "Bearer secret" {
+		t.Errorf("request = %s (auth %q)", gotPath, gotAuth)
+	}
+	if len(closes) != 2 || closes[0].CloseCents != 11000 || closes[1].CloseCents != 12100 {
+		t.Errorf("closes = %+v, want the two in range", closes)
+	}
+
+	if _, err := NewHTTPProvider("https://example.com/prices", ""); err == nil {
+		t.Error("expected an error for a URL without {symbol}")
+	}
+}
@@ -62,6 +62,64 @@ func (c *Client) GetHoldings(ctx context.Context, accessToken string) ([]PlaidHo
 	return resp.Holdings, resp.Securities, nil
 }
 
+// Fetches investment transactions and their securities between two dates (YYYY-MM-DD), paging with offset until all are read.
+func (c *Client) GetInvestmentTransactions(ctx context.Context, accessToken, startDate, endDate string) ([]PlaidInvestmentTransaction, []PlaidSecurity, error) {
+	const pageSize = 500
+	var transactions []PlaidInvestmentTransaction
+	securitiesByID := make(map[string]PlaidSecurity)
+
+	for {
+		reqBody := investmentsTransactionsGetRequest{
+			ClientID:    c.clientID,
+			Secret:      c.secret,
+			AccessToken: accessToken,
+			StartDate:   startDate,
+			EndDate:     endDate,
+			Options: &investmentsTransactionsGetOptions{
+				Count:  pageSize,
+				Offset: len(transactions),
+			},
+		}
+
+		var resp investmentsTransactionsGetResponse
+		err := c.postJSON(ctx, "/investments/transactions/get", reqBody, &resp)
+		if err != nil {
+			return nil, nil, err
+		}
+		transactions = append(transactions, resp.InvestmentTransactions...)
+		for _, security := range resp.Securities {
+			securitiesByID[security.SecurityID] = security
+		}
+
+		// Stops when every transaction is read (or a page comes back empty).
+		if len(resp.InvestmentTransactions) == 0 || len(transactions) >= resp.TotalInvestmentTransactions {
+			break
+		}
+	}
+
+	securities := make([]PlaidSecurity, 0, len(securitiesByID))
+	for _, security := range securitiesByID {
+		securities = append(securities, security)
+	}
+	return transactions, securities, nil
+}
+
+// Fetches credit card, student loan and mortgage details plus the accounts they belong to.
+func (c *Client) GetLiabilities(ctx context.Context, accessToken string) (*Liabilities, []Account, error) {
+	reqBody := liabilitiesGetRequest{
+		ClientID:    c.clientID,
+		Secret:      c.secret,
+		AccessToken: accessToken,
+	}
+
+	var resp liabilitiesGetResponse
+	err := c.postJSON(ctx, "/liabilities/get", reqBody, &resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp.Liabilities, resp.Accounts, nil
+}
+
 // Creates a Plaid Link token for the given user.
 func (c *Client) CreateLinkToken(ctx context.Context, userID, webhookURL string, products []string) (string, error) {
 	return c.CreateLinkTokenWithAccessToken(ctx, userID, "", webhookURL, products)
@@ -134,6 +192,20 @@ func (c *Client) GetAccounts(ctx context.Context, accessToken string) ([]Account
 	return resp.Accounts, nil
 }
 
+// Returns accounts with real-time balances (/accounts/balance/get) for a given access token.
+func (c *Client) GetBalances(ctx context.Context, accessToken string) ([]Account, error) {
+	reqBody := accountsGetRequest{
+		ClientID:    c.clientID,
+		Secret:      c.secret,
+		AccessToken: accessToken,
+	}
+	var resp accountsGetResponse
+	if err := c.postJSON(ctx, "/accounts/balance/get", reqBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Accounts, nil
+}
+
 // Gets item status for a given access token.
 func (c *Client) GetItem(ctx context.Context, accessToken string) (*ItemStatus, error) {
 	reqBody := itemGetRequest{
@@ -348,7 +420,9 @@ type Account struct {
 
 // Represents the balances of a Plaid account.
 type accountBalances struct {
-	Current float64 `json:"current"`
+	Current   float64  `json:"current"`
+	Available *float64 `json:"available"`
+	Limit     *float64 `json:"limit"`
 }
 
 // Represents an error response from the Plaid API.
@@ -475,5 +549,125 @@ type PlaidSecurity struct {
 	Ticker     *string `json:"ticker_symbol"`
 	Name       *string `json:"name"`
 	Type       string  `json:"type"`
+	CUSIP      *string `json:"cusip"`
 	ClosePrice float64 `json:"close_price,omitempty"`
+	// Date of the close price (YYYY-MM-DD), when Plaid provides one.
+	ClosePriceAsOf *string `json:"close_price_as_of,omitempty"`
+}
+
+// Request body for fetching investment transactions.
+type investmentsTransactionsGetRequest struct {
+	ClientID    string                             `json:"client_id"`
+	Secret      string                             `json:"secret"`
+	AccessToken string                             `json:"access_token"`
+	StartDate   string                             `json:"start_date"`
+	EndDate     string                             `json:"end_date"`
+	Options     *investmentsTransactionsGetOptions `json:"options,omitempty"`
+}
+
+// Pagination options for fetching investment transactions.
+type investmentsTransactionsGetOptions struct {
+	Count  int `json:"count"`
+	Offset int `json:"offset"`
+}
+
+// Response body for fetching investment transactions.
+type investmentsTransactionsGetResponse struct {
+	InvestmentTransactions      []PlaidInvestmentTransaction `json:"investment_transactions"`
+	Securities                  []PlaidSecurity              `json:"securities"`
+	TotalInvestmentTransactions int                          `json:"total_investment_transactions"`
+}
+
+// Represents an investment transaction (buy, sell, dividend, fee, transfer, ...).
+// Amount is positive when cash leaves the account and negative when cash enters it.
+type PlaidInvestmentTransaction struct {
+	InvestmentTransactionID string   `json:"investment_transaction_id"`
+	AccountID               string   `json:"account_id"`
+	SecurityID              *string  `json:"security_id"`
+	Date                    string   `json:"date"`
+	Name                    string   `json:"name"`
+	Quantity                float64  `json:"quantity"`
+	Amount                  float64  `json:"amount"`
+	Price                   float64  `json:"price"`
+	Fees                    *float64 `json:"fees"`
+	Type                    string   `json:"type"`
+	Subtype                 string   `json:"subtype"`
+}
+
+// Request body for fetching liabilities.
+type liabilitiesGetRequest struct {
+	ClientID    string `json:"client_id"`
+	Secret      string `json:"secret"`
+	AccessToken string `json:"access_token"`
+}
+
+// Response body for fetching liabilities.
+type liabilitiesGetResponse struct {
+	Accounts    []Account   `json:"accounts"`
+	Liabilities Liabilities `json:"liabilities"`
+}
+
+// Liabilities grouped by kind.
+type Liabilities struct {
+	Credit   []CreditLiability      `json:"credit"`
+	Student  []StudentLoanLiability `json:"student"`
+	Mortgage []MortgageLiability    `json:"mortgage"`
+}
+
+// Represents a credit card liability.
+type CreditLiability struct {
+	AccountID              *string     `json:"account_id"`
+	APRs                   []CreditAPR `json:"aprs"`
+	IsOverdue              *bool       `json:"is_overdue"`
+	LastPaymentAmount      *float64    `json:"last_payment_amount"`
+	LastPaymentDate        *string     `json:"last_payment_date"`
+	LastStatementBalance   *float64    `json:"last_statement_balance"`
+	LastStatementIssueDate *string     `json:"last_statement_issue_date"`
+	MinimumPaymentAmount   *float64    `json:"minimum_payment_amount"`
+	NextPaymentDueDate     *string     `json:"next_payment_due_date"`
+}
+
+// Represents one APR on a credit card (purchase, balance transfer, cash advance, ...).
+type CreditAPR struct {
+	APRPercentage        float64  `json:"apr_percentage"`
+	APRType              string   `json:"apr_type"`
+	BalanceSubjectToAPR  *float64 `json:"balance_subject_to_apr"`
+	InterestChargeAmount *float64 `json:"interest_charge_amount"`
+}
+
+// Represents a student loan liability.
+type StudentLoanLiability struct {
+	AccountID                  *string  `json:"account_id"`
+	ExpectedPayoffDate         *string  `json:"expected_payoff_date"`
+	InterestRatePercentage     float64  `json:"interest_rate_percentage"`
+	IsOverdue                  *bool    `json:"is_overdue"`
+	LastPaymentAmount          *float64 `json:"last_payment_amount"`
+	LastPaymentDate            *string  `json:"last_payment_date"`
+	LastStatementBalance       *float64 `json:"last_statement_balance"`
+	LastStatementIssueDate     *string  `json:"last_statement_issue_date"`
+	LoanName                   *string  `json:"loan_name"`
+	MinimumPaymentAmount       *float64 `json:"minimum_payment_amount"`
+	NextPaymentDueDate         *string  `json:"next_payment_due_date"`
+	OriginationPrincipalAmount *float64 `json:"origination_principal_amount"`
+	OutstandingInterestAmount  *float64 `json:"outstanding_interest_amount"`
+}
+
+// Represents a mortgage liability.
+type MortgageLiability struct {
+	AccountID                  string               `json:"account_id"`
+	InterestRate               MortgageInterestRate `json:"interest_rate"`
+	LastPaymentAmount          *float64             `json:"last_payment_amount"`
+	LastPaymentDate            *string              `json:"last_payment_date"`
+	LoanTypeDescription        *string              `json:"loan_type_description"`
+	MaturityDate               *string              `json:"maturity_date"`
+	NextMonthlyPayment         *float64             `json:"next_monthly_payment"`
+	NextPaymentDueDate         *string              `json:"next_payment_due_date"`
+	OriginationPrincipalAmount *float64             `json:"origination_principal_amount"`
+	PastDueAmount              *float64             `json:"past_due_amount"`
+}
+
+// Represents a mortgage interest rate.
+type MortgageInterestRate struct {
+	Percentage *float64 `json:"percentage"`
+	Type       *string  `json:"type"`
 }
@@ -1,27 +1,35 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"math"
 	"net/http"
 	"time"
 
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
 	// "github.com/matthewtzong/portfolio-tracker/backend/pkg/snaptrade"
 )
 
 // Account View Model
 type AccountJSON struct {
-	Provider     string  `json:"provider"`
-	PlaidItemID  *string `json:"plaidItemId,omitempty"`
-	AccountID    string  `json:"accountId"`
-	Name         string  `json:"name"`
-	Mask         *string `json:"mask,omitempty"`
-	Type         string  `json:"type"`
-	Subtype      *string `json:"subtype,omitempty"`
-	BalanceCents int64   `json:"balanceCents"`
-	IsLiability  bool    `json:"isLiability"`
+	Provider              string     `json:"provider"`
+	PlaidItemID           *string    `json:"plaidItemId,omitempty"`
+	AccountID             string     `json:"accountId"`
+	Name                  string     `json:"name"`
+	Mask                  *string    `json:"mask,omitempty"`
+	Type                  string     `json:"type"`
+	Subtype               *string    `json:"subtype,omitempty"`
+	BalanceCents          int64      `json:"balanceCents"`
+	IsLiability           bool       `json:"isLiability"`
+	AvailableBalanceCents *int64     `json:"availableBalanceCents,omitempty"`
+	LimitCents            *int64     `json:"limitCents,omitempty"`
+	BalancesAsOf          *time.Time `json:"balancesAsOf,omitempty"`
 }
 
 // List of Accounts and Net Worth breakdown.
@@ -31,6 +39,27 @@ type AccountsResponse struct {
 	CashCents        int64         `json:"cashCents"`
 	InvestmentsCents int64         `json:"investmentsCents"`
 	LiabilitiesCents int64         `json:"liabilitiesCents"`
+	// Per-item results, only set by POST /api/accounts/refresh.
+	Refresh []balanceRefreshResult `json:"refresh,omitempty"`
+}
+
+// Minimum time between real-time balance refreshes for one item (Plaid bills /accounts/balance/get per call).
+const balanceRefreshInterval = 5 * time.Minute
+
+// Per-item result of a real-time balance refresh.
+type balanceRefreshResult struct {
+	ItemID          string     `json:"itemId"`
+	InstitutionName string     `json:"institutionName,omitempty"`
+	Refreshed       bool       `json:"refreshed"`
+	RateLimited     bool       `json:"rateLimited,omitempty"`
+	NextRefreshAt   *time.Time `json:"nextRefreshAt,omitempty"`
+	ErrorCode       string     `json:"errorCode,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// Real-time balance refresh request (itemId is optional; empty refreshes every item).
+type refreshBalancesRequest struct {
+	ItemID string `json:"itemId"`
 }
 
 // Registers the accounts route.
@@ -44,6 +73,15 @@ func registerAccountsRoutes(mux *http.ServeMux, deps apiDependencies) {
 		handleGetAccounts(w, r, deps)
 	})))
 
+	// POST /api/accounts/refresh fetches real-time balances from Plaid for all items (or one item) and returns the accounts.
+	mux.Handle("/api/accounts/refresh", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleRefreshAccountBalances(w, r, deps)
+	})))
+
 	// GET /api/net-worth/snapshots returns monthly net worth snapshots over time.
 	mux.Handle("/api/net-worth/snapshots", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -69,13 +107,118 @@ func handleGetAccounts(w http.ResponseWriter, r *http.Request, deps apiDependenc
 		return
 	}
 
-	// Load Plaid accounts from the database.
-	plaidAccounts, err := deps.db.ListPlaidAccounts(r.Context())
+	resp, err := buildAccountsResponse(r.Context(), deps.db)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list Plaid accounts: "+err.Error())
+		return
+	}
+
+	// Return the accounts and net worth breakdown.
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Fetches real-time balances from Plaid and returns the refreshed accounts.
+// Items refreshed within the last balanceRefreshInterval are skipped and reported as rate limited.
+func handleRefreshAccountBalances(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil || deps.plaidClient == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database or Plaid not configured")
+		return
+	}
+
+	// Parses the optional request body.
+	var req refreshBalancesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	items, err := deps.db.ListPlaidItems(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list Plaid items: "+err.Error())
+		return
+	}
+	if req.ItemID != "" {
+		var selected []database.PlaidItem
+		for _, item := range items {
+			if item.ItemID == req.ItemID {
+				selected = append(selected, item)
+			}
+		}
+		if len(selected) == 0 {
+			writeJSONError(w, http.StatusNotFound, "Plaid item not found")
+			return
+		}
+		items = selected
+	}
+
+	storedAccounts, err := deps.db.ListPlaidAccounts(r.Context())
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "failed to list Plaid accounts: "+err.Error())
 		return
 	}
 
+	// Refreshes each item, keeping going after failures.
+	now := GetLocalNow()
+	results := make([]balanceRefreshResult, 0, len(items))
+	for _, item := range items {
+		if item.AccessToken == "manual" {
+			continue
+		}
+		result := balanceRefreshResult{ItemID: item.ItemID}
+		if item.InstitutionName != nil {
+			result.InstitutionName = *item.InstitutionName
+		}
+
+		if nextRefreshAt, allowed := nextBalanceRefresh(storedAccounts, item.ItemID, now); !allowed {
+			result.RateLimited = true
+			result.NextRefreshAt = &nextRefreshAt
+			results = append(results, result)
+			continue
+		}
+
+		accounts, err := deps.plaidClient.GetBalances(r.Context(), item.AccessToken)
+		if err != nil {
+			log.Printf("refresh balances for item %s: %v", item.ItemID, err)
+			result.ErrorCode = plaidSyncErrorCode(err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		dbAccounts := make([]database.PlaidAccount, len(accounts))
+		for i, account := range accounts {
+			dbAccounts[i] = plaidAccountToDB(item.ItemID, account)
+			dbAccounts[i].BalancesAsOf = &now
+		}
+		err = deps.db.UpsertPlaidAccounts(r.Context(), dbAccounts)
+		if err != nil {
+			result.ErrorCode = "DATABASE_ERROR"
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Refreshed = true
+		results = append(results, result)
+	}
+
+	resp, err := buildAccountsResponse(r.Context(), deps.db)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list Plaid accounts: "+err.Error())
+		return
+	}
+	resp.Refresh = results
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Loads the stored accounts and computes the net worth breakdown.
+func buildAccountsResponse(ctx context.Context, db *database.Client) (*AccountsResponse, error) {
+	// Load Plaid accounts from the database.
+	plaidAccounts, err := db.ListPlaidAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Converts the Plaid accounts to the AccountJSON view model.
 	// Plaid accounts contribute to cash (HYSA, checking, CDs), liabilities (credit cards), and investments (stocks, ETFs, etc).
 	accounts := make([]AccountJSON, 0)
@@ -95,16 +238,45 @@ func handleGetAccounts(w http.ResponseWriter, r *http.Request, deps apiDependenc
 	// Net worth = assets (cash + investments) - liabilities.
 	netWorthCents := cashCents + investmentsCents - liabilitiesCents
 
-	resp := AccountsResponse{
+	return &AccountsResponse{
 		Accounts:         accounts,
 		NetWorthCents:    netWorthCents,
 		CashCents:        cashCents,
 		InvestmentsCents: investmentsCents,
 		LiabilitiesCents: liabilitiesCents,
+	}, nil
+}
+
+// Returns when an item's balances may next be refreshed, and whether that is now.
+func nextBalanceRefresh(accounts []database.PlaidAccount, itemID string, now time.Time) (time.Time, bool) {
+	var lastRefresh time.Time
+	for _, account := range accounts {
+		if account.PlaidItemID == itemID && account.BalancesAsOf != nil && account.BalancesAsOf.After(lastRefresh) {
+			lastRefresh = *account.BalancesAsOf
+		}
 	}
+	next := lastRefresh.Add(balanceRefreshInterval)
+	return next, !now.Before(next)
+}
 
-	// Return the accounts and net worth breakdown.
-	_ = json.NewEncoder(w).Encode(resp)
+// Converts a Plaid account into the plaid_accounts row for an item.
+func plaidAccountToDB(itemID string, a plaid.Account) database.PlaidAccount {
+	account := database.PlaidAccount{
+		PlaidItemID:      itemID,
+		AccountID:        a.AccountID,
+		Name:             a.Name,
+		Type:             a.Type,
+		CurrentBalance:   a.Balances.Current,
+		AvailableBalance: a.Balances.Available,
+		BalanceLimit:     a.Balances.Limit,
+	}
+	if a.Mask != "" {
+		account.Mask = &a.Mask
+	}
+	if a.Subtype != "" {
+		account.Subtype = &a.Subtype
+	}
+	return account
 }
 
 // Returns monthly net worth snapshots over time.
@@ -194,6 +366,15 @@ func loadPlaidAccounts(a database.PlaidAccount) (AccountJSON, int64, int64, int6
 		Subtype:      subtype,
 		BalanceCents: balanceCents,
 		IsLiability:  isLiability,
+		BalancesAsOf: a.BalancesAsOf,
+	}
+	if a.AvailableBalance != nil {
+		availableCents := int64(math.Round(*a.AvailableBalance * 100))
+		account.AvailableBalanceCents = &availableCents
+	}
+	if a.BalanceLimit != nil {
+		limitCents := int64(math.Round(*a.BalanceLimit * 100))
+		account.LimitCents = &limitCents
 	}
 
 	return account, cashDelta, investDelta, liabilityDelta
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
 	// "github.com/matthewtzong/portfolio-tracker/backend/pkg/snaptrade"
 )
 
@@ -211,3 +215,60 @@ func TestNetWorthFormulaWithPlaidAndSnaptrade(t *testing.T) {
 	}
 }
 */
+
+func TestNextBalanceRefresh(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-2 * time.Minute)
+	old := now.Add(-time.Hour)
+	accounts := []database.PlaidAccount{
+		{PlaidItemID: "item-recent", AccountID: "a", BalancesAsOf: &old},
+		{PlaidItemID: "item-recent", AccountID: "b", BalancesAsOf: &recent},
+		{PlaidItemID: "item-old", AccountID: "c", BalancesAsOf: &old},
+		{PlaidItemID: "item-never", AccountID: "d"},
+	}
+
+	next, allowed := nextBalanceRefresh(accounts, "item-recent", now)
+	if allowed {
+		t.Errorf("item-recent refreshed 2 minutes ago should be rate limited")
+	}
+	if want := recent.Add(balanceRefreshInterval); !next.Equal(want) {
+		t.Errorf("item-recent next refresh = %v, want %v", next, want)
+	}
+	if _, allowed := nextBalanceRefresh(accounts, "item-old", now); !allowed {
+		t.Errorf("item-old refreshed an hour ago should be allowed")
+	}
+	if _, allowed := nextBalanceRefresh(accounts, "item-never", now); !allowed {
+		t.Errorf("item-never never refreshed should be allowed")
+	}
+}
+
+func TestLoadPlaidAccountsAvailableAndLimit(t *testing.T) {
+	available := 950.25
+	limit := 5000.0
+	account, _, _, _ := loadPlaidAccounts(database.PlaidAccount{
+		PlaidItemID:      "item",
+		AccountID:        "card",
+		Type:             "credit",
+		CurrentBalance:   49.75,
+		AvailableBalance: &available,
+		BalanceLimit:     &limit,
+	})
+
+	if account.AvailableBalanceCents == nil || *account.AvailableBalanceCents != 95025 {
+		t.Errorf("AvailableBalanceCents = %v, want 95025", account.AvailableBalanceCents)
+	}
+	if account.LimitCents == nil || *account.LimitCents != 500000 {
+		t.Errorf("LimitCents = %v, want 500000", account.LimitCents)
+	}
+}
+
+// Tests that accounts written from the cached /accounts/get clear an earlier real-time refresh time.
+func TestPlaidAccountToDBClearsBalancesAsOf(t *testing.T) {
+	body, err := json.Marshal(plaidAccountToDB("item", plaid.Account{AccountID: "a", Type: "depository"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"balances_as_of":null`) {
+		t.Errorf("plaid_accounts row = %s, want balances_as_of sent as null", body)
+	}
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+)
+
+// Days of investment activity re-read each night (Plaid can revise recent activity).
+const investmentTransactionsLookbackDays = 30
+
+// Days requested the first time an item's investment activity is synced (Plaid keeps up to 24 months).
+const investmentTransactionsBackfillDays = 730
+
+// Default number of activity rows returned.
+const defaultActivityLimit = 500
+
+// Returns investment activity (buys, sells, dividends, fees, ...), newest first.
+func handleGetPortfolioActivity(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	// Parses the query parameters.
+	query := r.URL.Query()
+	filter := database.ListInvestmentTransactionsFilter{
+		AccountID: query.Get("accountId"),
+		Symbol:    query.Get("symbol"),
+		Type:      query.Get("type"),
+		Limit:     defaultActivityLimit,
+	}
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err := time.ParseInLocation(dateLayout, fromStr, GetLocalLocation())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "from must be YYYY-MM-DD")
+			return
+		}
+		filter.From = &from
+	}
+	if toStr := query.Get("to"); toStr != "" {
+		to, err := time.ParseInLocation(dateLayout, toStr, GetLocalLocation())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "to must be YYYY-MM-DD")
+			return
+		}
+		filter.To = &to
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 5000 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and 5000")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	transactions, err := deps.db.ListInvestmentTransactions(r.Context(), filter)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list investment transactions: "+err.Error())
+		return
+	}
+
+	// Fetch Plaid accounts to get account names.
+	plaidAccounts, _ := deps.db.ListPlaidAccounts(r.Context())
+	accountMap := make(map[string]string)
+	for _, account := range plaidAccounts {
+		accountMap[account.AccountID] = account.Name
+	}
+
+	activity := make([]investmentActivityJSON, len(transactions))
+	for i, transaction := range transactions {
+		activity[i] = investmentActivityJSON{
+			ID:           transaction.ID,
+			Date:         transaction.Date.Format(dateLayout),
+			AccountID:    transaction.AccountID,
+			AccountName:  accountMap[transaction.AccountID],
+			Symbol:       transaction.Symbol,
+			SecurityName: transaction.SecurityName,
+			Name:         transaction.Name,
+			Type:         transaction.Type,
+			Subtype:      transaction.Subtype,
+			Quantity:     transaction.Quantity,
+			PriceCents:   transaction.PriceCents,
+			FeesCents:    transaction.FeesCents,
+			AmountCents:  transaction.AmountCents,
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(portfolioActivityResponse{Activity: activity})
+	if err != nil {
+		log.Printf("portfolio activity encode: %v", err)
+	}
+}
+
+// Syncs investment transactions for every Plaid item with investment accounts. Returns the number of rows written.
+// Per-item failures are logged and skipped.
+func syncInvestmentTransactions(ctx context.Context, deps apiDependencies) (int, error) {
+	if deps.db == nil || deps.plaidClient == nil {
+		return 0, nil
+	}
+
+	items, err := deps.db.ListPlaidItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+	accounts, err := deps.db.ListPlaidAccounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Groups investment account IDs by item.
+	investmentAccountsByItem := make(map[string][]string)
+	for _, account := range accounts {
+		if account.Type == "investment" {
+			investmentAccountsByItem[account.PlaidItemID] = append(investmentAccountsByItem[account.PlaidItemID], account.AccountID)
+		}
+	}
+
+	now := GetLocalNow()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, GetLocalLocation())
+
+	written := 0
+	for _, item := range items {
+		accountIDs := investmentAccountsByItem[item.ItemID]
+		if item.AccessToken == "manual" || len(accountIDs) == 0 {
+			continue
+		}
+		count, err := syncItemInvestmentTransactions(ctx, deps, item, accountIDs, today)
+		if err != nil {
+			log.Printf("cron: investment transactions for item %s: %v", item.ItemID, err)
+			continue
+		}
+		written += count
+	}
+	return written, nil
+}
+
+// Fetches and upserts investment transactions for one item, backfilling if none are stored yet.
+func syncItemInvestmentTransactions(ctx context.Context, deps apiDependencies, item database.PlaidItem, accountIDs []string, today time.Time) (int, error) {
+	// Re-reads recent activity, or backfills the full history on the first sync.
+	latest, err := deps.db.ListInvestmentTransactions(ctx, database.ListInvestmentTransactionsFilter{AccountIDs: accountIDs, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	start := today.AddDate(0, 0, -investmentTransactionsLookbackDays)
+	if len(latest) == 0 {
+		start = today.AddDate(0, 0, -investmentTransactionsBackfillDays)
+	}
+
+	plaidTransactions, securities, err := deps.plaidClient.GetInvestmentTransactions(ctx, item.AccessToken, start.Format(dateLayout), today.Format(dateLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get investment transactions: %w", err)
+	}
+
+	securitiesByID := make(map[string]plaid.PlaidSecurity, len(securities))
+	for _, security := range securities {
+		securitiesByID[security.SecurityID] = security
+	}
+	err = deps.db.UpsertSecurities(ctx, plaidSecuritiesToDB(securities, today))
+	if err != nil {
+		log.Printf("cron: failed to upsert securities for item %s: %v", item.ItemID, err)
+	}
+
+	transactions := make([]database.InvestmentTransaction, len(plaidTransactions))
+	for i, transaction := range plaidTransactions {
+		transactions[i] = plaidInvestmentTransactionToDB(transaction, securitiesByID)
+	}
+	err = deps.db.UpsertInvestmentTransactions(ctx, transactions)
+	if err != nil {
+		return 0, err
+	}
+	return len(transactions), nil
+}
+
+// Converts a Plaid investment transaction into our DB model.
+func plaidInvestmentTransactionToDB(p plaid.PlaidInvestmentTransaction, securitiesByID map[string]plaid.PlaidSecurity) database.InvestmentTransaction {
+	date, _ := time.Parse(dateLayout, p.Date)
+	// We negate the amount because Plaid returns positive for cash leaving the account.
+	transaction := database.InvestmentTransaction{
+		PlaidInvestmentTransactionID: p.InvestmentTransactionID,
+		AccountID:                    p.AccountID,
+		SecurityID:                   p.SecurityID,
+		Date:                         database.DateOnly{Time: date},
+		Name:                         p.Name,
+		Type:                         p.Type,
+		Subtype:                      p.Subtype,
+		Quantity:                     p.Quantity,
+		PriceCents:                   int64(math.Round(p.Price * 100)),
+		AmountCents:                  int64(math.Round(-p.Amount * 100)),
+	}
+	if p.Fees != nil {
+		transaction.FeesCents = int64(math.Round(*p.Fees * 100))
+	}
+
+	// Resolves the symbol and name (cash activity has no security).
+	if p.SecurityID != nil {
+		if security, ok := securitiesByID[*p.SecurityID]; ok {
+			symbol := securitySymbol(security)
+			transaction.Symbol = &symbol
+			transaction.SecurityName = security.Name
+		}
+	}
+	return transaction
+}
+
+// Returns the symbol we store for a Plaid security: ticker, then name, then UNKNOWN.
+func securitySymbol(security plaid.PlaidSecurity) string {
+	if security.Ticker != nil {
+		return *security.Ticker
+	}
+	if security.Name != nil {
+		return *security.Name
+	}
+	return "UNKNOWN"
+}
+
+// Investment activity row for API.
+type investmentActivityJSON struct {
+	ID           int64   `json:"id"`
+	Date         string  `json:"date"`
+	AccountID    string  `json:"accountId"`
+	AccountName  string  `json:"accountName,omitempty"`
+	Symbol       *string `json:"symbol,omitempty"`
+	SecurityName *string `json:"securityName,omitempty"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Subtype      string  `json:"subtype"`
+	Quantity     float64 `json:"quantity"`
+	PriceCents   int64   `json:"priceCents"`
+	FeesCents    int64   `json:"feesCents"`
+	AmountCents  int64   `json:"amountCents"`
+}
+
+// Portfolio activity response.
+type portfolioActivityResponse struct {
+	Activity []investmentActivityJSON `json:"activity"`
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+)
+
+func TestPlaidInvestmentTransactionToDB_BuyNegatesAmount(t *testing.T) {
+	securityID := "sec-1"
+	ticker := "VTI"
+	name := "Vanguard Total Stock Market ETF"
+	fees := 1.25
+	securities := map[string]plaid.PlaidSecurity{
+		securityID: {SecurityID: securityID, Ticker: &ticker, Name: &name},
+	}
+
+	got := plaidInvestmentTransactionToDB(plaid.PlaidInvestmentTransaction{
+		InvestmentTransactionID: "inv-1",
+		AccountID:               "acc-1",
+		SecurityID:              &securityID,
+		Date:                    "2026-03-02",
+		Name:                    "BUY VTI",
+		Quantity:                2,
+		Amount:                  501.25,
+		Price:                   250.005,
+		Fees:                    &fees,
+		Type:                    "buy",
+		Subtype:                 "buy",
+	}, securities)
+
+	if got.AmountCents != -50125 {
+		t.Errorf("AmountCents = %d, want -50125 (buy is an outflow)", got.AmountCents)
+	}
+	if got.PriceCents != 25001 {
+		t.Errorf("PriceCents = %d, want 25001", got.PriceCents)
+	}
+	if got.FeesCents != 125 {
+		t.Errorf("FeesCents = %d, want 125", got.FeesCents)
+	}
+	if got.Symbol == nil || *got.Symbol != "VTI" {
+		t.Errorf("Symbol = %v, want VTI", got.Symbol)
+	}
+	if got.Date.Format(dateLayout) != "2026-03-02" {
+		t.Errorf("Date = %s, want 2026-03-02", got.Date.Format(dateLayout))
+	}
+}
+
+func TestPlaidInvestmentTransactionToDB_CashDividendHasNoSymbol(t *testing.T) {
+	got := plaidInvestmentTransactionToDB(plaid.PlaidInvestmentTransaction{
+		InvestmentTransactionID: "inv-2",
+		AccountID:               "acc-1",
+		Date:                    "2026-03-15",
+		Name:                    "INTEREST",
+		Amount:                  -3.17,
+		Type:                    "cash",
+		Subtype:                 "interest",
+	}, nil)
+
+	if got.AmountCents != 317 {
+		t.Errorf("AmountCents = %d, want 317 (income is an inflow)", got.AmountCents)
+	}
+	if got.Symbol != nil {
+		t.Errorf("Symbol = %v, want nil", *got.Symbol)
+	}
+	if got.FeesCents != 0 {
+		t.Errorf("FeesCents = %d, want 0", got.FeesCents)
+	}
+}
@@ -0,0 +1,675 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+)
+
+// Asset classes a security can be assigned to. Securities we cannot classify are "other".
+const (
+	assetClassUSEquity   = "us_equity"
+	assetClassIntlEquity = "intl_equity"
+	assetClassBonds      = "bonds"
+	assetClassCash       = "cash"
+	assetClassCrypto     = "crypto"
+	assetClassRealEstate = "real_estate"
+	assetClassOther      = "other"
+)
+
+// Default rebalancing band, in percentage points of drift from target.
+const defaultRebalanceTolerance = 5.0
+
+// Trades smaller than this are not suggested.
+const minRebalanceTradeCents = 100
+
+// Asset classes in display order.
+var assetClasses = []string{assetClassUSEquity, assetClassIntlEquity, assetClassBonds, assetClassCash, assetClassCrypto, assetClassRealEstate}
+
+// Name keywords that place a fund in an asset class, checked in order.
+var assetClassKeywords = []struct {
+	assetClass string
+	keywords   []string
+}{
+	{assetClassCash, []string{"money market", "cash reserves", "government cash"}},
+	{assetClassRealEstate, []string{"real estate", "reit"}},
+	{assetClassBonds, []string{"bond", "treasury", "fixed income", "municipal", "aggregate"}},
+	{assetClassIntlEquity, []string{"international", "intl", "ex-us", "ex us", "emerging", "developed markets", "foreign", "global ex", "world ex", "europe", "pacific"}},
+}
+
+// Returns an allocation of the latest holdings by asset class against the targets, in total and per account
+// type, with the trades that bring the portfolio back to target when a class drifts outside the band.
+func handleGetPortfolioAllocation(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	options, err := parseRebalanceOptions(r.URL.Query().Get("tolerance"), r.URL.Query().Get("newCashCents"), r.URL.Query().Get("cashOnly"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	today := calendarDay(GetLocalNow())
+	daily, err := deps.db.ListDailyHoldings(r.Context(), dailyHoldingsWindowStart(today), today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list daily holdings: "+err.Error())
+		return
+	}
+	classify, err := loadAssetClassifier(r, deps)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	targetRows, err := deps.db.ListAllocationTargets(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list allocation targets: "+err.Error())
+		return
+	}
+	targets := make(map[string]float64, len(targetRows))
+	for _, target := range targetRows {
+		targets[target.AssetClass] = target.TargetPercent
+	}
+	plaidAccounts, _ := deps.db.ListPlaidAccounts(r.Context())
+	accountTypes := make(map[string]string, len(plaidAccounts))
+	for _, account := range plaidAccounts {
+		accountTypes[account.AccountID] = account.Type
+		if account.Subtype != nil && *account.Subtype != "" {
+			accountTypes[account.AccountID] = *account.Subtype
+		}
+	}
+
+	// Classifies each account's latest holdings.
+	var holdings []allocationHolding
+	for accountID, days := range latestHoldingDays(daily) {
+		accountType := accountTypes[accountID]
+		if accountType == "" {
+			accountType = "unknown"
+		}
+		for _, holding := range days.Current {
+			if holding.ValueCents == 0 {
+				continue
+			}
+			holdings = append(holdings, allocationHolding{
+				AccountID:   accountID,
+				AccountType: accountType,
+				Symbol:      holding.Symbol,
+				AssetClass:  classify(holding.Symbol),
+				ValueCents:  holding.ValueCents,
+			})
+		}
+	}
+	sort.Slice(holdings, func(i, j int) bool {
+		if holdings[i].ValueCents != holdings[j].ValueCents {
+			return holdings[i].ValueCents > holdings[j].ValueCents
+		}
+		return holdings[i].Symbol < holdings[j].Symbol
+	})
+
+	resp := portfolioAllocationResponse{
+		AsOf:                today.Format(dateLayout),
+		HasTargets:          len(targetRows) > 0,
+		Targets:             allocationTargetsToJSON(targetRows),
+		Total:               buildAllocation(holdings, targets, options.Tolerance),
+		AccountTypes:        []accountTypeAllocationJSON{},
+		UnclassifiedSymbols: []string{},
+		TolerancePercent:    options.Tolerance,
+		NewCashCents:        options.NewCashCents,
+		CashOnly:            options.CashOnly,
+		Trades:              rebalanceTrades(holdings, targets, options),
+	}
+	for _, class := range resp.Total.Classes {
+		if class.OutOfBand {
+			resp.RebalanceNeeded = true
+		}
+	}
+	byType := make(map[string][]allocationHolding)
+	unclassified := make(map[string]bool)
+	for _, holding := range holdings {
+		byType[holding.AccountType] = append(byType[holding.AccountType], holding)
+		if holding.AssetClass == assetClassOther && !unclassified[holding.Symbol] {
+			unclassified[holding.Symbol] = true
+			resp.UnclassifiedSymbols = append(resp.UnclassifiedSymbols, holding.Symbol)
+		}
+	}
+	sort.Strings(resp.UnclassifiedSymbols)
+	types := make([]string, 0, len(byType))
+	for accountType := range byType {
+		types = append(types, accountType)
+	}
+	sort.Strings(types)
+	for _, accountType := range types {
+		resp.AccountTypes = append(resp.AccountTypes, accountTypeAllocationJSON{
+			AccountType:    accountType,
+			allocationJSON: buildAllocation(byType[accountType], targets, options.Tolerance),
+		})
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("portfolio allocation encode: %v", err)
+	}
+}
+
+// Returns the target allocation.
+func handleGetAllocationTargets(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	targets, err := deps.db.ListAllocationTargets(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(allocationTargetsResponse{Targets: allocationTargetsToJSON(targets)})
+}
+
+// Replaces the target allocation. Targets must add up to 100%; an empty list clears them.
+func handleUpdateAllocationTargets(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	var req allocationTargetsResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if err := validateAllocationTargets(req.Targets); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := GetLocalNow()
+	rows := make([]database.AllocationTarget, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		if target.TargetPercent == 0 {
+			continue
+		}
+		rows = append(rows, database.AllocationTarget{AssetClass: target.AssetClass, TargetPercent: target.TargetPercent, UpdatedAt: &now})
+	}
+	err := deps.db.ReplaceAllocationTargets(r.Context(), rows)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(allocationTargetsResponse{Targets: allocationTargetsToJSON(rows)})
+}
+
+// Returns every known security with its Plaid type, override and the asset class used for allocation.
+func handleGetSecurities(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	securities, err := deps.db.ListSecurities(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	overrideRows, err := deps.db.ListAssetClassOverrides(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	overrides := make(map[string]string, len(overrideRows))
+	for _, override := range overrideRows {
+		overrides[override.Symbol] = override.AssetClass
+	}
+
+	output := make([]securityJSON, 0, len(securities))
+	for _, security := range securities {
+		output = append(output, securityJSON{
+			Symbol:     security.Symbol,
+			Name:       security.Name,
+			PlaidType:  security.PlaidType,
+			Override:   overrides[security.Symbol],
+			AssetClass: classifySecurity(security.Symbol, &security, overrides[security.Symbol]),
+		})
+		delete(overrides, security.Symbol)
+	}
+	// Overrides for symbols Plaid has not reported (e.g. manual Fidelity positions).
+	for symbol, assetClass := range overrides {
+		output = append(output, securityJSON{Symbol: symbol, Override: assetClass, AssetClass: assetClass})
+	}
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].Symbol < output[j].Symbol
+	})
+	_ = json.NewEncoder(w).Encode(securitiesResponse{Securities: output})
+}
+
+// Sets or clears (empty assetClass) a symbol's asset class override.
+func handleUpdateSecurityAssetClass(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	symbol := r.PathValue("symbol")
+	if symbol == "" {
+		writeJSONError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+
+	var req assetClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	var err error
+	if req.AssetClass == "" {
+		err = deps.db.DeleteAssetClassOverride(r.Context(), symbol)
+	} else {
+		if !isAssetClass(req.AssetClass) {
+			writeJSONError(w, http.StatusBadRequest, "assetClass must be one of "+strings.Join(assetClasses, ", "))
+			return
+		}
+		now := GetLocalNow()
+		err = deps.db.UpsertAssetClassOverride(r.Context(), &database.AssetClassOverride{Symbol: symbol, AssetClass: req.AssetClass, UpdatedAt: &now})
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(securityJSON{Symbol: symbol, Override: req.AssetClass})
+}
+
+// Loads the securities and overrides and returns a function that classifies a symbol.
+func loadAssetClassifier(r *http.Request, deps apiDependencies) (func(symbol string) string, error) {
+	securities, err := deps.db.ListSecurities(r.Context())
+	if err != nil {
+		return nil, errors.New("failed to list securities: " + err.Error())
+	}
+	overrideRows, err := deps.db.ListAssetClassOverrides(r.Context())
+	if err != nil {
+		return nil, errors.New("failed to list asset class overrides: " + err.Error())
+	}
+	bySymbol := make(map[string]*database.Security, len(securities))
+	for i := range securities {
+		bySymbol[securities[i].Symbol] = &securities[i]
+	}
+	overrides := make(map[string]string, len(overrideRows))
+	for _, override := range overrideRows {
+		overrides[override.Symbol] = override.AssetClass
+	}
+	return func(symbol string) string {
+		return classifySecurity(symbol, bySymbol[symbol], overrides[symbol])
+	}, nil
+}
+
+// Converts Plaid securities into our DB model, one row per stored symbol.
+func plaidSecuritiesToDB(securities []plaid.PlaidSecurity, now time.Time) []database.Security {
+	seen := make(map[string]bool, len(securities))
+	rows := make([]database.Security, 0, len(securities))
+	for _, security := range securities {
+		symbol := securitySymbol(security)
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		securityID := security.SecurityID
+		row := database.Security{Symbol: symbol, SecurityID: &securityID, Name: security.Name, CUSIP: security.CUSIP, UpdatedAt: &now}
+		if security.Type != "" {
+			securityType := security.Type
+			row.PlaidType = &securityType
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Returns a symbol's asset class: the override if set, then Plaid's type refined by the fund name.
+// Symbols Plaid has not described are only recognised as cash (money market funds such as SPAXX).
+func classifySecurity(symbol string, security *database.Security, override string) string {
+	if override != "" {
+		return override
+	}
+	if security == nil || security.PlaidType == nil {
+		if isMoneyMarketSymbol(symbol) {
+			return assetClassCash
+		}
+		return assetClassOther
+	}
+
+	securityType := strings.ToLower(*security.PlaidType)
+	switch securityType {
+	case "cash":
+		return assetClassCash
+	case "cryptocurrency":
+		return assetClassCrypto
+	}
+	if security.Name != nil {
+		name := strings.ToLower(*security.Name)
+		for _, group := range assetClassKeywords {
+			for _, keyword := range group.keywords {
+				if strings.Contains(name, keyword) {
+					return group.assetClass
+				}
+			}
+		}
+	}
+	switch securityType {
+	case "fixed income":
+		return assetClassBonds
+	case "equity", "etf", "mutual fund":
+		if isMoneyMarketSymbol(symbol) {
+			return assetClassCash
+		}
+		return assetClassUSEquity
+	}
+	return assetClassOther
+}
+
+// Reports whether a symbol looks like a money market fund (five letters ending in XX, e.g. SPAXX).
+func isMoneyMarketSymbol(symbol string) bool {
+	return len(symbol) == 5 && strings.HasSuffix(strings.ToUpper(symbol), "XX")
+}
+
+// Reports whether the asset class can be assigned or targeted.
+func isAssetClass(assetClass string) bool {
+	for _, candidate := range assetClasses {
+		if candidate == assetClass {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks that targets name each asset class at most once, lie between 0 and 100, and add up to 100.
+func validateAllocationTargets(targets []allocationTargetJSON) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(targets))
+	var total float64
+	for _, target := range targets {
+		if !isAssetClass(target.AssetClass) {
+			return errors.New("assetClass must be one of " + strings.Join(assetClasses, ", "))
+		}
+		if seen[target.AssetClass] {
+			return errors.New(target.AssetClass + " is listed more than once")
+		}
+		seen[target.AssetClass] = true
+		if target.TargetPercent < 0 || target.TargetPercent > 100 {
+			return errors.New("targetPercent must be between 0 and 100")
+		}
+		total += target.TargetPercent
+	}
+	if math.Abs(total-100) > 0.01 {
+		return errors.New("targets must add up to 100")
+	}
+	return nil
+}
+
+// Parses the rebalancing query parameters.
+func parseRebalanceOptions(tolerance, newCashCents, cashOnly string) (rebalanceOptions, error) {
+	options := rebalanceOptions{Tolerance: defaultRebalanceTolerance}
+	if tolerance != "" {
+		value, err := strconv.ParseFloat(tolerance, 64)
+		if err != nil || value < 0 || value > 100 {
+			return options, errors.New("tolerance must be a number of percentage points between 0 and 100")
+		}
+		options.Tolerance = value
+	}
+	if newCashCents != "" {
+		value, err := strconv.ParseInt(newCashCents, 10, 64)
+		if err != nil || value < 0 {
+			return options, errors.New("newCashCents must be a non-negative whole number of cents")
+		}
+		options.NewCashCents = value
+	}
+	if cashOnly != "" {
+		value, err := strconv.ParseBool(cashOnly)
+		if err != nil {
+			return options, errors.New("cashOnly must be true or false")
+		}
+		options.CashOnly = value
+	}
+	return options, nil
+}
+
+// Adds up holdings by asset class. Weights are shares of the classified value; "other" is reported apart.
+func buildAllocation(holdings []allocationHolding, targets map[string]float64, tolerance float64) allocationJSON {
+	values := make(map[string]int64)
+	var allocation allocationJSON
+	for _, holding := range holdings {
+		if holding.AssetClass == assetClassOther {
+			allocation.UnclassifiedValueCents += holding.ValueCents
+			continue
+		}
+		values[holding.AssetClass] += holding.ValueCents
+		allocation.ValueCents += holding.ValueCents
+	}
+
+	allocation.Classes = make([]allocationClassJSON, 0, len(assetClasses))
+	for _, assetClass := range assetClasses {
+		class := allocationClassJSON{AssetClass: assetClass, ValueCents: values[assetClass]}
+		if allocation.ValueCents > 0 {
+			class.CurrentPercent = roundPercent(float64(values[assetClass]) * 100 / float64(allocation.ValueCents))
+		}
+		if len(targets) > 0 {
+			target := targets[assetClass]
+			drift := roundPercent(class.CurrentPercent - target)
+			class.TargetPercent = &target
+			class.DriftPercent = &drift
+			class.OutOfBand = allocation.ValueCents > 0 && math.Abs(drift) > tolerance
+		}
+		if class.ValueCents == 0 && (class.TargetPercent == nil || *class.TargetPercent == 0) {
+			continue
+		}
+		allocation.Classes = append(allocation.Classes, class)
+	}
+	return allocation
+}
+
+// Suggests trades by asset class. When any class drifts outside the band, every class is traded back to
+// target (new cash included); otherwise new cash is spread over the underweight classes. With cashOnly
+// nothing is sold and only the new cash is invested. Each trade names the largest holding in its class.
+func rebalanceTrades(holdings []allocationHolding, targets map[string]float64, options rebalanceOptions) []rebalanceTradeJSON {
+	trades := []rebalanceTradeJSON{}
+	if len(targets) == 0 {
+		return trades
+	}
+	values := make(map[string]int64)
+	largest := make(map[string]allocationHolding)
+	var invested int64
+	for _, holding := range holdings {
+		if holding.AssetClass == assetClassOther {
+			continue
+		}
+		values[holding.AssetClass] += holding.ValueCents
+		invested += holding.ValueCents
+		if current, ok := largest[holding.AssetClass]; !ok || holding.ValueCents > current.ValueCents {
+			largest[holding.AssetClass] = holding
+		}
+	}
+	total := invested + options.NewCashCents
+	if total <= 0 {
+		return trades
+	}
+
+	// Gap between each class's target value and its current value.
+	gaps := make(map[string]int64, len(assetClasses))
+	outOfBand := false
+	for _, assetClass := range assetClasses {
+		gaps[assetClass] = int64(math.Round(targets[assetClass]*float64(total)/100)) - values[assetClass]
+		if invested > 0 && math.Abs(float64(values[assetClass])*100/float64(invested)-targets[assetClass]) > options.Tolerance {
+			outOfBand = true
+		}
+	}
+
+	var amounts map[string]int64
+	switch {
+	case outOfBand && !options.CashOnly:
+		amounts = gaps
+	case options.NewCashCents > 0:
+		// Spreads the new cash over the underweight classes in proportion to their shortfall.
+		var shortfall int64
+		var last string
+		for _, assetClass := range assetClasses {
+			if gaps[assetClass] > 0 {
+				shortfall += gaps[assetClass]
+				last = assetClass
+			}
+		}
+		if shortfall <= 0 {
+			return trades
+		}
+		amounts = make(map[string]int64, len(assetClasses))
+		var allocated int64
+		for _, assetClass := range assetClasses {
+			if gaps[assetClass] <= 0 {
+				continue
+			}
+			amount := int64(math.Round(float64(options.NewCashCents) * float64(gaps[assetClass]) / float64(shortfall)))
+			if assetClass == last {
+				amount = options.NewCashCents - allocated
+			}
+			amounts[assetClass] = amount
+			allocated += amount
+		}
+	default:
+		return trades
+	}
+
+	for _, assetClass := range assetClasses {
+		amount := amounts[assetClass]
+		if amount > -minRebalanceTradeCents && amount < minRebalanceTradeCents {
+			continue
+		}
+		trade := rebalanceTradeJSON{AssetClass: assetClass, Action: "buy", AmountCents: amount}
+		if amount < 0 {
+			trade.Action = "sell"
+			trade.AmountCents = -amount
+		}
+		if holding, ok := largest[assetClass]; ok {
+			trade.Symbol = holding.Symbol
+			trade.AccountID = holding.AccountID
+		}
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// Converts target rows into our API model.
+func allocationTargetsToJSON(targets []database.AllocationTarget) []allocationTargetJSON {
+	output := make([]allocationTargetJSON, 0, len(targets))
+	for _, target := range targets {
+		output = append(output, allocationTargetJSON{AssetClass: target.AssetClass, TargetPercent: target.TargetPercent})
+	}
+	return output
+}
+
+// Rounds a percentage to two decimals.
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*100) / 100
+}
+
+// One holding's value and asset class.
+type allocationHolding struct {
+	AccountID   string
+	AccountType string
+	Symbol      string
+	AssetClass  string
+	ValueCents  int64
+}
+
+// Rebalancing query parameters. Tolerance is in percentage points.
+type rebalanceOptions struct {
+	Tolerance    float64
+	NewCashCents int64
+	CashOnly     bool
+}
+
+// One asset class's current and target weight for API.
+type allocationClassJSON struct {
+	AssetClass     string   `json:"assetClass"`
+	ValueCents     int64    `json:"valueCents"`
+	CurrentPercent float64  `json:"currentPercent"`
+	TargetPercent  *float64 `json:"targetPercent"`
+	DriftPercent   *float64 `json:"driftPercent"`
+	OutOfBand      bool     `json:"outOfBand"`
+}
+
+// Allocation across asset classes for API.
+type allocationJSON struct {
+	ValueCents             int64                 `json:"valueCents"`
+	UnclassifiedValueCents int64                 `json:"unclassifiedValueCents"`
+	Classes                []allocationClassJSON `json:"classes"`
+}
+
+// Allocation for one account type (e.g. 401k, ira, brokerage).
+type accountTypeAllocationJSON struct {
+	AccountType string `json:"accountType"`
+	allocationJSON
+}
+
+// One suggested trade. AccountID and Symbol point at the class's largest holding, when there is one.
+type rebalanceTradeJSON struct {
+	AssetClass  string `json:"assetClass"`
+	Action      string `json:"action"`
+	AmountCents int64  `json:"amountCents"`
+	Symbol      string `json:"symbol,omitempty"`
+	AccountID   string `json:"accountId,omitempty"`
+}
+
+// Target weight for one asset class.
+type allocationTargetJSON struct {
+	AssetClass    string  `json:"assetClass"`
+	TargetPercent float64 `json:"targetPercent"`
+}
+
+// Allocation targets request and response.
+type allocationTargetsResponse struct {
+	Targets []allocationTargetJSON `json:"targets"`
+}
+
+// Portfolio allocation response.
+type portfolioAllocationResponse struct {
+	AsOf                string                      `json:"asOf"`
+	HasTargets          bool                        `json:"hasTargets"`
+	Targets             []allocationTargetJSON      `json:"targets"`
+	Total               allocationJSON              `json:"total"`
+	AccountTypes        []accountTypeAllocationJSON `json:"accountTypes"`
+	UnclassifiedSymbols []string                    `json:"unclassifiedSymbols"`
+	TolerancePercent    float64                     `json:"tolerancePercent"`
+	NewCashCents        int64                       `json:"newCashCents"`
+	CashOnly            bool                        `json:"cashOnly"`
+	RebalanceNeeded     bool                        `json:"rebalanceNeeded"`
+	Trades              []rebalanceTradeJSON        `json:"trades"`
+}
+
+// Security for API. AssetClass is the class used for allocation.
+type securityJSON struct {
+	Symbol     string  `json:"symbol"`
+	Name       *string `json:"name,omitempty"`
+	PlaidType  *string `json:"plaidType,omitempty"`
+	Override   string  `json:"override,omitempty"`
+	AssetClass string  `json:"assetClass,omitempty"`
+}
+
+// Securities response.
+type securitiesResponse struct {
+	Securities []securityJSON `json:"securities"`
+}
+
+// Asset class override request.
+type assetClassRequest struct {
+	AssetClass string `json:"assetClass"`
+}
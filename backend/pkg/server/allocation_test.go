@@ -0,0 +1,188 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+)
+
+func TestClassifySecurity(t *testing.T) {
+	security := func(securityType, name string) *database.Security {
+		return &database.Security{PlaidType: &securityType, Name: &name}
+	}
+	tests := []struct {
+		name     string
+		symbol   string
+		security *database.Security
+		override string
+		want     string
+	}{
+		{"override wins", "VTI", security("etf", "Vanguard Total Stock Market ETF"), assetClassRealEstate, assetClassRealEstate},
+		{"us etf", "VTI", security("etf", "Vanguard Total Stock Market ETF"), "", assetClassUSEquity},
+		{"intl fund", "VXUS", security("etf", "Vanguard Total International Stock ETF"), "", assetClassIntlEquity},
+		{"bond fund", "BND", security("etf", "Vanguard Total Bond Market ETF"), "", assetClassBonds},
+		{"reit", "VNQ", security("etf", "Vanguard Real Estate ETF"), "", assetClassRealEstate},
+		{"fixed income", "912828", security("fixed income", "US Treasury Note"), "", assetClassBonds},
+		{"crypto", "BTC", security("cryptocurrency", "Bitcoin"), "", assetClassCrypto},
+		{"cash", "CUR:USD", security("cash", "U S Dollar"), "", assetClassCash},
+		{"money market fund", "SPAXX", security("mutual fund", "Fidelity Government Money Market"), "", assetClassCash},
+		{"derivative", "AAPL240119C", security("derivative", "AAPL Call"), "", assetClassOther},
+		{"unknown money market", "FDRXX", nil, "", assetClassCash},
+		{"unknown symbol", "FXAIX", nil, "", assetClassOther},
+	}
+
+	for _, tt := range tests {
+		if got := classifySecurity(tt.symbol, tt.security, tt.override); got != tt.want {
+			t.Errorf("%s: classifySecurity(%q) = %q, want %q", tt.name, tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestPlaidSecuritiesToDB(t *testing.T) {
+	ticker := "VTI"
+	name := "Vanguard Total Stock Market ETF"
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	rows := plaidSecuritiesToDB([]plaid.PlaidSecurity{
+		{SecurityID: "s1", Ticker: &ticker, Name: &name, Type: "etf"},
+		{SecurityID: "s2", Ticker: &ticker, Type: "etf"},
+		{SecurityID: "s3", Name: &name},
+	}, now)
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+	if rows[0].Symbol != "VTI" || *rows[0].SecurityID != "s1" || *rows[0].PlaidType != "etf" {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Symbol != name || rows[1].PlaidType != nil {
+		t.Errorf("row 1 = %+v", rows[1])
+	}
+}
+
+func TestValidateAllocationTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []allocationTargetJSON
+		wantErr bool
+	}{
+		{"empty clears", nil, false},
+		{"adds to 100", []allocationTargetJSON{{assetClassUSEquity, 60}, {assetClassIntlEquity, 30}, {assetClassBonds, 10}}, false},
+		{"short of 100", []allocationTargetJSON{{assetClassUSEquity, 60}, {assetClassBonds, 30}}, true},
+		{"duplicate", []allocationTargetJSON{{assetClassUSEquity, 50}, {assetClassUSEquity, 50}}, true},
+		{"unknown class", []allocationTargetJSON{{assetClassOther, 100}}, true},
+		{"negative", []allocationTargetJSON{{assetClassUSEquity, 110}, {assetClassBonds, -10}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := validateAllocationTargets(tt.targets); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateAllocationTargets() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseRebalanceOptions(t *testing.T) {
+	got, err := parseRebalanceOptions("", "", "")
+	if err != nil || got.Tolerance != defaultRebalanceTolerance || got.NewCashCents != 0 || got.CashOnly {
+		t.Errorf("defaults = %+v, %v", got, err)
+	}
+	got, err = parseRebalanceOptions("2.5", "100000", "true")
+	if err != nil || got.Tolerance != 2.5 || got.NewCashCents != 100000 || !got.CashOnly {
+		t.Errorf("parsed = %+v, %v", got, err)
+	}
+	for _, input := range [][3]string{{"-1", "", ""}, {"", "-5", ""}, {"", "1.5", ""}, {"", "", "maybe"}} {
+		if _, err := parseRebalanceOptions(input[0], input[1], input[2]); err == nil {
+			t.Errorf("parseRebalanceOptions(%q) expected an error", input)
+		}
+	}
+}
+
+func TestBuildAllocation(t *testing.T) {
+	holdings := []allocationHolding{
+		{Symbol: "VTI", AssetClass: assetClassUSEquity, ValueCents: 700000},
+		{Symbol: "BND", AssetClass: assetClassBonds, ValueCents: 300000},
+		{Symbol: "AAPL240119C", AssetClass: assetClassOther, ValueCents: 5000},
+	}
+	targets := map[string]float64{assetClassUSEquity: 60, assetClassIntlEquity: 20, assetClassBonds: 20}
+
+	got := buildAllocation(holdings, targets, 5)
+	if got.ValueCents != 1000000 || got.UnclassifiedValueCents != 5000 {
+		t.Errorf("values = %d / %d, want 1000000 / 5000", got.ValueCents, got.UnclassifiedValueCents)
+	}
+	want := []struct {
+		assetClass string
+		current    float64
+		drift      float64
+		outOfBand  bool
+	}{
+		{assetClassUSEquity, 70, 10, true},
+		{assetClassIntlEquity, 0, -20, true},
+		{assetClassBonds, 30, 10, true},
+	}
+	if len(got.Classes) != len(want) {
+		t.Fatalf("classes = %+v", got.Classes)
+	}
+	for i, w := range want {
+		class := got.Classes[i]
+		if class.AssetClass != w.assetClass || class.CurrentPercent != w.current || *class.DriftPercent != w.drift || class.OutOfBand != w.outOfBand {
+			t.Errorf("class %d = %+v (drift %v), want %+v", i, class, *class.DriftPercent, w)
+		}
+	}
+
+	// Without targets there is no drift.
+	got = buildAllocation(holdings, nil, 5)
+	if len(got.Classes) != 2 || got.Classes[0].TargetPercent != nil || got.Classes[0].OutOfBand {
+		t.Errorf("no targets = %+v", got.Classes)
+	}
+}
+
+func TestRebalanceTrades(t *testing.T) {
+	holdings := []allocationHolding{
+		{AccountID: "a", Symbol: "VTI", AssetClass: assetClassUSEquity, ValueCents: 700000},
+		{AccountID: "b", Symbol: "FXAIX", AssetClass: assetClassUSEquity, ValueCents: 100000},
+		{AccountID: "a", Symbol: "BND", AssetClass: assetClassBonds, ValueCents: 200000},
+		{AccountID: "a", Symbol: "AAPL240119C", AssetClass: assetClassOther, ValueCents: 5000},
+	}
+	targets := map[string]float64{assetClassUSEquity: 60, assetClassIntlEquity: 20, assetClassBonds: 20}
+
+	tests := []struct {
+		name    string
+		options rebalanceOptions
+		want    []rebalanceTradeJSON
+	}{
+		{"full rebalance", rebalanceOptions{Tolerance: 5}, []rebalanceTradeJSON{
+			{AssetClass: assetClassUSEquity, Action: "sell", AmountCents: 200000, Symbol: "VTI", AccountID: "a"},
+			{AssetClass: assetClassIntlEquity, Action: "buy", AmountCents: 200000},
+		}},
+		{"full rebalance with new cash", rebalanceOptions{Tolerance: 5, NewCashCents: 100000}, []rebalanceTradeJSON{
+			{AssetClass: assetClassUSEquity, Action: "sell", AmountCents: 140000, Symbol: "VTI", AccountID: "a"},
+			{AssetClass: assetClassIntlEquity, Action: "buy", AmountCents: 220000},
+			{AssetClass: assetClassBonds, Action: "buy", AmountCents: 20000, Symbol: "BND", AccountID: "a"},
+		}},
+		{"cash only", rebalanceOptions{Tolerance: 5, NewCashCents: 100000, CashOnly: true}, []rebalanceTradeJSON{
+			{AssetClass: assetClassIntlEquity, Action: "buy", AmountCents: 91667},
+			{AssetClass: assetClassBonds, Action: "buy", AmountCents: 8333, Symbol: "BND", AccountID: "a"},
+		}},
+		{"cash only without cash", rebalanceOptions{Tolerance: 5, CashOnly: true}, []rebalanceTradeJSON{}},
+		{"inside a wide band", rebalanceOptions{Tolerance: 25}, []rebalanceTradeJSON{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rebalanceTrades(holdings, targets, tt.options)
+			if len(got) != len(tt.want) {
+				t.Fatalf("trades = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("trade %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if got := rebalanceTrades(holdings, nil, rebalanceOptions{Tolerance: 5}); len(got) != 0 {
+		t.Errorf("trades without targets = %+v, want none", got)
+	}
+}
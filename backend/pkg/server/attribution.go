@@ -0,0 +1,441 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Days apart a bank-side transfer and an investment account's deposit may be and still be the same money.
+const attributionMatchWindowDays = 5
+
+// Longest range one attribution request may cover.
+const maxAttributionMonths = 120
+
+// Splits each month's change in portfolio value and net worth between from and to (default: the last 12
+// months) into contributions, withdrawals and market gain or loss.
+func handleGetPortfolioAttribution(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	today := calendarDay(GetLocalNow())
+	from, to, err := parseAttributionRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"), today)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input, err := loadAttributionInput(r.Context(), deps.db, from, to)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := buildAttribution(input, from, to)
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("portfolio attribution encode: %v", err)
+	}
+}
+
+// Parses the attribution range into the first days of its first and last months. Dates may be YYYY-MM-DD or
+// YYYY-MM.
+func parseAttributionRange(fromValue, toValue string, today time.Time) (time.Time, time.Time, error) {
+	parse := func(value string) (time.Time, bool) {
+		for _, layout := range []string{dateLayout, "2006-01"} {
+			if date, err := time.Parse(layout, value); err == nil {
+				return monthStart(date), true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	to := monthStart(today)
+	if toValue != "" {
+		var ok bool
+		if to, ok = parse(toValue); !ok {
+			return to, to, errors.New("to must be YYYY-MM-DD or YYYY-MM")
+		}
+	}
+	from := to.AddDate(0, -11, 0)
+	if fromValue != "" {
+		var ok bool
+		if from, ok = parse(fromValue); !ok {
+			return from, to, errors.New("from must be YYYY-MM-DD or YYYY-MM")
+		}
+	}
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+	if !to.Before(from.AddDate(0, maxAttributionMonths, 0)) {
+		return from, to, errors.New("range must not exceed 120 months")
+	}
+	return from, to, nil
+}
+
+// Loads the month-end values and cash flows for the range and the month before it. Year-end summaries stand in
+// for Decembers whose monthly snapshots have been rolled up. Cash flows come from investment activity (or
+// deposits detected in an account's own feed), and bank-side transfers in the Investments category are loaded
+// so they can be matched against them.
+func loadAttributionInput(ctx context.Context, db *database.Client, from, to time.Time) (attributionInput, error) {
+	var input attributionInput
+	since := from.AddDate(0, -1, 0)
+	var err error
+	input.Monthly, err = db.ListMonthlySnapshots(ctx, since, monthEnd(to))
+	if err != nil {
+		return input, errors.New("failed to list monthly snapshots: " + err.Error())
+	}
+	for year := since.Year(); year <= to.Year(); year++ {
+		summaries, err := db.ListYearlyPortfolioSummaries(ctx, year)
+		if err != nil {
+			return input, errors.New("failed to list yearly portfolio summaries: " + err.Error())
+		}
+		input.Yearly = append(input.Yearly, summaries...)
+	}
+	input.NetWorth, err = db.ListMonthlyNetWorth(ctx, since, monthEnd(to))
+	if err != nil {
+		return input, errors.New("failed to list monthly net worth: " + err.Error())
+	}
+
+	// Investment accounts are the ones with values plus any Plaid reports as investment accounts.
+	investmentAccounts := make(map[string]bool)
+	for _, snapshot := range input.Monthly {
+		investmentAccounts[snapshot.AccountID] = true
+	}
+	for _, summary := range input.Yearly {
+		investmentAccounts[summary.AccountID] = true
+	}
+	plaidAccounts, err := db.ListPlaidAccounts(ctx)
+	if err != nil {
+		return input, errors.New("failed to list accounts: " + err.Error())
+	}
+	for _, account := range plaidAccounts {
+		if isPlaidInvestment(account.Type) {
+			investmentAccounts[account.AccountID] = true
+		}
+	}
+	accountIDs := make([]string, 0, len(investmentAccounts))
+	for accountID := range investmentAccounts {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	var hasActivity map[string]bool
+	input.AccountFlows, _, hasActivity, err = loadCashFlows(ctx, db, accountIDs, since)
+	if err != nil {
+		return input, err
+	}
+	input.UnobservedAccounts = make(map[string]bool)
+	for _, accountID := range accountIDs {
+		if !hasActivity[accountID] && len(input.AccountFlows[accountID]) == 0 {
+			input.UnobservedAccounts[accountID] = true
+		}
+	}
+
+	categories, err := db.ListCategories(ctx)
+	if err != nil {
+		return input, errors.New("failed to list categories: " + err.Error())
+	}
+	var investmentsCategoryID int64
+	for _, category := range categories {
+		if category.Name == "Investments" {
+			investmentsCategoryID = category.ID
+		}
+	}
+	transactions, err := db.ListTransactionsBetween(ctx, since.AddDate(0, 0, -attributionMatchWindowDays),
+		monthEnd(to).AddDate(0, 0, attributionMatchWindowDays+1))
+	if err != nil {
+		return input, errors.New("failed to list transactions: " + err.Error())
+	}
+	for _, transaction := range transactions {
+		if transaction.Pending || transaction.CategoryID == nil || *transaction.CategoryID != investmentsCategoryID ||
+			investmentAccounts[transaction.PlaidAccountID] {
+			continue
+		}
+		input.BankTransfers = append(input.BankTransfers, transaction)
+	}
+	return input, nil
+}
+
+// Matches bank-side transfers to investment account cash flows of the opposite amount within
+// attributionMatchWindowDays, each flow matching at most one transfer. Returns the unmatched transfers as
+// flows into the portfolio (a payment out of the bank is a contribution) and the number matched.
+func matchInvestmentTransfers(transfers []database.Transaction, accountFlows map[string][]cashFlow) ([]cashFlow, int) {
+	type flowRef struct {
+		AccountID string
+		Index     int
+	}
+	byAmount := make(map[int64][]flowRef)
+	accountIDs := make([]string, 0, len(accountFlows))
+	for accountID := range accountFlows {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+	for _, accountID := range accountIDs {
+		for i, flow := range accountFlows[accountID] {
+			byAmount[flow.AmountCents] = append(byAmount[flow.AmountCents], flowRef{AccountID: accountID, Index: i})
+		}
+	}
+
+	sorted := make([]database.Transaction, len(transfers))
+	copy(sorted, transfers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date.Time)
+	})
+
+	used := make(map[flowRef]bool)
+	var unmatched []cashFlow
+	matched := 0
+	for _, transfer := range sorted {
+		amount := -transfer.AmountCents
+		if amount == 0 {
+			continue
+		}
+		date := calendarDay(transfer.Date.Time)
+
+		// Picks the closest unused flow of the same amount.
+		best, bestDays := flowRef{}, -1
+		for _, ref := range byAmount[amount] {
+			if used[ref] {
+				continue
+			}
+			days := int(calendarDay(accountFlows[ref.AccountID][ref.Index].Date).Sub(date).Hours() / 24)
+			if days < 0 {
+				days = -days
+			}
+			if days <= attributionMatchWindowDays && (bestDays < 0 || days < bestDays) {
+				best, bestDays = ref, days
+			}
+		}
+		if bestDays >= 0 {
+			used[best] = true
+			matched++
+			continue
+		}
+		unmatched = append(unmatched, cashFlow{Date: date, AmountCents: amount})
+	}
+	return unmatched, matched
+}
+
+// Splits each month's change from the previous month's value. For accounts valued in both months, market gain
+// is the change less the month's net cash flow. Accounts valued in only one of the two months (newly linked or
+// missing a snapshot) are reported as unattributed. Unmatched bank transfers count as contributions or
+// withdrawals of the portfolio as a whole only when an account valued in both months has no cash flows we can
+// see, since that's the only tracked account they could have gone to; otherwise they went to an untracked
+// account and are reported without touching market gain. A net worth month is split when its portfolio month
+// is: market gain is the portfolio's, and the rest of the change is savings. Months without a previous value
+// are skipped.
+func buildAttribution(input attributionInput, from, to time.Time) attributionResponse {
+	resp := attributionResponse{
+		From:      from.Format(dateLayout),
+		To:        monthEnd(to).Format(dateLayout),
+		Portfolio: []portfolioAttributionJSON{},
+		NetWorth:  []netWorthAttributionJSON{},
+	}
+
+	// Values each account at the end of each month, preferring monthly snapshots to year-end summaries and
+	// the latest snapshot when a month has two.
+	values := make(map[time.Time]map[string]int64)
+	set := func(month time.Time, accountID string, cents int64) {
+		if values[month] == nil {
+			values[month] = make(map[string]int64)
+		}
+		values[month][accountID] = cents
+	}
+	for _, summary := range input.Yearly {
+		set(time.Date(summary.Year, time.December, 1, 0, 0, 0, 0, time.UTC), summary.AccountID, summary.PortfolioValueCents)
+	}
+	monthly := make([]database.MonthlySnapshot, len(input.Monthly))
+	copy(monthly, input.Monthly)
+	sort.SliceStable(monthly, func(i, j int) bool {
+		return monthly[i].Month.Before(monthly[j].Month.Time)
+	})
+	for _, snapshot := range monthly {
+		set(monthStart(snapshot.Month.Time), snapshot.AccountID, snapshot.PortfolioValueCents)
+	}
+
+	unmatched, matched := matchInvestmentTransfers(input.BankTransfers, input.AccountFlows)
+	resp.MatchedTransfers = matched
+	flowsByMonth := func(flows []cashFlow) map[time.Time][]cashFlow {
+		byMonth := make(map[time.Time][]cashFlow)
+		for _, flow := range flows {
+			month := monthStart(flow.Date)
+			byMonth[month] = append(byMonth[month], flow)
+		}
+		return byMonth
+	}
+	accountFlows := make(map[string]map[time.Time][]cashFlow, len(input.AccountFlows))
+	for accountID, flows := range input.AccountFlows {
+		accountFlows[accountID] = flowsByMonth(flows)
+	}
+	unmatchedFlows := flowsByMonth(unmatched)
+
+	netWorth := make(map[time.Time]int64)
+	sortedNetWorth := make([]database.MonthlyNetWorth, len(input.NetWorth))
+	copy(sortedNetWorth, input.NetWorth)
+	sort.SliceStable(sortedNetWorth, func(i, j int) bool {
+		return sortedNetWorth[i].Month.Before(sortedNetWorth[j].Month.Time)
+	})
+	for _, row := range sortedNetWorth {
+		netWorth[monthStart(row.Month.Time)] = row.NetWorthCents
+	}
+
+	addFlows := func(point *portfolioAttributionJSON, flows []cashFlow) int64 {
+		var net int64
+		for _, flow := range flows {
+			if flow.AmountCents > 0 {
+				point.ContributionsCents += flow.AmountCents
+			} else {
+				point.WithdrawalsCents -= flow.AmountCents
+			}
+			net += flow.AmountCents
+		}
+		return net
+	}
+
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		previous, current := values[month.AddDate(0, -1, 0)], values[month]
+		if len(previous) == 0 || len(current) == 0 {
+			continue
+		}
+
+		point := portfolioAttributionJSON{Month: month.Format("2006-01")}
+		accountIDs := make(map[string]bool, len(current))
+		for accountID := range previous {
+			accountIDs[accountID] = true
+		}
+		for accountID := range current {
+			accountIDs[accountID] = true
+		}
+		trackedDestination := false
+		for accountID := range accountIDs {
+			start, hasStart := previous[accountID]
+			end, hasEnd := current[accountID]
+			point.StartValueCents += start
+			point.EndValueCents += end
+			if !hasStart || !hasEnd {
+				point.UnattributedCents += end - start
+				continue
+			}
+			if input.UnobservedAccounts[accountID] {
+				trackedDestination = true
+			}
+			net := addFlows(&point, accountFlows[accountID][month])
+			point.MarketGainCents += end - start - net
+		}
+		if trackedDestination {
+			net := addFlows(&point, unmatchedFlows[month])
+			point.UnmatchedTransfersCents = net
+			point.MarketGainCents -= net
+		} else {
+			for _, flow := range unmatchedFlows[month] {
+				point.UnmatchedTransfersCents += flow.AmountCents
+			}
+		}
+		point.ChangeCents = point.EndValueCents - point.StartValueCents
+		resp.Portfolio = append(resp.Portfolio, point)
+
+		start, hasStart := netWorth[month.AddDate(0, -1, 0)]
+		end, hasEnd := netWorth[month]
+		if hasStart && hasEnd {
+			resp.NetWorth = append(resp.NetWorth, netWorthAttributionJSON{
+				Month:              point.Month,
+				StartValueCents:    start,
+				EndValueCents:      end,
+				ChangeCents:        end - start,
+				ContributionsCents: point.ContributionsCents,
+				WithdrawalsCents:   point.WithdrawalsCents,
+				MarketGainCents:    point.MarketGainCents,
+				SavingsCents:       end - start - point.MarketGainCents,
+			})
+		}
+	}
+
+	// Totals sum the months, starting from the first month's start and ending at the last month's end.
+	for i, point := range resp.Portfolio {
+		if i == 0 {
+			resp.PortfolioTotal.StartValueCents = point.StartValueCents
+		}
+		resp.PortfolioTotal.EndValueCents = point.EndValueCents
+		resp.PortfolioTotal.ChangeCents += point.ChangeCents
+		resp.PortfolioTotal.ContributionsCents += point.ContributionsCents
+		resp.PortfolioTotal.WithdrawalsCents += point.WithdrawalsCents
+		resp.PortfolioTotal.MarketGainCents += point.MarketGainCents
+		resp.PortfolioTotal.UnmatchedTransfersCents += point.UnmatchedTransfersCents
+		resp.PortfolioTotal.UnattributedCents += point.UnattributedCents
+	}
+	for i, point := range resp.NetWorth {
+		if i == 0 {
+			resp.NetWorthTotal.StartValueCents = point.StartValueCents
+		}
+		resp.NetWorthTotal.EndValueCents = point.EndValueCents
+		resp.NetWorthTotal.ChangeCents += point.ChangeCents
+		resp.NetWorthTotal.ContributionsCents += point.ContributionsCents
+		resp.NetWorthTotal.WithdrawalsCents += point.WithdrawalsCents
+		resp.NetWorthTotal.MarketGainCents += point.MarketGainCents
+		resp.NetWorthTotal.SavingsCents += point.SavingsCents
+	}
+	return resp
+}
+
+// Stored rows an attribution reads.
+type attributionInput struct {
+	Monthly       []database.MonthlySnapshot
+	Yearly        []database.YearlyPortfolioSummary
+	NetWorth      []database.MonthlyNetWorth
+	AccountFlows  map[string][]cashFlow
+	BankTransfers []database.Transaction
+	// Investment accounts with neither investment activity nor detected transfers, so deposits into them
+	// can't be matched.
+	UnobservedAccounts map[string]bool
+}
+
+// Attribution response.
+type attributionResponse struct {
+	From             string                     `json:"from"`
+	To               string                     `json:"to"`
+	MatchedTransfers int                        `json:"matchedTransfers"`
+	Portfolio        []portfolioAttributionJSON `json:"portfolio"`
+	PortfolioTotal   portfolioAttributionJSON   `json:"portfolioTotal"`
+	NetWorth         []netWorthAttributionJSON  `json:"netWorth"`
+	NetWorthTotal    netWorthAttributionJSON    `json:"netWorthTotal"`
+}
+
+// One month's portfolio change for API. Change = contributions - withdrawals + market gain + unattributed.
+type portfolioAttributionJSON struct {
+	Month              string `json:"month,omitempty"`
+	StartValueCents    int64  `json:"startValueCents"`
+	EndValueCents      int64  `json:"endValueCents"`
+	ChangeCents        int64  `json:"changeCents"`
+	ContributionsCents int64  `json:"contributionsCents"`
+	WithdrawalsCents   int64  `json:"withdrawalsCents"`
+	MarketGainCents    int64  `json:"marketGainCents"`
+	// Net of the bank-side transfers no investment account reported. Included in contributions and withdrawals
+	// only when an account valued in both months has no visible cash flows; otherwise they went to an
+	// untracked account and don't affect market gain.
+	UnmatchedTransfersCents int64 `json:"unmatchedTransfersCents"`
+	// Change in accounts valued in only one of the two months.
+	UnattributedCents int64 `json:"unattributedCents"`
+}
+
+// One month's net worth change for API. Change = market gain + savings; contributions and withdrawals move
+// money between cash and investments, so they are part of savings rather than extra change.
+type netWorthAttributionJSON struct {
+	Month              string `json:"month,omitempty"`
+	StartValueCents    int64  `json:"startValueCents"`
+	EndValueCents      int64  `json:"endValueCents"`
+	ChangeCents        int64  `json:"changeCents"`
+	ContributionsCents int64  `json:"contributionsCents"`
+	WithdrawalsCents   int64  `json:"withdrawalsCents"`
+	MarketGainCents    int64  `json:"marketGainCents"`
+	SavingsCents       int64  `json:"savingsCents"`
+}
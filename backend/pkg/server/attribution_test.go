@@ -0,0 +1,157 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestBuildAttribution(t *testing.T) {
+	snapshot := func(m time.Month, d int, account string, cents int64) database.MonthlySnapshot {
+		return database.MonthlySnapshot{Month: database.DateOnly{Time: utcDay(2026, m, d)}, AccountID: account, PortfolioValueCents: cents}
+	}
+	transfer := func(m time.Month, d int, cents int64) database.Transaction {
+		return database.Transaction{PlaidAccountID: "checking", Date: database.DateOnly{Time: utcDay(2026, m, d)}, AmountCents: cents}
+	}
+	input := attributionInput{
+		Yearly: []database.YearlyPortfolioSummary{
+			{Year: 2025, AccountID: "brokerage", PortfolioValueCents: 95000},
+			{Year: 2025, AccountID: "ira", PortfolioValueCents: 50000},
+		},
+		Monthly: []database.MonthlySnapshot{
+			snapshot(1, 31, "brokerage", 100000),
+			snapshot(1, 31, "ira", 50000),
+			// February has a month-start and a month-end row; the later one wins.
+			snapshot(2, 28, "brokerage", 112000),
+			snapshot(2, 1, "brokerage", 999),
+			snapshot(2, 28, "ira", 51000),
+			snapshot(3, 31, "brokerage", 115000),
+			snapshot(3, 31, "ira", 51000),
+			// Linked in March.
+			snapshot(3, 31, "new", 20000),
+		},
+		NetWorth: []database.MonthlyNetWorth{
+			{Month: database.DateOnly{Time: utcDay(2026, 1, 31)}, NetWorthCents: 200000},
+			{Month: database.DateOnly{Time: utcDay(2026, 2, 28)}, NetWorthCents: 215000},
+		},
+		AccountFlows: map[string][]cashFlow{
+			"brokerage": {{Date: utcDay(2026, 2, 10), AmountCents: 10000}},
+		},
+		BankTransfers: []database.Transaction{
+			// The ACH behind the brokerage deposit.
+			transfer(2, 8, -10000),
+			// No account reported this one.
+			transfer(3, 5, -3000),
+		},
+		// The IRA has no activity or transfer feed, so the March transfer may have gone there.
+		UnobservedAccounts: map[string]bool{"ira": true},
+	}
+
+	got := buildAttribution(input, utcDay(2026, 1, 1), utcDay(2026, 3, 1))
+	want := []portfolioAttributionJSON{
+		{Month: "2026-01", StartValueCents: 145000, EndValueCents: 150000, ChangeCents: 5000, MarketGainCents: 5000},
+		{Month: "2026-02", StartValueCents: 150000, EndValueCents: 163000, ChangeCents: 13000, ContributionsCents: 10000, MarketGainCents: 3000},
+		{Month: "2026-03", StartValueCents: 163000, EndValueCents: 186000, ChangeCents: 23000, ContributionsCents: 3000,
+			UnmatchedTransfersCents: 3000, UnattributedCents: 20000},
+	}
+	if len(got.Portfolio) != len(want) {
+		t.Fatalf("portfolio = %+v, want %+v", got.Portfolio, want)
+	}
+	for i := range want {
+		if got.Portfolio[i] != want[i] {
+			t.Errorf("month %d = %+v, want %+v", i, got.Portfolio[i], want[i])
+		}
+	}
+	wantTotal := portfolioAttributionJSON{StartValueCents: 145000, EndValueCents: 186000, ChangeCents: 41000, ContributionsCents: 13000,
+		MarketGainCents: 8000, UnmatchedTransfersCents: 3000, UnattributedCents: 20000}
+	if got.PortfolioTotal != wantTotal {
+		t.Errorf("total = %+v, want %+v", got.PortfolioTotal, wantTotal)
+	}
+	if got.MatchedTransfers != 1 {
+		t.Errorf("matched transfers = %d, want 1", got.MatchedTransfers)
+	}
+
+	wantNetWorth := netWorthAttributionJSON{Month: "2026-02", StartValueCents: 200000, EndValueCents: 215000, ChangeCents: 15000,
+		ContributionsCents: 10000, MarketGainCents: 3000, SavingsCents: 12000}
+	if len(got.NetWorth) != 1 || got.NetWorth[0] != wantNetWorth {
+		t.Errorf("net worth = %+v, want [%+v]", got.NetWorth, wantNetWorth)
+	}
+}
+
+func TestBuildAttributionUntrackedDestination(t *testing.T) {
+	snapshot := func(m time.Month, d int, account string, cents int64) database.MonthlySnapshot {
+		return database.MonthlySnapshot{Month: database.DateOnly{Time: utcDay(2026, m, d)}, AccountID: account, PortfolioValueCents: cents}
+	}
+	input := attributionInput{
+		Monthly: []database.MonthlySnapshot{
+			snapshot(1, 31, "brokerage", 100000),
+			snapshot(2, 28, "brokerage", 104000),
+		},
+		AccountFlows: map[string][]cashFlow{
+			"brokerage": {{Date: utcDay(2026, 2, 10), AmountCents: 1000}},
+		},
+		// Every tracked account reports its flows, so this went to an account we don't track.
+		BankTransfers: []database.Transaction{
+			{PlaidAccountID: "checking", Date: database.DateOnly{Time: utcDay(2026, 2, 20)}, AmountCents: -5000},
+		},
+	}
+
+	got := buildAttribution(input, utcDay(2026, 2, 1), utcDay(2026, 2, 1))
+	want := portfolioAttributionJSON{Month: "2026-02", StartValueCents: 100000, EndValueCents: 104000, ChangeCents: 4000,
+		ContributionsCents: 1000, MarketGainCents: 3000, UnmatchedTransfersCents: 5000}
+	if len(got.Portfolio) != 1 || got.Portfolio[0] != want {
+		t.Errorf("portfolio = %+v, want [%+v]", got.Portfolio, want)
+	}
+}
+
+func TestMatchInvestmentTransfers(t *testing.T) {
+	flows := map[string][]cashFlow{
+		"brokerage": {{Date: utcDay(2026, 3, 10), AmountCents: 5000}, {Date: utcDay(2026, 3, 20), AmountCents: 5000}},
+		"ira":       {{Date: utcDay(2026, 3, 12), AmountCents: -2000}},
+	}
+	transfers := []database.Transaction{
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 18)}, AmountCents: -5000},
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 9)}, AmountCents: -5000},
+		// A withdrawal landing in checking.
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 14)}, AmountCents: 2000},
+		// Too far from any deposit.
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 1)}, AmountCents: -5000},
+	}
+
+	unmatched, matched := matchInvestmentTransfers(transfers, flows)
+	if matched != 3 {
+		t.Errorf("matched = %d, want 3", matched)
+	}
+	if len(unmatched) != 1 || !unmatched[0].Date.Equal(utcDay(2026, 3, 1)) || unmatched[0].AmountCents != 5000 {
+		t.Errorf("unmatched = %+v, want a 5000 contribution on 2026-03-01", unmatched)
+	}
+}
+
+func TestParseAttributionRange(t *testing.T) {
+	today := utcDay(2026, 3, 20)
+	tests := []struct {
+		name     string
+		from, to string
+		want     [2]time.Time
+		wantErr  bool
+	}{
+		{name: "default", want: [2]time.Time{utcDay(2025, 4, 1), utcDay(2026, 3, 1)}},
+		{name: "months", from: "2025-07", to: "2025-12", want: [2]time.Time{utcDay(2025, 7, 1), utcDay(2025, 12, 1)}},
+		{name: "dates", from: "2025-07-15", to: "2025-12-31", want: [2]time.Time{utcDay(2025, 7, 1), utcDay(2025, 12, 1)}},
+		{name: "reversed", from: "2026-02", to: "2026-01", wantErr: true},
+		{name: "too long", from: "2010-01", wantErr: true},
+		{name: "bad date", from: "07/2025", wantErr: true},
+	}
+	for _, tt := range tests {
+		from, to, err := parseAttributionRange(tt.from, tt.to, today)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (!from.Equal(tt.want[0]) || !to.Equal(tt.want[1])) {
+			t.Errorf("%s: range = %s..%s, want %s..%s", tt.name, from.Format(dateLayout), to.Format(dateLayout),
+				tt.want[0].Format(dateLayout), tt.want[1].Format(dateLayout))
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/marketdata"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Registers the benchmark routes.
+func registerBenchmarkRoutes(mux *http.ServeMux, deps apiDependencies) {
+	// GET/POST /api/benchmarks lists or adds benchmarks.
+	mux.Handle("/api/benchmarks", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleListBenchmarks(w, r, deps)
+		case http.MethodPost:
+			handleAddBenchmark(w, r, deps)
+		default:
+			methodNotAllowed(w, http.MethodGet)
+		}
+	})))
+
+	// DELETE /api/benchmarks/{symbol} removes a benchmark.
+	mux.Handle("/api/benchmarks/{symbol}", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			methodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		handleDeleteBenchmark(w, r, deps)
+	})))
+
+	// POST /api/benchmarks/{symbol}/prices imports daily closes from an uploaded CSV.
+	mux.Handle("/api/benchmarks/{symbol}/prices", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleUploadBenchmarkPrices(w, r, deps)
+	})))
+
+	// POST /api/benchmarks/refresh fetches missing closes for every benchmark from the price provider.
+	mux.Handle("/api/benchmarks/refresh", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleRefreshBenchmarks(w, r, deps)
+	})))
+
+	// GET /api/portfolio/benchmark?symbol=VTI compares the portfolio (or accountId) with a benchmark over the
+	// snapshot periods.
+	mux.Handle("/api/portfolio/benchmark", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetBenchmarkComparison(w, r, deps)
+	})))
+}
+
+// Returns the benchmarks with the date of each one's latest stored close.
+func handleListBenchmarks(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	benchmarks, err := deps.db.ListBenchmarks(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	output := make([]benchmarkJSON, 0, len(benchmarks))
+	for _, benchmark := range benchmarks {
+		item := benchmarkJSON{Symbol: benchmark.Symbol, Name: benchmark.Name}
+		if latest, err := deps.db.GetLatestPriceDate(r.Context(), benchmark.Symbol); err == nil && latest != nil {
+			item.LatestPriceDate = latest.Format(dateLayout)
+		}
+		output = append(output, item)
+	}
+	_ = json.NewEncoder(w).Encode(benchmarksResponse{Benchmarks: output, ProviderConfigured: deps.priceProvider != nil})
+}
+
+// Adds a benchmark and, when a price provider is configured, fetches its closes.
+func handleAddBenchmark(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	var req benchmarkJSON
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Symbol == "" {
+		writeJSONError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	now := GetLocalNow()
+	err := deps.db.UpsertBenchmark(r.Context(), &database.Benchmark{Symbol: req.Symbol, Name: req.Name, CreatedAt: &now})
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if deps.priceProvider != nil {
+		written, err := fetchBenchmarkPrices(r.Context(), deps, req.Symbol, calendarDay(now))
+		if err != nil {
+			log.Printf("benchmarks: fetch prices for %s: %v", req.Symbol, err)
+		}
+		req.PricesImported = written
+	}
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(req)
+}
+
+// Removes a benchmark.
+func handleDeleteBenchmark(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	err := deps.db.DeleteBenchmark(r.Context(), strings.ToUpper(r.PathValue("symbol")))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Imports a benchmark's daily closes from an uploaded CSV (e.g. a Yahoo Finance history export).
+func handleUploadBenchmarkPrices(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to get file from request")
+		return
+	}
+	defer file.Close()
+
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	closes, err := marketdata.ParseClosesCSV(file, symbol)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to parse CSV: "+err.Error())
+		return
+	}
+	err = deps.db.UpsertPrices(r.Context(), dailyClosesToDB(closes, priceSourceCSV))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to save prices: "+err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(benchmarkJSON{Symbol: symbol, PricesImported: len(closes)})
+}
+
+// Fetches missing closes for every benchmark.
+func handleRefreshBenchmarks(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	if deps.priceProvider == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "no price provider is configured")
+		return
+	}
+
+	written, err := refreshBenchmarkPrices(r.Context(), deps)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(benchmarkRefreshResponse{PricesImported: written})
+}
+
+// Returns the portfolio's daily and monthly value series (as in /api/portfolio/snapshots) next to the
+// benchmark's growth from the same starting value, and the value had every contribution bought the benchmark.
+func handleGetBenchmarkComparison(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+	if symbol == "" {
+		writeJSONError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	accountID := r.URL.Query().Get("accountId")
+
+	now := GetLocalNow()
+	today := calendarDay(now)
+	series, err := loadSnapshotSeries(r.Context(), deps.db, accountID, now)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	start := today
+	for _, points := range [][]SnapshotDataPoint{series.Daily, series.Monthly} {
+		if len(points) > 0 {
+			if date, err := time.Parse(dateLayout, points[0].Date); err == nil && date.Before(start) {
+				start = date
+			}
+		}
+	}
+
+	// Reads closes from a week before the first point so it has a prior close.
+	closes, err := deps.db.ListPrices(r.Context(), symbol, start.AddDate(0, 0, -7), today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list prices: "+err.Error())
+		return
+	}
+
+	// Loads the contributions of the account, or of every investment account.
+	accountIDs := []string{accountID}
+	if accountID == "" {
+		plaidAccounts, _ := deps.db.ListPlaidAccounts(r.Context())
+		accountIDs = accountIDs[:0]
+		for _, account := range plaidAccounts {
+			if account.Type == "investment" {
+				accountIDs = append(accountIDs, account.AccountID)
+			}
+		}
+	}
+	accountFlows, _, _, err := loadCashFlows(r.Context(), deps.db, accountIDs, start)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var flows []cashFlow
+	for _, id := range accountIDs {
+		flows = append(flows, accountFlows[id]...)
+	}
+	flows = sortedCashFlows(flows)
+
+	resp := benchmarkComparisonResponse{
+		Symbol:  symbol,
+		Daily:   benchmarkSeries(series.Daily, closes, flows, false, today),
+		Monthly: benchmarkSeries(series.Monthly, closes, flows, true, today),
+	}
+	if len(closes) > 0 {
+		resp.LatestPriceDate = closes[len(closes)-1].Date.Format(dateLayout)
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("benchmark comparison encode: %v", err)
+	}
+}
+
+// Fetches missing closes for every benchmark and returns how many were stored.
+func refreshBenchmarkPrices(ctx context.Context, deps apiDependencies) (int, error) {
+	if deps.db == nil || deps.priceProvider == nil {
+		return 0, nil
+	}
+	benchmarks, err := deps.db.ListBenchmarks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	today := calendarDay(GetLocalNow())
+	written := 0
+	for _, benchmark := range benchmarks {
+		count, err := fetchBenchmarkPrices(ctx, deps, benchmark.Symbol, today)
+		if err != nil {
+			log.Printf("benchmarks: fetch prices for %s: %v", benchmark.Symbol, err)
+			continue
+		}
+		written += count
+	}
+	return written, nil
+}
+
+// Fetches a symbol's closes after its latest stored close (or since January two years ago) through today.
+func fetchBenchmarkPrices(ctx context.Context, deps apiDependencies, symbol string, today time.Time) (int, error) {
+	from := time.Date(today.Year()-2, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -7)
+	latest, err := deps.db.GetLatestPriceDate(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	if latest != nil {
+		from = calendarDay(*latest).AddDate(0, 0, 1)
+	}
+	if from.After(today) {
+		return 0, nil
+	}
+
+	closes, err := deps.priceProvider.DailyCloses(ctx, symbol, from, today)
+	if err != nil {
+		return 0, err
+	}
+	err = deps.db.UpsertPrices(ctx, dailyClosesToDB(closes, priceSourceProvider))
+	if err != nil {
+		return 0, err
+	}
+	return len(closes), nil
+}
+
+// Converts provider closes into our DB model.
+func dailyClosesToDB(closes []marketdata.DailyClose, source string) []database.Price {
+	prices := make([]database.Price, 0, len(closes))
+	for _, dailyClose := range closes {
+		prices = append(prices, database.Price{
+			Symbol:     dailyClose.Symbol,
+			Date:       database.DateOnly{Time: dailyClose.Date},
+			CloseCents: dailyClose.CloseCents,
+			Source:     source,
+		})
+	}
+	return prices
+}
+
+// Pairs each portfolio point with the benchmark. The benchmark series grows the first point's value by the
+// benchmark's return; the contributions series also buys the benchmark with every later cash flow (and sells
+// it for withdrawals) at that day's close. Monthly points are valued at month end (or today for this month).
+// Points before the first close are left out.
+func benchmarkSeries(points []SnapshotDataPoint, closes []database.Price, flows []cashFlow, monthly bool, today time.Time) benchmarkSeriesJSON {
+	series := benchmarkSeriesJSON{Points: []benchmarkPointJSON{}}
+	var startClose, startValue int64
+	var startDate time.Time
+	var shares float64
+	flowIndex := 0
+	for _, point := range points {
+		date, err := time.Parse(dateLayout, point.Date)
+		if err != nil {
+			continue
+		}
+		if monthly {
+			date = minTime(date.AddDate(0, 1, -1), today)
+		}
+		closeCents, ok := closeOnOrBefore(closes, date)
+		if !ok {
+			continue
+		}
+
+		if startClose == 0 {
+			if point.PortfolioValueCents <= 0 {
+				continue
+			}
+			startClose, startValue, startDate = closeCents, point.PortfolioValueCents, date
+			shares = float64(point.PortfolioValueCents) / float64(startClose)
+			series.StartDate = point.Date
+			for flowIndex < len(flows) && !flows[flowIndex].Date.After(startDate) {
+				flowIndex++
+			}
+		}
+		for flowIndex < len(flows) && !flows[flowIndex].Date.After(date) {
+			if flowClose, ok := closeOnOrBefore(closes, flows[flowIndex].Date); ok {
+				shares += float64(flows[flowIndex].AmountCents) / float64(flowClose)
+			}
+			flowIndex++
+		}
+
+		benchmarkValue := int64(math.Round(float64(startValue) * float64(closeCents) / float64(startClose)))
+		series.Points = append(series.Points, benchmarkPointJSON{
+			Date:                    point.Date,
+			PortfolioValueCents:     point.PortfolioValueCents,
+			BenchmarkValueCents:     benchmarkValue,
+			ContributionsValueCents: int64(math.Round(shares * float64(closeCents))),
+		})
+		series.EndDate = point.Date
+		growth := roundPercent((float64(closeCents)/float64(startClose) - 1) * 100)
+		series.BenchmarkReturnPercent = &growth
+	}
+	return series
+}
+
+// Returns the latest close on or before the date. Closes must be oldest first.
+func closeOnOrBefore(closes []database.Price, date time.Time) (int64, bool) {
+	price, ok := priceOnOrBefore(closes, date)
+	return price.CloseCents, ok
+}
+
+// Returns the latest price row on or before the date. Closes must be oldest first.
+func priceOnOrBefore(closes []database.Price, date time.Time) (database.Price, bool) {
+	index := sort.Search(len(closes), func(i int) bool {
+		return closes[i].Date.After(date)
+	})
+	if index == 0 {
+		return database.Price{}, false
+	}
+	return closes[index-1], true
+}
+
+// Benchmark for API. PricesImported is set when closes were just imported.
+type benchmarkJSON struct {
+	Symbol          string  `json:"symbol"`
+	Name            *string `json:"name,omitempty"`
+	LatestPriceDate string  `json:"latestPriceDate,omitempty"`
+	PricesImported  int     `json:"pricesImported,omitempty"`
+}
+
+// Benchmarks response.
+type benchmarksResponse struct {
+	Benchmarks         []benchmarkJSON `json:"benchmarks"`
+	ProviderConfigured bool            `json:"providerConfigured"`
+}
+
+// Benchmark refresh response.
+type benchmarkRefreshResponse struct {
+	PricesImported int `json:"pricesImported"`
+}
+
+// One date of the comparison.
+type benchmarkPointJSON struct {
+	Date                    string `json:"date"`
+	PortfolioValueCents     int64  `json:"portfolioValueCents"`
+	BenchmarkValueCents     int64  `json:"benchmarkValueCents"`
+	ContributionsValueCents int64  `json:"contributionsValueCents"`
+}
+
+// Comparison over one series. BenchmarkReturnPercent is the benchmark's growth from StartDate to EndDate.
+type benchmarkSeriesJSON struct {
+	StartDate              string               `json:"startDate,omitempty"`
+	EndDate                string               `json:"endDate,omitempty"`
+	BenchmarkReturnPercent *float64             `json:"benchmarkReturnPercent"`
+	Points                 []benchmarkPointJSON `json:"points"`
+}
+
+// Benchmark comparison response.
+type benchmarkComparisonResponse struct {
+	Symbol          string              `json:"symbol"`
+	LatestPriceDate string              `json:"latestPriceDate,omitempty"`
+	Daily           benchmarkSeriesJSON `json:"daily"`
+	Monthly         benchmarkSeriesJSON `json:"monthly"`
+}
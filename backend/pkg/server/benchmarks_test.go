@@ -0,0 +1,74 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestBenchmarkSeriesDaily(t *testing.T) {
+	price := func(m time.Month, d int, cents int64) database.Price {
+		return database.Price{Symbol: "VTI", Date: database.DateOnly{Time: utcDay(2026, m, d)}, CloseCents: cents}
+	}
+	closes := []database.Price{price(1, 1, 10000), price(1, 2, 11000), price(1, 5, 12100)}
+	points := []SnapshotDataPoint{
+		{Date: "2025-12-31", PortfolioValueCents: 90000},
+		{Date: "2026-01-01", PortfolioValueCents: 100000},
+		{Date: "2026-01-02", PortfolioValueCents: 110000},
+		{Date: "2026-01-03", PortfolioValueCents: 130000},
+		{Date: "2026-01-05", PortfolioValueCents: 150000},
+	}
+	flows := []cashFlow{
+		// On the start date, so already in the starting value.
+		{Date: utcDay(2026, 1, 1), AmountCents: 5000},
+		{Date: utcDay(2026, 1, 3), AmountCents: 20000},
+	}
+
+	got := benchmarkSeries(points, closes, flows, false, utcDay(2026, 1, 5))
+	want := []benchmarkPointJSON{
+		{Date: "2026-01-01", PortfolioValueCents: 100000, BenchmarkValueCents: 100000, ContributionsValueCents: 100000},
+		{Date: "2026-01-02", PortfolioValueCents: 110000, BenchmarkValueCents: 110000, ContributionsValueCents: 110000},
+		{Date: "2026-01-03", PortfolioValueCents: 130000, BenchmarkValueCents: 110000, ContributionsValueCents: 130000},
+		{Date: "2026-01-05", PortfolioValueCents: 150000, BenchmarkValueCents: 121000, ContributionsValueCents: 143000},
+	}
+	if len(got.Points) != len(want) {
+		t.Fatalf("points = %+v, want %+v", got.Points, want)
+	}
+	for i := range want {
+		if got.Points[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, got.Points[i], want[i])
+		}
+	}
+	if got.StartDate != "2026-01-01" || got.EndDate != "2026-01-05" || got.BenchmarkReturnPercent == nil || *got.BenchmarkReturnPercent != 21 {
+		t.Errorf("summary = %s..%s %v, want 2026-01-01..2026-01-05 21%%", got.StartDate, got.EndDate, got.BenchmarkReturnPercent)
+	}
+}
+
+func TestBenchmarkSeriesMonthly(t *testing.T) {
+	closes := []database.Price{
+		{Date: database.DateOnly{Time: utcDay(2026, 1, 30)}, CloseCents: 10000},
+		{Date: database.DateOnly{Time: utcDay(2026, 2, 10)}, CloseCents: 10500},
+	}
+	points := []SnapshotDataPoint{
+		{Date: "2026-01-01", PortfolioValueCents: 100000},
+		{Date: "2026-02-01", PortfolioValueCents: 120000},
+	}
+	flows := []cashFlow{{Date: utcDay(2026, 2, 5), AmountCents: 10000}}
+
+	// January is valued at its last close; February (this month) at today's.
+	got := benchmarkSeries(points, closes, flows, true, utcDay(2026, 2, 10))
+	if len(got.Points) != 2 {
+		t.Fatalf("points = %+v, want 2", got.Points)
+	}
+	if got.Points[1].BenchmarkValueCents != 105000 || got.Points[1].ContributionsValueCents != 115500 {
+		t.Errorf("February = %+v, want benchmark 105000 and contributions 115500", got.Points[1])
+	}
+}
+
+func TestBenchmarkSeriesWithoutCloses(t *testing.T) {
+	got := benchmarkSeries([]SnapshotDataPoint{{Date: "2026-01-01", PortfolioValueCents: 100000}}, nil, nil, false, utcDay(2026, 1, 1))
+	if len(got.Points) != 0 || got.BenchmarkReturnPercent != nil {
+		t.Errorf("series = %+v, want no points", got)
+	}
+}
@@ -0,0 +1,722 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Month layout used by the budget API (YYYY-MM).
+const budgetMonthLayout = "2006-01"
+
+// Registers the budget routes.
+func registerBudgetRoutes(mux *http.ServeMux, deps apiDependencies) {
+	mux.Handle("/api/budget", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleBudget(w, r, deps)
+	})))
+	mux.Handle("/api/budget/history", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetBudgetHistory(w, r, deps)
+	})))
+	mux.Handle("/api/budget/settings", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetBudgetSettings(w, r, deps)
+		case http.MethodPut:
+			handleUpdateBudgetSettings(w, r, deps)
+		default:
+			methodNotAllowed(w, http.MethodGet)
+		}
+	})))
+	mux.Handle("/api/budget/annual", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetAnnualBudget(w, r, deps)
+	})))
+	mux.Handle("/api/budget/sinking-funds", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleListSinkingFunds(w, r, deps)
+		case http.MethodPost:
+			handleSaveSinkingFund(w, r, deps)
+		default:
+			methodNotAllowed(w, http.MethodGet)
+		}
+	})))
+	mux.Handle("/api/budget/sinking-funds/{id}", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			handleSaveSinkingFund(w, r, deps)
+		case http.MethodDelete:
+			handleDeleteSinkingFund(w, r, deps)
+		default:
+			methodNotAllowed(w, http.MethodPut)
+		}
+	})))
+	mux.Handle("/api/budget/sinking-funds/{id}/contributions", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleAddSinkingFundContribution(w, r, deps)
+	})))
+}
+
+// Returns or updates the budget for a month.
+func handleBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		handleGetBudget(w, r, deps)
+	case http.MethodPost, http.MethodPut:
+		handleUpdateBudget(w, r, deps)
+	default:
+		methodNotAllowed(w, http.MethodGet)
+	}
+}
+
+// Returns the allocations in effect for the requested month and spent-by-category for that month.
+func handleGetBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		writeJSONError(w, http.StatusBadRequest, "month required (YYYY-MM)")
+		return
+	}
+	monthStart, err := parseBudgetMonth(month)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "month must be YYYY-MM")
+		return
+	}
+
+	// Loads the budget version in effect for the month.
+	version, err := deps.db.GetBudgetVersionForMonth(r.Context(), monthStart)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Maps the allocations to a map.
+	allocations := map[int64]int64{}
+	var totalCents int64
+	effectiveMonth := ""
+	if version != nil {
+		totalCents = version.TotalCents
+		for k, v := range version.Allocations {
+			allocations[k] = v
+		}
+		effectiveMonth = version.EffectiveMonth.Format(budgetMonthLayout)
+	}
+
+	// Computes the monthly spent by category.
+	categories, err := deps.db.ListCategories(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	categoriesByID := make(map[int64]database.Category, len(categories))
+	for _, category := range categories {
+		categoriesByID[category.ID] = category
+	}
+	spentByID, err := calculateMonthlySpentByCategoryID(r.Context(), deps.db, month, categoriesByID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Computes available, carried-in and carried-out amounts for rollover categories.
+	envelopes, err := calculateBudgetEnvelopes(r.Context(), deps.db, monthStart, categories, allocations, spentByID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Projects month-end spend per category.
+	forecasts, err := calculateBudgetForecasts(r.Context(), deps.db, monthStart, categories, spentByID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Returns the response.
+	resp := budgetResponse{
+		Month:              month,
+		EffectiveMonth:     effectiveMonth,
+		TotalCents:         totalCents,
+		Allocations:        allocations,
+		Spent:              spentByID,
+		Available:          make(map[int64]int64, len(envelopes)),
+		CarriedIn:          make(map[int64]int64, len(envelopes)),
+		CarriedOut:         make(map[int64]int64, len(envelopes)),
+		Projected:          make(map[int64]int64, len(forecasts)),
+		ProjectedLow:       make(map[int64]int64, len(forecasts)),
+		ProjectedHigh:      make(map[int64]int64, len(forecasts)),
+		ProjectedOverUnder: make(map[int64]int64, len(forecasts)),
+	}
+	for key, envelope := range envelopes {
+		resp.Available[key] = envelope.AvailableCents
+		resp.CarriedIn[key] = envelope.CarriedInCents
+		resp.CarriedOut[key] = envelope.CarriedOutCents
+	}
+	// Projections use the same sign as spent (outflows negative); over/under is available minus projected spend.
+	for key, forecast := range forecasts {
+		resp.Projected[key] = -forecast.ProjectedCents
+		resp.ProjectedLow[key] = -forecast.LowCents
+		resp.ProjectedHigh[key] = -forecast.HighCents
+		resp.ProjectedOverUnder[key] = resp.Available[key] - forecast.ProjectedCents
+	}
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("get budget encode: %v", err)
+	}
+}
+
+// Computes the envelope for every expense category in the month, keyed by category ID (like allocations).
+// Rollover categories replay each month from their start month; carryover before the transaction retention window is not tracked.
+func calculateBudgetEnvelopes(ctx context.Context, db *database.Client, monthStart time.Time, categories []database.Category, allocations map[int64]int64, spentByID map[int64]int64) (map[int64]budgetEnvelope, error) {
+	settings, err := db.ListBudgetCategorySettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settingsByID := make(map[int64]database.BudgetCategorySetting, len(settings))
+	hasRollover := false
+	for _, setting := range settings {
+		settingsByID[setting.CategoryID] = setting
+		hasRollover = hasRollover || setting.Rollover
+	}
+
+	// Loads the history only when a category rolls over.
+	var versions []database.BudgetVersion
+	if hasRollover {
+		versions, err = db.ListBudgetVersions(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	categoriesByID := make(map[int64]database.Category, len(categories))
+	for _, category := range categories {
+		categoriesByID[category.ID] = category
+	}
+	cutoff := transactionRetentionCutoff(GetLocalNow())
+	spentByMonth := map[string]map[int64]int64{monthStart.Format(budgetMonthLayout): spentByID}
+
+	envelopes := make(map[int64]budgetEnvelope)
+	for _, category := range categories {
+		if !category.Expense {
+			continue
+		}
+		key := category.ID
+		setting, ok := settingsByID[category.ID]
+		if !ok || !setting.Rollover {
+			envelopes[key] = budgetEnvelope{AvailableCents: allocations[key]}
+			continue
+		}
+
+		// Builds the month-by-month allocation and spend from the start month through the requested month.
+		var months []rolloverMonth
+		for m := rolloverStartMonth(setting, versions, cutoff, monthStart); !m.After(monthStart); m = m.AddDate(0, 1, 0) {
+			monthKey := m.Format(budgetMonthLayout)
+			spent, ok := spentByMonth[monthKey]
+			if !ok {
+				spent, err = calculateMonthlySpentByCategoryID(ctx, db, monthKey, categoriesByID)
+				if err != nil {
+					return nil, err
+				}
+				spentByMonth[monthKey] = spent
+			}
+			months = append(months, rolloverMonth{
+				Month:           m,
+				AllocationCents: budgetAllocationForMonth(versions, m)[key],
+				SpentCents:      spent[category.ID],
+			})
+		}
+		// The requested month uses the allocations already loaded for it.
+		months[len(months)-1].AllocationCents = allocations[key]
+		envelopes[key] = computeRollover(months, setting)
+	}
+	return envelopes, nil
+}
+
+// Returns the first month a rollover category accumulates from: its start month, else the first budget version,
+// never earlier than the retention cutoff or later than the requested month.
+func rolloverStartMonth(setting database.BudgetCategorySetting, versions []database.BudgetVersion, cutoff, monthStart time.Time) time.Time {
+	start := monthStart
+	if setting.StartMonth != nil {
+		start = setting.StartMonth.Time
+	} else if len(versions) > 0 {
+		start = versions[0].EffectiveMonth.Time
+	}
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, monthStart.Location())
+	if start.Before(cutoff) {
+		start = time.Date(cutoff.Year(), cutoff.Month(), 1, 0, 0, 0, 0, monthStart.Location())
+	}
+	if start.After(monthStart) {
+		start = monthStart
+	}
+	return start
+}
+
+// Returns the allocations of the latest version effective on or before the month (versions sorted oldest first).
+func budgetAllocationForMonth(versions []database.BudgetVersion, month time.Time) map[int64]int64 {
+	monthKey := month.Format(budgetMonthLayout)
+	var allocations map[int64]int64
+	for _, version := range versions {
+		if version.EffectiveMonth.Format(budgetMonthLayout) > monthKey {
+			break
+		}
+		allocations = version.Allocations
+	}
+	return allocations
+}
+
+// Replays a rollover category month by month and returns the envelope for the last month.
+// Unspent money carries forward up to the cap; overspending always carries; the reset month starts from zero.
+func computeRollover(months []rolloverMonth, setting database.BudgetCategorySetting) budgetEnvelope {
+	var envelope budgetEnvelope
+	carry := int64(0)
+	for i, month := range months {
+		if i > 0 && setting.ResetMonth != nil && int(month.Month.Month()) == *setting.ResetMonth {
+			carry = 0
+		}
+		envelope.CarriedInCents = carry
+		envelope.AvailableCents = month.AllocationCents + carry
+		envelope.CarriedOutCents = envelope.AvailableCents + month.SpentCents
+		if setting.MaxCarryoverCents != nil && envelope.CarriedOutCents > *setting.MaxCarryoverCents {
+			envelope.CarriedOutCents = *setting.MaxCarryoverCents
+		}
+		carry = envelope.CarriedOutCents
+	}
+	return envelope
+}
+
+// Saves allocations as the budget version effective from the given month (default: the current month).
+func handleUpdateBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Decodes the request body into an updateBudgetRequest.
+	var req updateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	// Validates the allocations against the categories and the total.
+	categories, err := deps.db.ListCategories(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	allocations, problems := validateBudgetAllocations(req, categories)
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(budgetValidationResponse{Error: "invalid budget", Problems: problems})
+		return
+	}
+
+	// Resolves the effective month.
+	now := GetLocalNow()
+	effective := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, GetLocalLocation())
+	if req.EffectiveMonth != "" {
+		parsed, err := parseBudgetMonth(req.EffectiveMonth)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "effectiveMonth must be YYYY-MM")
+			return
+		}
+		effective = parsed
+	}
+
+	// Creates or replaces the version for that month.
+	version := &database.BudgetVersion{
+		EffectiveMonth: database.DateOnly{Time: effective},
+		Allocations:    allocations,
+		TotalCents:     *req.TotalCents,
+		UpdatedAt:      &now,
+	}
+	if err := deps.db.UpsertBudgetVersion(r.Context(), version); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Returns the saved version.
+	err = json.NewEncoder(w).Encode(toBudgetVersionJSON(*version))
+	if err != nil {
+		log.Printf("update budget encode: %v", err)
+	}
+}
+
+// Checks a budget update: keys must be expense category IDs, amounts 0 or more, and the sum must equal totalCents.
+// Returns the allocations for every expense category (missing ones are 0) and every problem found.
+func validateBudgetAllocations(req updateBudgetRequest, categories []database.Category) (map[int64]int64, []budgetProblem) {
+	var problems []budgetProblem
+	categoriesByID := make(map[int64]database.Category, len(categories))
+	allocations := make(map[int64]int64)
+	for _, category := range categories {
+		categoriesByID[category.ID] = category
+		if category.Expense {
+			allocations[category.ID] = 0
+		}
+	}
+
+	if req.TotalCents == nil {
+		problems = append(problems, budgetProblem{Field: "totalCents", Code: "required", Message: "totalCents is required"})
+	} else if *req.TotalCents <= 0 {
+		problems = append(problems, budgetProblem{Field: "totalCents", Code: "invalid", Message: "totalCents must be greater than 0"})
+	}
+
+	// Checks each allocation in key order so problems are stable.
+	keys := make([]string, 0, len(req.Allocations))
+	for key := range req.Allocations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sum int64
+	for _, key := range keys {
+		cents := req.Allocations[key]
+		field := "allocations." + key
+		categoryID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			problems = append(problems, budgetProblem{Field: field, Code: "unknown_category", Message: fmt.Sprintf("%q is not a category ID", key)})
+			continue
+		}
+		category, ok := categoriesByID[categoryID]
+		if !ok {
+			problems = append(problems, budgetProblem{Field: field, CategoryID: &categoryID, Code: "unknown_category", Message: fmt.Sprintf("category %d does not exist", categoryID)})
+			continue
+		}
+		if !category.Expense {
+			problems = append(problems, budgetProblem{Field: field, CategoryID: &categoryID, Code: "not_expense", Message: fmt.Sprintf("%s is not an expense category", category.Name)})
+			continue
+		}
+		if cents < 0 {
+			problems = append(problems, budgetProblem{Field: field, CategoryID: &categoryID, Code: "negative", Message: fmt.Sprintf("%s allocation must be 0 or more", category.Name)})
+			continue
+		}
+		allocations[categoryID] = cents
+		sum += cents
+	}
+
+	if req.TotalCents != nil && *req.TotalCents > 0 && sum != *req.TotalCents {
+		problems = append(problems, budgetProblem{
+			Field:   "allocations",
+			Code:    "sum_mismatch",
+			Message: fmt.Sprintf("allocations sum to %s but totalCents is %s", formatDollars(sum), formatDollars(*req.TotalCents)),
+		})
+	}
+	return allocations, problems
+}
+
+// Returns every budget version, oldest first.
+func handleGetBudgetHistory(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	versions, err := deps.db.ListBudgetVersions(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	output := make([]budgetVersionJSON, len(versions))
+	for i, version := range versions {
+		output[i] = toBudgetVersionJSON(version)
+	}
+	err = json.NewEncoder(w).Encode(budgetHistoryResponse{Versions: output})
+	if err != nil {
+		log.Printf("budget history encode: %v", err)
+	}
+}
+
+// Returns the per-category rollover and alert settings.
+func handleGetBudgetSettings(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	settings, err := deps.db.ListBudgetCategorySettings(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeBudgetSettings(w, settings)
+}
+
+// Updates rollover and alert settings for the given categories; categories not in the request keep their settings,
+// and fields left out of a category's entry keep their stored values.
+func handleUpdateBudgetSettings(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	var req budgetSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	// Validates the settings against the expense categories.
+	categories, err := deps.db.ListCategories(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	expenseIDs := make(map[int64]bool, len(categories))
+	for _, category := range categories {
+		if category.Expense {
+			expenseIDs[category.ID] = true
+		}
+	}
+	existing, err := deps.db.ListBudgetCategorySettings(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	stored := make(map[int64]database.BudgetCategorySetting, len(existing))
+	for _, setting := range existing {
+		stored[setting.CategoryID] = setting
+	}
+	now := GetLocalNow()
+	settings := make([]database.BudgetCategorySetting, len(req.Settings))
+	for i, input := range req.Settings {
+		setting, err := budgetSettingFromJSON(input, stored, expenseIDs)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		setting.UpdatedAt = &now
+		settings[i] = setting
+	}
+
+	if err := deps.db.UpsertBudgetCategorySettings(r.Context(), settings); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	saved, err := deps.db.ListBudgetCategorySettings(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeBudgetSettings(w, saved)
+}
+
+// Writes the settings response.
+func writeBudgetSettings(w http.ResponseWriter, settings []database.BudgetCategorySetting) {
+	output := make([]budgetCategorySettingJSON, len(settings))
+	for i, setting := range settings {
+		output[i] = budgetCategorySettingJSON{
+			CategoryID:        setting.CategoryID,
+			Rollover:          setting.Rollover,
+			MaxCarryoverCents: setting.MaxCarryoverCents,
+			ResetMonth:        setting.ResetMonth,
+			AlertThresholds:   setting.AlertThresholds,
+		}
+		if output[i].AlertThresholds == nil {
+			output[i].AlertThresholds = []int{}
+		}
+		if setting.StartMonth != nil {
+			output[i].StartMonth = setting.StartMonth.Format(budgetMonthLayout)
+		}
+	}
+	err := json.NewEncoder(w).Encode(budgetSettingsPayload{Settings: output})
+	if err != nil {
+		log.Printf("budget settings encode: %v", err)
+	}
+}
+
+// Validates one setting from the API and merges it into the category's stored setting, so only the fields present
+// in the request change (a rollover-only update keeps the alert thresholds, and the reverse).
+func budgetSettingFromJSON(raw json.RawMessage, stored map[int64]database.BudgetCategorySetting, expenseIDs map[int64]bool) (database.BudgetCategorySetting, error) {
+	var input budgetCategorySettingJSON
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return database.BudgetCategorySetting{}, errors.New("invalid setting: " + err.Error())
+	}
+	if err := json.Unmarshal(raw, &present); err != nil {
+		return database.BudgetCategorySetting{}, errors.New("invalid setting: " + err.Error())
+	}
+
+	setting, ok := stored[input.CategoryID]
+	if !ok {
+		setting = database.BudgetCategorySetting{CategoryID: input.CategoryID}
+	}
+	setting.UpdatedAt = nil
+	if setting.AlertThresholds == nil {
+		setting.AlertThresholds = []int{}
+	}
+	if !expenseIDs[input.CategoryID] {
+		return setting, fmt.Errorf("category %d is not an expense category", input.CategoryID)
+	}
+	if _, ok := present["rollover"]; ok {
+		setting.Rollover = input.Rollover
+	}
+	if _, ok := present["maxCarryoverCents"]; ok {
+		if input.MaxCarryoverCents != nil && *input.MaxCarryoverCents < 0 {
+			return setting, fmt.Errorf("category %d: maxCarryoverCents must be 0 or more", input.CategoryID)
+		}
+		setting.MaxCarryoverCents = input.MaxCarryoverCents
+	}
+	if _, ok := present["resetMonth"]; ok {
+		if input.ResetMonth != nil && (*input.ResetMonth < 1 || *input.ResetMonth > 12) {
+			return setting, fmt.Errorf("category %d: resetMonth must be between 1 and 12", input.CategoryID)
+		}
+		setting.ResetMonth = input.ResetMonth
+	}
+	if input.StartMonth != "" {
+		start, err := parseBudgetMonth(input.StartMonth)
+		if err != nil {
+			return setting, fmt.Errorf("category %d: startMonth must be YYYY-MM", input.CategoryID)
+		}
+		setting.StartMonth = &database.DateOnly{Time: start}
+	}
+	if _, ok := present["alertThresholds"]; !ok {
+		return setting, nil
+	}
+
+	// Stores alert thresholds sorted and without duplicates.
+	setting.AlertThresholds = []int{}
+	seen := make(map[int]bool, len(input.AlertThresholds))
+	for _, threshold := range input.AlertThresholds {
+		if threshold < 1 || threshold > maxBudgetAlertThreshold {
+			return setting, fmt.Errorf("category %d: alertThresholds must be between 1 and %d", input.CategoryID, maxBudgetAlertThreshold)
+		}
+		if !seen[threshold] {
+			seen[threshold] = true
+			setting.AlertThresholds = append(setting.AlertThresholds, threshold)
+		}
+	}
+	sort.Ints(setting.AlertThresholds)
+	return setting, nil
+}
+
+// Parses a YYYY-MM month into the first day of that month in local time.
+func parseBudgetMonth(month string) (time.Time, error) {
+	return time.ParseInLocation(budgetMonthLayout, month, GetLocalLocation())
+}
+
+// Converts a DB budget version into its API form.
+func toBudgetVersionJSON(version database.BudgetVersion) budgetVersionJSON {
+	allocations := version.Allocations
+	if allocations == nil {
+		allocations = map[int64]int64{}
+	}
+	output := budgetVersionJSON{
+		EffectiveMonth: version.EffectiveMonth.Format(budgetMonthLayout),
+		TotalCents:     version.TotalCents,
+		Allocations:    allocations,
+	}
+	if version.UpdatedAt != nil {
+		output.UpdatedAt = version.UpdatedAt.Format(time.RFC3339)
+	}
+	return output
+}
+
+// Budget API response. Per-category maps are keyed by category ID.
+type budgetResponse struct {
+	Month          string          `json:"month"`
+	EffectiveMonth string          `json:"effectiveMonth,omitempty"`
+	TotalCents     int64           `json:"totalCents"`
+	Allocations    map[int64]int64 `json:"allocations"`
+	Spent          map[int64]int64 `json:"spent"`
+	Available      map[int64]int64 `json:"available"`
+	CarriedIn      map[int64]int64 `json:"carriedIn"`
+	CarriedOut     map[int64]int64 `json:"carriedOut"`
+	// Projected month-end spend: same sign as spent, so the high band is the most negative.
+	Projected          map[int64]int64 `json:"projectedCents"`
+	ProjectedLow       map[int64]int64 `json:"projectedLowCents"`
+	ProjectedHigh      map[int64]int64 `json:"projectedHighCents"`
+	ProjectedOverUnder map[int64]int64 `json:"projectedOverUnder"`
+}
+
+// Budget update request (allocations keyed by category ID).
+type updateBudgetRequest struct {
+	Allocations    map[string]int64 `json:"allocations"`
+	TotalCents     *int64           `json:"totalCents"`
+	EffectiveMonth string           `json:"effectiveMonth,omitempty"`
+}
+
+// One problem found validating a budget update.
+type budgetProblem struct {
+	Field      string `json:"field"`
+	CategoryID *int64 `json:"categoryId,omitempty"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// Budget validation error response (422).
+type budgetValidationResponse struct {
+	Error    string          `json:"error"`
+	Problems []budgetProblem `json:"problems"`
+}
+
+// Budget version for API.
+type budgetVersionJSON struct {
+	EffectiveMonth string          `json:"effectiveMonth"`
+	TotalCents     int64           `json:"totalCents"`
+	Allocations    map[int64]int64 `json:"allocations"`
+	UpdatedAt      string          `json:"updatedAt,omitempty"`
+}
+
+// Budget history response.
+type budgetHistoryResponse struct {
+	Versions []budgetVersionJSON `json:"versions"`
+}
+
+// Budget for one category in one month: allocation plus carryover in, and what carries to next month.
+type budgetEnvelope struct {
+	CarriedInCents  int64
+	AvailableCents  int64
+	CarriedOutCents int64
+}
+
+// Allocation and spend for one month of a rollover replay.
+type rolloverMonth struct {
+	Month           time.Time
+	AllocationCents int64
+	SpentCents      int64
+}
+
+// Budget category setting for API.
+type budgetCategorySettingJSON struct {
+	CategoryID        int64  `json:"categoryId"`
+	Rollover          bool   `json:"rollover"`
+	MaxCarryoverCents *int64 `json:"maxCarryoverCents"`
+	ResetMonth        *int   `json:"resetMonth"`
+	StartMonth        string `json:"startMonth,omitempty"`
+	AlertThresholds   []int  `json:"alertThresholds"`
+}
+
+// Budget settings response.
+type budgetSettingsPayload struct {
+	Settings []budgetCategorySettingJSON `json:"settings"`
+}
+
+// Budget settings update request. Entries are kept raw so fields left out can be told apart from zero values.
+type budgetSettingsRequest struct {
+	Settings []json.RawMessage `json:"settings"`
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/email"
+)
+
+// Highest alert threshold accepted, as a percent of allocation.
+const maxBudgetAlertThreshold = 1000
+
+// Emails an alert for each category that crossed one of its thresholds for the first time in the month containing date.
+// Returns the number of emails sent. Alerts are recorded before sending and un-recorded if the send fails, so they retry.
+func evaluateBudgetAlerts(ctx context.Context, db *database.Client, date time.Time) (int, error) {
+	userEmail := os.Getenv("ALLOWED_USER_EMAIL")
+	if db == nil || userEmail == "" {
+		return 0, nil
+	}
+
+	// Gets the categories with alert thresholds.
+	settings, err := db.ListBudgetCategorySettings(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var alerting []database.BudgetCategorySetting
+	for _, setting := range settings {
+		if len(setting.AlertThresholds) > 0 {
+			alerting = append(alerting, setting)
+		}
+	}
+	if len(alerting) == 0 {
+		return 0, nil
+	}
+
+	monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, GetLocalLocation())
+	month := monthStart.Format(budgetMonthLayout)
+
+	// Loads the allocations, categories, the month's transactions and the alerts already sent.
+	version, err := db.GetBudgetVersionForMonth(ctx, monthStart)
+	if err != nil {
+		return 0, err
+	}
+	if version == nil {
+		return 0, nil
+	}
+	categories, err := db.ListCategories(ctx)
+	if err != nil {
+		return 0, err
+	}
+	categoriesByID := make(map[int64]database.Category, len(categories))
+	for _, category := range categories {
+		categoriesByID[category.ID] = category
+	}
+	transactions, err := db.ListTransactions(ctx, database.ListTransactionsFilter{Month: month})
+	if err != nil {
+		return 0, err
+	}
+	transactionsByCategory := make(map[int64][]database.Transaction)
+	for _, transaction := range transactions {
+		if transaction.CategoryID != nil {
+			transactionsByCategory[*transaction.CategoryID] = append(transactionsByCategory[*transaction.CategoryID], transaction)
+		}
+	}
+	sentAlerts, err := db.ListBudgetAlertsSent(ctx, monthStart)
+	if err != nil {
+		return 0, err
+	}
+	sentByCategory := make(map[int64]map[int]bool)
+	for _, alert := range sentAlerts {
+		if sentByCategory[alert.CategoryID] == nil {
+			sentByCategory[alert.CategoryID] = make(map[int]bool)
+		}
+		sentByCategory[alert.CategoryID][alert.ThresholdPercent] = true
+	}
+
+	sent := 0
+	var errs []error
+	for _, setting := range alerting {
+		category, ok := categoriesByID[setting.CategoryID]
+		allocationCents := version.Allocations[setting.CategoryID]
+		if !ok || !category.Expense || allocationCents <= 0 {
+			continue
+		}
+		categoryTransactions := transactionsByCategory[setting.CategoryID]
+		spentCents := budgetSpentCents(categoryTransactions)
+		crossed := newlyCrossedThresholds(setting.AlertThresholds, spentCents, allocationCents, sentByCategory[setting.CategoryID])
+		if len(crossed) == 0 {
+			continue
+		}
+
+		// Claims each threshold; another sync may already have sent it.
+		var claimed []int
+		for _, threshold := range crossed {
+			inserted, err := db.InsertBudgetAlertSent(ctx, &database.BudgetAlertSent{
+				Month:            database.DateOnly{Time: monthStart},
+				CategoryID:       setting.CategoryID,
+				ThresholdPercent: threshold,
+				SpentCents:       spentCents,
+				AllocationCents:  allocationCents,
+			})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if inserted {
+				claimed = append(claimed, threshold)
+			}
+		}
+		if len(claimed) == 0 {
+			continue
+		}
+
+		// Sends one email covering every threshold crossed.
+		pushedOver := transactionsPushingOver(categoryTransactions, allocationCents, claimed[0])
+		subject, text := budgetAlertEmail(category.Name, month, allocationCents, spentCents, claimed[len(claimed)-1], pushedOver)
+		err = email.SendText(ctx, userEmail, subject, text)
+		if err != nil {
+			log.Printf("budget alerts: send %s alert: %v", category.Name, err)
+			errs = append(errs, err)
+			for _, threshold := range claimed {
+				if err := db.DeleteBudgetAlertSent(ctx, monthStart, setting.CategoryID, threshold); err != nil {
+					log.Printf("budget alerts: unrecord %s %d%% alert: %v", category.Name, threshold, err)
+				}
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// Returns the category's spending as a positive amount (outflows minus refunds).
+func budgetSpentCents(transactions []database.Transaction) int64 {
+	var spent int64
+	for _, transaction := range transactions {
+		spent -= transaction.AmountCents
+	}
+	return spent
+}
+
+// Reports whether spending has reached the given percent of the allocation.
+func budgetThresholdReached(spentCents, allocationCents int64, thresholdPercent int) bool {
+	return spentCents*100 >= int64(thresholdPercent)*allocationCents
+}
+
+// Returns the thresholds reached but not yet alerted, lowest first.
+func newlyCrossedThresholds(thresholds []int, spentCents, allocationCents int64, alreadySent map[int]bool) []int {
+	var crossed []int
+	for _, threshold := range thresholds {
+		if !alreadySent[threshold] && budgetThresholdReached(spentCents, allocationCents, threshold) {
+			crossed = append(crossed, threshold)
+		}
+	}
+	sort.Ints(crossed)
+	return crossed
+}
+
+// Returns the transactions from the one that first reached the threshold onward, in date order.
+func transactionsPushingOver(transactions []database.Transaction, allocationCents int64, thresholdPercent int) []database.Transaction {
+	ordered := make([]database.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if !ordered[i].Date.Equal(ordered[j].Date.Time) {
+			return ordered[i].Date.Before(ordered[j].Date.Time)
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	var spent int64
+	for i, transaction := range ordered {
+		spent -= transaction.AmountCents
+		if budgetThresholdReached(spent, allocationCents, thresholdPercent) {
+			return ordered[i:]
+		}
+	}
+	return nil
+}
+
+// Builds the alert subject and plain-text body.
+func budgetAlertEmail(categoryName, month string, allocationCents, spentCents int64, thresholdPercent int, pushedOver []database.Transaction) (string, string) {
+	subject := fmt.Sprintf("Portfolio Tracker: %s budget at %d%% for %s", categoryName, thresholdPercent, month)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s has reached %d%% of its %s budget.\n\n", categoryName, thresholdPercent, month)
+	fmt.Fprintf(&body, "Spent: %s of %s (%d%%)\n", formatDollars(spentCents), formatDollars(allocationCents), spentCents*100/allocationCents)
+	fmt.Fprintf(&body, "Remaining: %s\n", formatDollars(allocationCents-spentCents))
+	if len(pushedOver) > 0 {
+		body.WriteString("\nTransactions that pushed it over:\n")
+		for _, transaction := range pushedOver {
+			name := transaction.Name
+			if transaction.MerchantName != nil && *transaction.MerchantName != "" {
+				name = *transaction.MerchantName
+			}
+			fmt.Fprintf(&body, "- %s  %s  %s\n", transaction.Date.Format(dateLayout), name, formatDollars(-transaction.AmountCents))
+		}
+	}
+	return subject, body.String()
+}
+
+// Formats cents as dollars, e.g. -1250 -> "-$12.50".
+func formatDollars(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestNewlyCrossedThresholds(t *testing.T) {
+	tests := []struct {
+		name        string
+		thresholds  []int
+		spentCents  int64
+		alreadySent map[int]bool
+		want        []int
+	}{
+		{name: "below every threshold", thresholds: []int{80, 100}, spentCents: 30000, want: nil},
+		{name: "exactly 80 percent", thresholds: []int{80, 100}, spentCents: 32000, want: []int{80}},
+		{name: "both crossed at once", thresholds: []int{100, 80}, spentCents: 41000, want: []int{80, 100}},
+		{name: "already sent is skipped", thresholds: []int{80, 100}, spentCents: 41000, alreadySent: map[int]bool{80: true}, want: []int{100}},
+		{name: "all already sent", thresholds: []int{80, 100}, spentCents: 41000, alreadySent: map[int]bool{80: true, 100: true}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newlyCrossedThresholds(tt.thresholds, tt.spentCents, 40000, tt.alreadySent)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newlyCrossedThresholds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionsPushingOver(t *testing.T) {
+	day := func(d int) database.DateOnly {
+		return database.DateOnly{Time: time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)}
+	}
+	// Stored newest first, like ListTransactions returns them.
+	transactions := []database.Transaction{
+		{ID: 4, Date: day(20), AmountCents: -5000, Name: "Dinner"},
+		{ID: 3, Date: day(12), AmountCents: -15000, Name: "Groceries"},
+		{ID: 2, Date: day(5), AmountCents: 2000, Name: "Refund"},
+		{ID: 1, Date: day(2), AmountCents: -20000, Name: "Groceries"},
+	}
+
+	got := transactionsPushingOver(transactions, 40000, 80)
+	var ids []int64
+	for _, transaction := range got {
+		ids = append(ids, transaction.ID)
+	}
+	// 200 - 20 = 180, then +150 = 330 >= 320 on the 12th.
+	if !reflect.DeepEqual(ids, []int64{3, 4}) {
+		t.Errorf("transactionsPushingOver() IDs = %v, want [3 4]", ids)
+	}
+
+	if got := transactionsPushingOver(transactions, 40000, 200); got != nil {
+		t.Errorf("transactionsPushingOver() for unreached threshold = %v, want nil", got)
+	}
+}
+
+func TestBudgetAlertEmail(t *testing.T) {
+	merchant := "Whole Foods"
+	pushedOver := []database.Transaction{
+		{Date: database.DateOnly{Time: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)}, AmountCents: -8520, Name: "WHOLEFDS 123", MerchantName: &merchant},
+	}
+
+	subject, body := budgetAlertEmail("Food and Drink", "2026-10", 40000, 41250, 100, pushedOver)
+	if subject != "Portfolio Tracker: Food and Drink budget at 100% for 2026-10" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"Spent: $412.50 of $400.00 (103%)",
+		"Remaining: -$12.50",
+		"- 2026-10-14  Whole Foods  $85.20",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestFormatDollars(t *testing.T) {
+	tests := []struct {
+		cents int64
+		want  string
+	}{
+		{cents: 0, want: "$0.00"},
+		{cents: 5, want: "$0.05"},
+		{cents: 123456, want: "$1234.56"},
+		{cents: -1250, want: "-$12.50"},
+	}
+	for _, tt := range tests {
+		if got := formatDollars(tt.cents); got != tt.want {
+			t.Errorf("formatDollars(%d) = %q, want %q", tt.cents, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Compares year-to-date spend with the year's budget targets, plus sinking funds due that year.
+func handleGetAnnualBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	now := GetLocalNow()
+	year := now.Year()
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil || parsed < 2000 || parsed > 2100 {
+			writeJSONError(w, http.StatusBadRequest, "year must be YYYY")
+			return
+		}
+		year = parsed
+	}
+	throughMonth := annualThroughMonth(year, now)
+
+	versions, err := deps.db.ListBudgetVersions(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	categories, err := deps.db.ListCategories(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Loads spend through the end of throughMonth: summaries before the retention cutoff, live transactions after.
+	var transactions []database.Transaction
+	var summaries []database.MonthlyExpenseSummary
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, GetLocalLocation())
+	if throughMonth > 0 {
+		spendEnd := time.Date(year, time.Month(throughMonth)+1, 1, 0, 0, 0, 0, GetLocalLocation())
+		cutoff := transactionRetentionCutoff(now)
+		if yearStart.Before(cutoff) {
+			summaries, err = deps.db.ListMonthlyExpenseSummaries(r.Context(), yearStart, minTime(cutoff, spendEnd).AddDate(0, 0, -1))
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		if spendEnd.After(cutoff) {
+			transactions, err = deps.db.ListTransactionsBetween(r.Context(), maxTime(yearStart, cutoff), spendEnd)
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
+	spentByID := annualSpentByCategoryID(transactions, summaries, categories)
+
+	resp := annualBudgetResponse{
+		Year:         year,
+		Categories:   annualBudgetCategories(categories, versions, year, throughMonth, spentByID),
+		SinkingFunds: []sinkingFundJSON{},
+	}
+	if throughMonth > 0 {
+		resp.ThroughMonth = time.Date(year, time.Month(throughMonth), 1, 0, 0, 0, 0, time.UTC).Format(budgetMonthLayout)
+	}
+	for _, category := range resp.Categories {
+		resp.TotalAnnualTargetCents += category.AnnualTargetCents
+		resp.TotalYTDTargetCents += category.YTDTargetCents
+		resp.TotalYTDSpentCents += category.YTDSpentCents
+	}
+	resp.TotalRemainingCents = resp.TotalAnnualTargetCents - resp.TotalYTDSpentCents
+
+	// Adds the sinking funds due this year.
+	funds, err := deps.db.ListSinkingFunds(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var dueThisYear []database.SinkingFund
+	for _, fund := range funds {
+		if fund.DueDate.Year() == year {
+			dueThisYear = append(dueThisYear, fund)
+		}
+	}
+	resp.SinkingFunds, err = loadSinkingFundProgress(r.Context(), deps.db, dueThisYear, now)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("annual budget encode: %v", err)
+	}
+}
+
+// Returns the last month (1-12) counted toward year-to-date: December for past years,
+// the current month for this year, and 0 for future years.
+func annualThroughMonth(year int, now time.Time) int {
+	switch {
+	case year < now.Year():
+		return 12
+	case year == now.Year():
+		return int(now.Month())
+	default:
+		return 0
+	}
+}
+
+// Sums expense spend (positive cents) by category from live transactions and monthly summaries.
+// Callers pass summaries and transactions for non-overlapping months.
+func annualSpentByCategoryID(transactions []database.Transaction, summaries []database.MonthlyExpenseSummary, categories []database.Category) map[int64]int64 {
+	expense := make(map[int64]bool, len(categories))
+	for _, category := range categories {
+		expense[category.ID] = category.Expense
+	}
+	spent := make(map[int64]int64)
+	for _, transaction := range transactions {
+		if transaction.CategoryID != nil && expense[*transaction.CategoryID] {
+			spent[*transaction.CategoryID] -= transaction.AmountCents
+		}
+	}
+	for _, summary := range summaries {
+		if expense[summary.CategoryID] {
+			spent[summary.CategoryID] += summary.TotalCents
+		}
+	}
+	return spent
+}
+
+// Builds each expense category's annual target (the twelve monthly allocations in effect that year),
+// its year-to-date target through throughMonth, and its year-to-date spend.
+func annualBudgetCategories(categories []database.Category, versions []database.BudgetVersion, year, throughMonth int, spentByID map[int64]int64) []annualBudgetCategoryJSON {
+	annualTargets := make(map[int64]int64)
+	ytdTargets := make(map[int64]int64)
+	for month := 1; month <= 12; month++ {
+		allocations := budgetAllocationForMonth(versions, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC))
+		for categoryID, cents := range allocations {
+			annualTargets[categoryID] += cents
+			if month <= throughMonth {
+				ytdTargets[categoryID] += cents
+			}
+		}
+	}
+
+	output := []annualBudgetCategoryJSON{}
+	for _, category := range categories {
+		if !category.Expense {
+			continue
+		}
+		row := annualBudgetCategoryJSON{
+			CategoryID:        category.ID,
+			Name:              category.Name,
+			AnnualTargetCents: annualTargets[category.ID],
+			YTDTargetCents:    ytdTargets[category.ID],
+			YTDSpentCents:     spentByID[category.ID],
+		}
+		row.RemainingCents = row.AnnualTargetCents - row.YTDSpentCents
+		row.YTDOverUnderCents = row.YTDTargetCents - row.YTDSpentCents
+		if row.AnnualTargetCents > 0 {
+			row.PercentUsed = math.Round(float64(row.YTDSpentCents)*1000/float64(row.AnnualTargetCents)) / 10
+		}
+		if row.AnnualTargetCents == 0 && row.YTDSpentCents == 0 {
+			continue
+		}
+		output = append(output, row)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].Name < output[j].Name
+	})
+	return output
+}
+
+// Returns the earlier of two times.
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// Returns the later of two times.
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// One category's annual budget progress. Spend is positive cents.
+type annualBudgetCategoryJSON struct {
+	CategoryID        int64   `json:"categoryId"`
+	Name              string  `json:"name"`
+	AnnualTargetCents int64   `json:"annualTargetCents"`
+	YTDTargetCents    int64   `json:"ytdTargetCents"`
+	YTDSpentCents     int64   `json:"ytdSpentCents"`
+	YTDOverUnderCents int64   `json:"ytdOverUnderCents"`
+	RemainingCents    int64   `json:"remainingCents"`
+	PercentUsed       float64 `json:"percentUsed"`
+}
+
+// Annual budget response.
+type annualBudgetResponse struct {
+	Year                   int                        `json:"year"`
+	ThroughMonth           string                     `json:"throughMonth,omitempty"`
+	Categories             []annualBudgetCategoryJSON `json:"categories"`
+	TotalAnnualTargetCents int64                      `json:"totalAnnualTargetCents"`
+	TotalYTDTargetCents    int64                      `json:"totalYtdTargetCents"`
+	TotalYTDSpentCents     int64                      `json:"totalYtdSpentCents"`
+	TotalRemainingCents    int64                      `json:"totalRemainingCents"`
+	SinkingFunds           []sinkingFundJSON          `json:"sinkingFunds"`
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestAnnualThroughMonth(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		year int
+		want int
+	}{
+		{year: 2025, want: 12},
+		{year: 2026, want: 10},
+		{year: 2027, want: 0},
+	}
+	for _, tt := range tests {
+		if got := annualThroughMonth(tt.year, now); got != tt.want {
+			t.Errorf("annualThroughMonth(%d) = %d, want %d", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestAnnualBudgetCategories(t *testing.T) {
+	month := func(y int, m time.Month) database.DateOnly {
+		return database.DateOnly{Time: time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)}
+	}
+	categories := []database.Category{
+		{ID: 1, Name: "Groceries", Expense: true},
+		{ID: 2, Name: "Dining", Expense: true},
+		{ID: 3, Name: "Income", Expense: false},
+		{ID: 4, Name: "Unused", Expense: true},
+	}
+	versions := []database.BudgetVersion{
+		{EffectiveMonth: month(2025, time.December), Allocations: map[int64]int64{1: 40000}},
+		{EffectiveMonth: month(2026, time.July), Allocations: map[int64]int64{1: 50000, 2: 10000}},
+	}
+	spent := map[int64]int64{1: 400000, 2: 50000}
+
+	got := annualBudgetCategories(categories, versions, 2026, 10, spent)
+	want := []annualBudgetCategoryJSON{
+		// Jul-Dec at 100, through October 4 months.
+		{CategoryID: 2, Name: "Dining", AnnualTargetCents: 60000, YTDTargetCents: 40000, YTDSpentCents: 50000, YTDOverUnderCents: -10000, RemainingCents: 10000, PercentUsed: 83.3},
+		// Jan-Jun at 400, Jul-Dec at 500.
+		{CategoryID: 1, Name: "Groceries", AnnualTargetCents: 540000, YTDTargetCents: 440000, YTDSpentCents: 400000, YTDOverUnderCents: 40000, RemainingCents: 140000, PercentUsed: 74.1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("annualBudgetCategories() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("annualBudgetCategories()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnnualSpentByCategoryID(t *testing.T) {
+	groceries, income := int64(1), int64(3)
+	categories := []database.Category{{ID: 1, Expense: true}, {ID: 3, Expense: false}}
+	transactions := []database.Transaction{
+		{AmountCents: -2500, CategoryID: &groceries},
+		{AmountCents: 500, CategoryID: &groceries},
+		{AmountCents: 100000, CategoryID: &income},
+	}
+	summaries := []database.MonthlyExpenseSummary{{CategoryID: groceries, TotalCents: 3000}}
+
+	got := annualSpentByCategoryID(transactions, summaries, categories)
+	if got[groceries] != 5000 || got[income] != 0 {
+		t.Errorf("annualSpentByCategoryID() = %v, want groceries 5000 and no income", got)
+	}
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Months of history used for the day-of-month spending distribution.
+const budgetForecastHistoryMonths = 12
+
+// Months checked for recurring charges; a merchant charged in at least recurringChargeMinMonths of them is recurring.
+const recurringChargeLookbackMonths = 3
+const recurringChargeMinMonths = 2
+
+// Lowest share of a month's spend assumed to have happened by today (keeps early-month projections bounded).
+const minForecastFraction = 0.05
+
+// Projects month-end spend for every expense category, keyed by category ID (like allocations).
+// Past months report actual spend; future months have no forecast.
+func calculateBudgetForecasts(ctx context.Context, db *database.Client, monthStart time.Time, categories []database.Category, spentByID map[int64]int64) (map[int64]budgetForecast, error) {
+	forecasts := make(map[int64]budgetForecast)
+	now := GetLocalNow()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, GetLocalLocation())
+	if monthStart.After(currentMonth) {
+		return forecasts, nil
+	}
+
+	// A finished month's projection is what was spent.
+	if monthStart.Before(currentMonth) {
+		for _, category := range categories {
+			if category.Expense {
+				spent := -spentByID[category.ID]
+				forecasts[category.ID] = budgetForecast{ProjectedCents: spent, LowCents: spent, HighCents: spent}
+			}
+		}
+		return forecasts, nil
+	}
+
+	// Loads the history: live transactions for retained months, summaries for pruned ones.
+	historyStart := monthStart.AddDate(0, -budgetForecastHistoryMonths, 0)
+	transactions, err := db.ListTransactionsBetween(ctx, historyStart, monthStart.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	cutoff := transactionRetentionCutoff(now)
+	var summaries []database.MonthlyExpenseSummary
+	if historyStart.Before(cutoff) {
+		summaries, err = db.ListMonthlyExpenseSummaries(ctx, historyStart, cutoff.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	history := buildSpendHistory(transactions, summaries, categories, cutoff)
+
+	monthKey := monthStart.Format(budgetMonthLayout)
+	daysInMonth := time.Date(monthStart.Year(), monthStart.Month()+1, 0, 0, 0, 0, 0, monthStart.Location()).Day()
+	for _, category := range categories {
+		if !category.Expense {
+			continue
+		}
+		categoryHistory := history[category.ID]
+		if categoryHistory == nil {
+			categoryHistory = &categorySpendHistory{}
+		}
+		recurring := detectRecurringCharges(categoryHistory.transactions, monthStart)
+		var pastMonths [][]int64
+		for key, daily := range categoryHistory.dailyByMonth {
+			if key != monthKey {
+				pastMonths = append(pastMonths, daily)
+			}
+		}
+		forecasts[category.ID] = forecastCategorySpend(budgetForecastInput{
+			Day:                    now.Day(),
+			DaysInMonth:            daysInMonth,
+			SpentCents:             -spentByID[category.ID],
+			RecurringSpentCents:    recurring.SpentCents,
+			RecurringExpectedCents: recurring.ExpectedCents,
+			History:                pastMonths,
+		})
+	}
+	return forecasts, nil
+}
+
+// Groups expense spend by category: per-day spend for each month, plus the live transactions.
+// Months before the retention cutoff come from summaries that stored per-day spend.
+func buildSpendHistory(transactions []database.Transaction, summaries []database.MonthlyExpenseSummary, categories []database.Category, cutoff time.Time) map[int64]*categorySpendHistory {
+	expense := make(map[int64]bool, len(categories))
+	for _, category := range categories {
+		expense[category.ID] = category.Expense
+	}
+	history := make(map[int64]*categorySpendHistory)
+	get := func(categoryID int64) *categorySpendHistory {
+		if history[categoryID] == nil {
+			history[categoryID] = &categorySpendHistory{dailyByMonth: make(map[string][]int64)}
+		}
+		return history[categoryID]
+	}
+
+	for _, transaction := range transactions {
+		if transaction.CategoryID == nil || !expense[*transaction.CategoryID] {
+			continue
+		}
+		categoryHistory := get(*transaction.CategoryID)
+		categoryHistory.transactions = append(categoryHistory.transactions, transaction)
+
+		date := transaction.Date.Time
+		key := date.Format(budgetMonthLayout)
+		if categoryHistory.dailyByMonth[key] == nil {
+			days := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			categoryHistory.dailyByMonth[key] = make([]int64, days)
+		}
+		categoryHistory.dailyByMonth[key][date.Day()-1] -= transaction.AmountCents
+	}
+
+	cutoffKey := cutoff.Format(budgetMonthLayout)
+	for _, summary := range summaries {
+		key := summary.Month.Format(budgetMonthLayout)
+		if len(summary.DailyCents) == 0 || key >= cutoffKey || !expense[summary.CategoryID] {
+			continue
+		}
+		categoryHistory := get(summary.CategoryID)
+		if categoryHistory.dailyByMonth[key] == nil {
+			categoryHistory.dailyByMonth[key] = summary.DailyCents
+		}
+	}
+	return history
+}
+
+// Finds merchants charged in most of the previous months. Returns what they have charged this month
+// and what is still expected from those not yet seen (their most recent monthly amount).
+func detectRecurringCharges(transactions []database.Transaction, monthStart time.Time) recurringCharges {
+	lookbackStart := monthStart.AddDate(0, -recurringChargeLookbackMonths, 0)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	// Sums outflows per merchant per month.
+	byMerchant := make(map[string]map[string]int64)
+	for _, transaction := range transactions {
+		date := transaction.Date.Time
+		if transaction.AmountCents >= 0 || date.Before(lookbackStart) || !date.Before(monthEnd) {
+			continue
+		}
+		key := recurringMerchantKey(transaction)
+		if byMerchant[key] == nil {
+			byMerchant[key] = make(map[string]int64)
+		}
+		byMerchant[key][date.Format(budgetMonthLayout)] -= transaction.AmountCents
+	}
+
+	var charges recurringCharges
+	monthKey := monthStart.Format(budgetMonthLayout)
+	for _, months := range byMerchant {
+		var pastKeys []string
+		for key := range months {
+			if key != monthKey {
+				pastKeys = append(pastKeys, key)
+			}
+		}
+		if len(pastKeys) < recurringChargeMinMonths {
+			continue
+		}
+		if spent, ok := months[monthKey]; ok {
+			charges.SpentCents += spent
+			continue
+		}
+		sort.Strings(pastKeys)
+		charges.ExpectedCents += months[pastKeys[len(pastKeys)-1]]
+	}
+	return charges
+}
+
+// Returns the merchant a transaction is grouped under for recurring detection.
+func recurringMerchantKey(transaction database.Transaction) string {
+	if transaction.MerchantName != nil && *transaction.MerchantName != "" {
+		return strings.ToLower(*transaction.MerchantName)
+	}
+	return strings.ToLower(transaction.Name)
+}
+
+// Projects month-end spend (positive cents) from month-to-date spend, recurring charges still expected,
+// and the share of spend past months had reached by the same day. Without at least two months of history
+// it falls back to a straight-line pace with a +/-50% band on the remaining variable spend.
+// The band spans the past months that had spent the most and least by this day.
+func forecastCategorySpend(input budgetForecastInput) budgetForecast {
+	known := input.SpentCents + input.RecurringExpectedCents
+	if input.Day >= input.DaysInMonth {
+		return budgetForecast{ProjectedCents: known, LowCents: known, HighCents: known}
+	}
+
+	// Share of each past month's spend that had happened by this day, and the spend still to come after it.
+	var fractions []float64
+	var rest []int64
+	for _, daily := range input.History {
+		var total, toDate int64
+		for i, cents := range daily {
+			total += cents
+			if i < input.Day {
+				toDate += cents
+			}
+		}
+		if total > 0 {
+			fractions = append(fractions, float64(toDate)/float64(total))
+			rest = append(rest, total-toDate)
+		}
+	}
+
+	// Nothing spent yet to scale from: use what past months spent after this day.
+	variable := input.SpentCents - input.RecurringSpentCents
+	if variable <= 0 {
+		if len(rest) < 2 {
+			return budgetForecast{ProjectedCents: known, LowCents: known, HighCents: known}
+		}
+		var sum int64
+		lowest, highest := rest[0], rest[0]
+		for _, cents := range rest {
+			sum += cents
+			lowest = min(lowest, cents)
+			highest = max(highest, cents)
+		}
+		return budgetForecast{
+			ProjectedCents: known + sum/int64(len(rest)),
+			LowCents:       known + lowest,
+			HighCents:      known + highest,
+		}
+	}
+
+	// Variable spend still to come if the given share of the month's spend has already happened.
+	remaining := func(fraction float64) int64 {
+		fraction = math.Min(math.Max(fraction, minForecastFraction), 1)
+		return int64(math.Round(float64(variable) * (1 - fraction) / fraction))
+	}
+
+	if len(fractions) < 2 {
+		pace := remaining(float64(input.Day) / float64(input.DaysInMonth))
+		return budgetForecast{
+			ProjectedCents: known + pace,
+			LowCents:       known + pace/2,
+			HighCents:      known + pace*3/2,
+		}
+	}
+
+	sum, lowest, highest := 0.0, fractions[0], fractions[0]
+	for _, fraction := range fractions {
+		sum += fraction
+		lowest = math.Min(lowest, fraction)
+		highest = math.Max(highest, fraction)
+	}
+	return budgetForecast{
+		ProjectedCents: known + remaining(sum/float64(len(fractions))),
+		// A month that front-loads spend less means more still to come.
+		LowCents:  known + remaining(highest),
+		HighCents: known + remaining(lowest),
+	}
+}
+
+// Inputs for one category's month-end projection. Amounts are positive spend in cents.
+type budgetForecastInput struct {
+	Day                    int
+	DaysInMonth            int
+	SpentCents             int64
+	RecurringSpentCents    int64
+	RecurringExpectedCents int64
+	History                [][]int64
+}
+
+// Projected month-end spend (positive cents) with a confidence band.
+type budgetForecast struct {
+	ProjectedCents int64
+	LowCents       int64
+	HighCents      int64
+}
+
+// Recurring charges already posted this month and still expected.
+type recurringCharges struct {
+	SpentCents    int64
+	ExpectedCents int64
+}
+
+// One category's spend history.
+type categorySpendHistory struct {
+	dailyByMonth map[string][]int64
+	transactions []database.Transaction
+}
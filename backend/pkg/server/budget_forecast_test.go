@@ -0,0 +1,130 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestForecastCategorySpend(t *testing.T) {
+	// Evenly spread spend: 100 per day over a 30-day month.
+	even := make([]int64, 30)
+	for i := range even {
+		even[i] = 100
+	}
+	// Front-loaded spend: everything on day 1.
+	frontLoaded := make([]int64, 30)
+	frontLoaded[0] = 3000
+
+	tests := []struct {
+		name  string
+		input budgetForecastInput
+		want  budgetForecast
+	}{
+		{
+			name:  "straight-line pace without history",
+			input: budgetForecastInput{Day: 10, DaysInMonth: 30, SpentCents: 10000},
+			want:  budgetForecast{ProjectedCents: 30000, LowCents: 20000, HighCents: 40000},
+		},
+		{
+			name:  "recurring charges still expected are added",
+			input: budgetForecastInput{Day: 10, DaysInMonth: 30, SpentCents: 10000, RecurringExpectedCents: 1500},
+			want:  budgetForecast{ProjectedCents: 31500, LowCents: 21500, HighCents: 41500},
+		},
+		{
+			name:  "recurring charges already posted are not scaled",
+			input: budgetForecastInput{Day: 10, DaysInMonth: 30, SpentCents: 11500, RecurringSpentCents: 1500},
+			want:  budgetForecast{ProjectedCents: 31500, LowCents: 21500, HighCents: 41500},
+		},
+		{
+			name:  "history distribution and band",
+			input: budgetForecastInput{Day: 15, DaysInMonth: 30, SpentCents: 5000, History: [][]int64{even, frontLoaded}},
+			// Fractions by day 15: 0.5 and 1.0, mean 0.75.
+			want: budgetForecast{ProjectedCents: 6667, LowCents: 5000, HighCents: 10000},
+		},
+		{
+			name:  "nothing spent yet uses what past months spent after today",
+			input: budgetForecastInput{Day: 15, DaysInMonth: 30, History: [][]int64{even, frontLoaded}},
+			want:  budgetForecast{ProjectedCents: 750, LowCents: 0, HighCents: 1500},
+		},
+		{
+			name:  "last day is what was spent",
+			input: budgetForecastInput{Day: 30, DaysInMonth: 30, SpentCents: 5000, History: [][]int64{even, frontLoaded}},
+			want:  budgetForecast{ProjectedCents: 5000, LowCents: 5000, HighCents: 5000},
+		},
+		{
+			name:  "early month is bounded by the minimum fraction",
+			input: budgetForecastInput{Day: 1, DaysInMonth: 31, SpentCents: 1000},
+			want:  budgetForecast{ProjectedCents: 20000, LowCents: 10500, HighCents: 29500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := forecastCategorySpend(tt.input)
+			if got != tt.want {
+				t.Errorf("forecastCategorySpend() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectRecurringCharges(t *testing.T) {
+	date := func(month time.Month, day int) database.DateOnly {
+		return database.DateOnly{Time: time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)}
+	}
+	netflix := "Netflix"
+	transactions := []database.Transaction{
+		// Charged three months running, not yet this month: expected at the latest amount.
+		{Date: date(7, 3), AmountCents: -1549, Name: "NETFLIX.COM", MerchantName: &netflix},
+		{Date: date(8, 3), AmountCents: -1549, Name: "NETFLIX.COM", MerchantName: &netflix},
+		{Date: date(9, 3), AmountCents: -1799, Name: "NETFLIX.COM", MerchantName: &netflix},
+		// Two past months and already charged this month.
+		{Date: date(8, 1), AmountCents: -4000, Name: "Gym"},
+		{Date: date(9, 1), AmountCents: -4000, Name: "gym"},
+		{Date: date(10, 1), AmountCents: -4000, Name: "Gym"},
+		// One-off and refunds are ignored; so is anything before the lookback.
+		{Date: date(9, 20), AmountCents: -9000, Name: "Concert"},
+		{Date: date(9, 21), AmountCents: 9000, Name: "Concert"},
+		{Date: date(5, 20), AmountCents: -2000, Name: "Old"},
+		{Date: date(6, 20), AmountCents: -2000, Name: "Old"},
+	}
+
+	got := detectRecurringCharges(transactions, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	want := recurringCharges{SpentCents: 4000, ExpectedCents: 1799}
+	if got != want {
+		t.Errorf("detectRecurringCharges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildSpendHistory(t *testing.T) {
+	food := int64(2)
+	categories := []database.Category{{ID: 2, Name: "Food", Expense: true}, {ID: 9, Name: "Income"}}
+	income := int64(9)
+	transactions := []database.Transaction{
+		{Date: database.DateOnly{Time: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)}, AmountCents: -500, CategoryID: &food},
+		{Date: database.DateOnly{Time: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)}, AmountCents: -250, CategoryID: &food},
+		{Date: database.DateOnly{Time: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)}, AmountCents: 100000, CategoryID: &income},
+	}
+	summaries := []database.MonthlyExpenseSummary{
+		{Month: database.DateOnly{Time: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)}, CategoryID: 2, DailyCents: []int64{100, 200}},
+		// Summaries without per-day spend are skipped.
+		{Month: database.DateOnly{Time: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)}, CategoryID: 2, TotalCents: 900},
+	}
+
+	history := buildSpendHistory(transactions, summaries, categories, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC))
+	if _, ok := history[income]; ok {
+		t.Error("income category should not have spend history")
+	}
+	february := history[food].dailyByMonth["2026-02"]
+	if len(february) != 28 || february[2] != 750 {
+		t.Errorf("2026-02 daily = %v, want 28 days with 750 on day 3", february)
+	}
+	if got := history[food].dailyByMonth["2025-10"]; len(got) != 2 {
+		t.Errorf("2025-10 daily = %v, want the summary's per-day spend", got)
+	}
+	if _, ok := history[food].dailyByMonth["2025-09"]; ok {
+		t.Error("2025-09 summary without per-day spend should be skipped")
+	}
+}
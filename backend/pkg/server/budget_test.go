@@ -0,0 +1,312 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestParseBudgetMonth(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "2026-03", want: "2026-03-01"},
+		{input: "2026-12", want: "2026-12-01"},
+		{input: "2026-13", wantErr: true},
+		{input: "2026-03-15", wantErr: true},
+		{input: "march", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBudgetMonth(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseBudgetMonth(%q) expected error, got %v", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseBudgetMonth(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got.Format(dateLayout) != tt.want {
+			t.Errorf("parseBudgetMonth(%q) = %s, want %s", tt.input, got.Format(dateLayout), tt.want)
+		}
+	}
+}
+
+func TestToBudgetVersionJSON(t *testing.T) {
+	updated := time.Date(2026, 6, 3, 12, 0, 0, 0, time.UTC)
+	version := database.BudgetVersion{
+		EffectiveMonth: database.DateOnly{Time: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		Allocations:    map[int64]int64{2: 50000},
+		UpdatedAt:      &updated,
+	}
+
+	got := toBudgetVersionJSON(version)
+	if got.EffectiveMonth != "2026-06" {
+		t.Errorf("EffectiveMonth = %q, want 2026-06", got.EffectiveMonth)
+	}
+	if got.Allocations[2] != 50000 {
+		t.Errorf("Allocations[2] = %d, want 50000", got.Allocations[2])
+	}
+	if got.UpdatedAt != "2026-06-03T12:00:00Z" {
+		t.Errorf("UpdatedAt = %q, want 2026-06-03T12:00:00Z", got.UpdatedAt)
+	}
+
+	// A version without allocations still returns an empty map.
+	empty := toBudgetVersionJSON(database.BudgetVersion{EffectiveMonth: version.EffectiveMonth})
+	if empty.Allocations == nil || len(empty.Allocations) != 0 {
+		t.Errorf("Allocations = %v, want empty map", empty.Allocations)
+	}
+	if empty.UpdatedAt != "" {
+		t.Errorf("UpdatedAt = %q, want empty", empty.UpdatedAt)
+	}
+}
+
+func TestComputeRollover(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+	cents := func(v int64) *int64 { return &v }
+	january := 1
+
+	tests := []struct {
+		name    string
+		months  []rolloverMonth
+		setting database.BudgetCategorySetting
+		want    budgetEnvelope
+	}{
+		{
+			name:    "single month has nothing carried in",
+			months:  []rolloverMonth{{Month: month(3), AllocationCents: 10000, SpentCents: -4000}},
+			setting: database.BudgetCategorySetting{Rollover: true},
+			want:    budgetEnvelope{CarriedInCents: 0, AvailableCents: 10000, CarriedOutCents: 6000},
+		},
+		{
+			name: "unspent money accumulates",
+			months: []rolloverMonth{
+				{Month: month(3), AllocationCents: 10000, SpentCents: 0},
+				{Month: month(4), AllocationCents: 10000, SpentCents: -2500},
+				{Month: month(5), AllocationCents: 10000, SpentCents: -30000},
+			},
+			setting: database.BudgetCategorySetting{Rollover: true},
+			want:    budgetEnvelope{CarriedInCents: 17500, AvailableCents: 27500, CarriedOutCents: -2500},
+		},
+		{
+			name: "cap limits positive carryover",
+			months: []rolloverMonth{
+				{Month: month(3), AllocationCents: 10000, SpentCents: 0},
+				{Month: month(4), AllocationCents: 10000, SpentCents: 0},
+			},
+			setting: database.BudgetCategorySetting{Rollover: true, MaxCarryoverCents: cents(5000)},
+			want:    budgetEnvelope{CarriedInCents: 5000, AvailableCents: 15000, CarriedOutCents: 5000},
+		},
+		{
+			name: "overspending carries past the cap",
+			months: []rolloverMonth{
+				{Month: month(3), AllocationCents: 10000, SpentCents: -16000},
+				{Month: month(4), AllocationCents: 10000, SpentCents: 0},
+			},
+			setting: database.BudgetCategorySetting{Rollover: true, MaxCarryoverCents: cents(5000)},
+			want:    budgetEnvelope{CarriedInCents: -6000, AvailableCents: 4000, CarriedOutCents: 4000},
+		},
+		{
+			name: "reset month starts from zero",
+			months: []rolloverMonth{
+				{Month: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), AllocationCents: 10000, SpentCents: 0},
+				{Month: month(1), AllocationCents: 10000, SpentCents: -1000},
+			},
+			setting: database.BudgetCategorySetting{Rollover: true, ResetMonth: &january},
+			want:    budgetEnvelope{CarriedInCents: 0, AvailableCents: 10000, CarriedOutCents: 9000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeRollover(tt.months, tt.setting)
+			if got != tt.want {
+				t.Errorf("computeRollover() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetAllocationForMonth(t *testing.T) {
+	version := func(m time.Month, cents int64) database.BudgetVersion {
+		return database.BudgetVersion{
+			EffectiveMonth: database.DateOnly{Time: time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC)},
+			Allocations:    map[int64]int64{7: cents},
+		}
+	}
+	versions := []database.BudgetVersion{version(3, 10000), version(6, 20000)}
+
+	tests := []struct {
+		month time.Month
+		want  int64
+	}{
+		{month: 2, want: 0},
+		{month: 3, want: 10000},
+		{month: 5, want: 10000},
+		{month: 6, want: 20000},
+		{month: 9, want: 20000},
+	}
+	for _, tt := range tests {
+		got := budgetAllocationForMonth(versions, time.Date(2026, tt.month, 1, 0, 0, 0, 0, time.UTC))[7]
+		if got != tt.want {
+			t.Errorf("budgetAllocationForMonth(%s) = %d, want %d", tt.month, got, tt.want)
+		}
+	}
+}
+
+func TestRolloverStartMonth(t *testing.T) {
+	month := func(y int, m time.Month) time.Time { return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC) }
+	versions := []database.BudgetVersion{{EffectiveMonth: database.DateOnly{Time: month(2026, 3)}}}
+	cutoff := month(2026, 1)
+	target := month(2026, 8)
+
+	// Defaults to the first budget version.
+	if got := rolloverStartMonth(database.BudgetCategorySetting{}, versions, cutoff, target); !got.Equal(month(2026, 3)) {
+		t.Errorf("default start = %v, want 2026-03", got)
+	}
+	// Uses the configured start month.
+	start := database.DateOnly{Time: month(2026, 5)}
+	if got := rolloverStartMonth(database.BudgetCategorySetting{StartMonth: &start}, versions, cutoff, target); !got.Equal(month(2026, 5)) {
+		t.Errorf("configured start = %v, want 2026-05", got)
+	}
+	// Never earlier than the retention cutoff.
+	early := database.DateOnly{Time: month(2025, 6)}
+	if got := rolloverStartMonth(database.BudgetCategorySetting{StartMonth: &early}, versions, cutoff, target); !got.Equal(cutoff) {
+		t.Errorf("early start = %v, want cutoff %v", got, cutoff)
+	}
+	// Never later than the requested month.
+	late := database.DateOnly{Time: month(2026, 10)}
+	if got := rolloverStartMonth(database.BudgetCategorySetting{StartMonth: &late}, versions, cutoff, target); !got.Equal(target) {
+		t.Errorf("late start = %v, want %v", got, target)
+	}
+}
+
+func TestBudgetSettingFromJSON(t *testing.T) {
+	expenseIDs := map[int64]bool{7: true}
+
+	valid, err := budgetSettingFromJSON(json.RawMessage(`{"categoryId":7,"rollover":true,"startMonth":"2026-04","alertThresholds":[100,80,100]}`), nil, expenseIDs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid.StartMonth == nil || valid.StartMonth.Format(dateLayout) != "2026-04-01" {
+		t.Errorf("StartMonth = %v, want 2026-04-01", valid.StartMonth)
+	}
+	if len(valid.AlertThresholds) != 2 || valid.AlertThresholds[0] != 80 || valid.AlertThresholds[1] != 100 {
+		t.Errorf("AlertThresholds = %v, want [80 100]", valid.AlertThresholds)
+	}
+
+	invalid := []string{
+		`{"categoryId":8,"rollover":true}`,
+		`{"categoryId":7,"maxCarryoverCents":-1}`,
+		`{"categoryId":7,"resetMonth":13}`,
+		`{"categoryId":7,"startMonth":"April"}`,
+		`{"categoryId":7,"alertThresholds":[0]}`,
+		`{"categoryId":7,"rollover":"yes"}`,
+	}
+	for _, input := range invalid {
+		if _, err := budgetSettingFromJSON(json.RawMessage(input), nil, expenseIDs); err == nil {
+			t.Errorf("budgetSettingFromJSON(%s) expected error", input)
+		}
+	}
+}
+
+func TestBudgetSettingFromJSONPartialUpdate(t *testing.T) {
+	expenseIDs := map[int64]bool{7: true}
+	carryover, resetMonth := int64(5000), 1
+	start := database.DateOnly{Time: time.Date(2026, time.January, 1, 0, 0, 0, 0, GetLocalLocation())}
+	stored := map[int64]database.BudgetCategorySetting{
+		7: {CategoryID: 7, Rollover: true, MaxCarryoverCents: &carryover, ResetMonth: &resetMonth, StartMonth: &start, AlertThresholds: []int{80, 100}},
+	}
+
+	// A rollover-only update keeps the alert thresholds.
+	setting, err := budgetSettingFromJSON(json.RawMessage(`{"categoryId":7,"rollover":false,"maxCarryoverCents":null}`), stored, expenseIDs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if setting.Rollover || setting.MaxCarryoverCents != nil || setting.ResetMonth == nil || *setting.ResetMonth != 1 || setting.StartMonth == nil {
+		t.Errorf("rollover update = %+v, want rollover off, no cap, reset month and start month kept", setting)
+	}
+	if len(setting.AlertThresholds) != 2 {
+		t.Errorf("AlertThresholds = %v, want the stored [80 100]", setting.AlertThresholds)
+	}
+
+	// An alerts-only update keeps the rollover settings.
+	setting, err = budgetSettingFromJSON(json.RawMessage(`{"categoryId":7,"alertThresholds":[90]}`), stored, expenseIDs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !setting.Rollover || setting.MaxCarryoverCents == nil || *setting.MaxCarryoverCents != 5000 || setting.ResetMonth == nil {
+		t.Errorf("alerts update = %+v, want the stored rollover settings", setting)
+	}
+	if len(setting.AlertThresholds) != 1 || setting.AlertThresholds[0] != 90 {
+		t.Errorf("AlertThresholds = %v, want [90]", setting.AlertThresholds)
+	}
+}
+
+func TestValidateBudgetAllocations(t *testing.T) {
+	categories := []database.Category{
+		{ID: 2, Name: "Food and Drink", Expense: true},
+		{ID: 3, Name: "Travel", Expense: true},
+		{ID: 9, Name: "Income", Expense: false},
+	}
+	total := func(cents int64) *int64 { return &cents }
+
+	t.Run("valid budget fills missing expense categories with 0", func(t *testing.T) {
+		allocations, problems := validateBudgetAllocations(updateBudgetRequest{
+			Allocations: map[string]int64{"2": 50000},
+			TotalCents:  total(50000),
+		}, categories)
+		if len(problems) != 0 {
+			t.Fatalf("unexpected problems: %+v", problems)
+		}
+		if allocations[2] != 50000 || allocations[3] != 0 || len(allocations) != 2 {
+			t.Errorf("allocations = %v, want map[2:50000 3:0]", allocations)
+		}
+	})
+
+	t.Run("every problem is reported", func(t *testing.T) {
+		_, problems := validateBudgetAllocations(updateBudgetRequest{
+			Allocations: map[string]int64{
+				"Food and Drink": 100,
+				"3":              -500,
+				"9":              1000,
+				"42":             1000,
+				"2":              40000,
+			},
+			TotalCents: total(50000),
+		}, categories)
+		var codes []string
+		for _, problem := range problems {
+			codes = append(codes, problem.Field+":"+problem.Code)
+		}
+		want := []string{
+			"allocations.3:negative",
+			"allocations.42:unknown_category",
+			"allocations.9:not_expense",
+			"allocations.Food and Drink:unknown_category",
+			"allocations:sum_mismatch",
+		}
+		if strings.Join(codes, ",") != strings.Join(want, ",") {
+			t.Errorf("problems = %v, want %v", codes, want)
+		}
+	})
+
+	t.Run("total is required and positive", func(t *testing.T) {
+		_, problems := validateBudgetAllocations(updateBudgetRequest{Allocations: map[string]int64{"2": 0}}, categories)
+		if len(problems) != 1 || problems[0].Code != "required" {
+			t.Errorf("problems = %+v, want totalCents required", problems)
+		}
+		_, problems = validateBudgetAllocations(updateBudgetRequest{TotalCents: total(0)}, categories)
+		if len(problems) != 1 || problems[0].Code != "invalid" {
+			t.Errorf("problems = %+v, want totalCents invalid", problems)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Returns each security's holdings summed across accounts from every account's latest daily holdings, with a
+// per-account breakdown and the consolidated daily history. An optional symbol (ticker or CUSIP) limits the
+// response to one security.
+func handleGetConsolidatedHoldings(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	today := calendarDay(GetLocalNow())
+	daily, err := deps.db.ListDailyHoldings(r.Context(), dailyHoldingsWindowStart(today), today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list daily holdings: "+err.Error())
+		return
+	}
+	securities, err := deps.db.ListSecurities(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list securities: "+err.Error())
+		return
+	}
+
+	keys := consolidationKeys(securities)
+	if symbol := strings.TrimSpace(r.URL.Query().Get("symbol")); symbol != "" {
+		key := consolidationKey(keys, symbol)
+		filtered := daily[:0]
+		for _, holding := range daily {
+			if consolidationKey(keys, holding.Symbol) == key {
+				filtered = append(filtered, holding)
+			}
+		}
+		daily = filtered
+	}
+
+	resp := buildConsolidatedHoldings(daily, keys, loadAccountNames(r.Context(), deps.db), today)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("consolidated holdings encode: %v", err)
+	}
+}
+
+// Maps each symbol (and each CUSIP) to the key its holdings are grouped under: the security's CUSIP when
+// Plaid reported one, so different tickers for the same security are grouped together.
+func consolidationKeys(securities []database.Security) map[string]string {
+	keys := make(map[string]string, len(securities)*2)
+	for _, security := range securities {
+		if security.CUSIP == nil || strings.TrimSpace(*security.CUSIP) == "" {
+			continue
+		}
+		cusip := strings.ToUpper(strings.TrimSpace(*security.CUSIP))
+		keys[security.Symbol] = cusip
+		keys[cusip] = cusip
+	}
+	return keys
+}
+
+// Returns the group key for a symbol: its CUSIP if known, else the symbol itself.
+func consolidationKey(keys map[string]string, symbol string) string {
+	if key, ok := keys[symbol]; ok {
+		return key
+	}
+	if key, ok := keys[strings.ToUpper(symbol)]; ok {
+		return key
+	}
+	return symbol
+}
+
+// Groups every account's latest holdings by security and sums each day of the window by security, carrying an
+// account's latest rows forward to days it didn't report (e.g. a monthly Fidelity statement). Cost basis sums
+// the rows that have one; CostBasisPartial is set when some rows don't. A security is shown under the ticker
+// of its most valuable row.
+func buildConsolidatedHoldings(daily []database.DailyHolding, keys map[string]string, accountNames map[string]string, today time.Time) consolidatedHoldingsResponse {
+	resp := consolidatedHoldingsResponse{
+		AsOf:     today.Format(dateLayout),
+		Holdings: []consolidatedHoldingJSON{},
+		History:  []consolidatedHistoryPointJSON{},
+	}
+
+	// Sums the latest rows of every account by security, in account order so the output doesn't depend on
+	// map order.
+	latest := latestHoldingDays(daily)
+	latestAccountIDs := make([]string, 0, len(latest))
+	for accountID := range latest {
+		latestAccountIDs = append(latestAccountIDs, accountID)
+	}
+	sort.Strings(latestAccountIDs)
+	groups := make(map[string]*consolidatedHoldingJSON)
+	var groupKeys []string
+	var totalValue int64
+	for _, accountID := range latestAccountIDs {
+		days := latest[accountID]
+		for _, holding := range days.Current {
+			if holding.Quantity == 0 && holding.ValueCents == 0 {
+				continue
+			}
+			key := consolidationKey(keys, holding.Symbol)
+			group := groups[key]
+			if group == nil {
+				group = &consolidatedHoldingJSON{Accounts: []consolidatedAccountJSON{}}
+				if _, ok := keys[key]; ok {
+					group.CUSIP = key
+				}
+				groups[key] = group
+				groupKeys = append(groupKeys, key)
+			}
+			group.Quantity += holding.Quantity
+			group.ValueCents += holding.ValueCents
+			if holding.CostBasisCents != nil {
+				if group.CostBasisCents == nil {
+					group.CostBasisCents = new(int64)
+				}
+				*group.CostBasisCents += *holding.CostBasisCents
+			} else {
+				group.CostBasisPartial = true
+			}
+			group.Accounts = append(group.Accounts, consolidatedAccountJSON{
+				AccountID:      accountID,
+				AccountName:    accountNames[accountID],
+				Symbol:         holding.Symbol,
+				Date:           days.Date.Format(dateLayout),
+				Quantity:       holding.Quantity,
+				ValueCents:     holding.ValueCents,
+				CostBasisCents: holding.CostBasisCents,
+			})
+			totalValue += holding.ValueCents
+		}
+	}
+
+	displaySymbols := make(map[string]string, len(groups))
+	for _, key := range groupKeys {
+		group := groups[key]
+		sort.Slice(group.Accounts, func(i, j int) bool {
+			if group.Accounts[i].ValueCents != group.Accounts[j].ValueCents {
+				return group.Accounts[i].ValueCents > group.Accounts[j].ValueCents
+			}
+			return group.Accounts[i].AccountID < group.Accounts[j].AccountID
+		})
+		seen := make(map[string]bool)
+		for _, account := range group.Accounts {
+			if !seen[account.Symbol] {
+				seen[account.Symbol] = true
+				group.Symbols = append(group.Symbols, account.Symbol)
+			}
+		}
+		for i := range group.Accounts {
+			if group.ValueCents != 0 {
+				group.Accounts[i].PercentOfHolding = roundPercent(float64(group.Accounts[i].ValueCents) / float64(group.ValueCents) * 100)
+			}
+		}
+		group.Symbol = group.Symbols[0]
+		if group.CostBasisCents == nil {
+			group.CostBasisPartial = false
+		}
+		if totalValue != 0 {
+			group.PercentOfPortfolio = roundPercent(float64(group.ValueCents) / float64(totalValue) * 100)
+		}
+		displaySymbols[key] = group.Symbol
+		resp.Holdings = append(resp.Holdings, *group)
+	}
+	sort.Slice(resp.Holdings, func(i, j int) bool {
+		if resp.Holdings[i].ValueCents != resp.Holdings[j].ValueCents {
+			return resp.Holdings[i].ValueCents > resp.Holdings[j].ValueCents
+		}
+		return resp.Holdings[i].Symbol < resp.Holdings[j].Symbol
+	})
+	resp.TotalValueCents = totalValue
+
+	// Sums each day's rows by security, using each account's latest day on or before it.
+	byAccountDate := make(map[string]map[time.Time][]database.DailyHolding)
+	dateSet := make(map[time.Time]bool)
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		if byAccountDate[holding.AccountID] == nil {
+			byAccountDate[holding.AccountID] = make(map[time.Time][]database.DailyHolding)
+		}
+		byAccountDate[holding.AccountID][date] = append(byAccountDate[holding.AccountID][date], holding)
+		dateSet[date] = true
+	}
+	dates := sortedDates(dateSet)
+	accountIDs := make([]string, 0, len(byAccountDate))
+	accountDates := make(map[string][]time.Time, len(byAccountDate))
+	for accountID, byDate := range byAccountDate {
+		accountIDs = append(accountIDs, accountID)
+		accountDates[accountID] = sortedDates(byDate)
+	}
+	sort.Strings(accountIDs)
+
+	next := make(map[string]int, len(accountIDs))
+	for _, date := range dates {
+		points := make(map[string]*consolidatedHistoryPointJSON)
+		for _, accountID := range accountIDs {
+			// Advances to the account's latest day on or before this one.
+			own := accountDates[accountID]
+			for next[accountID] < len(own) && !own[next[accountID]].After(date) {
+				next[accountID]++
+			}
+			if next[accountID] == 0 {
+				continue
+			}
+			counted := make(map[string]bool)
+			for _, holding := range byAccountDate[accountID][own[next[accountID]-1]] {
+				key := consolidationKey(keys, holding.Symbol)
+				point := points[key]
+				if point == nil {
+					symbol := displaySymbols[key]
+					if symbol == "" {
+						// Sold before the latest day.
+						symbol = holding.Symbol
+					}
+					point = &consolidatedHistoryPointJSON{Date: date.Format(dateLayout), Symbol: symbol}
+					points[key] = point
+				}
+				point.Quantity += holding.Quantity
+				point.ValueCents += holding.ValueCents
+				if !counted[key] {
+					counted[key] = true
+					point.AccountCount++
+				}
+			}
+		}
+		start := len(resp.History)
+		for _, point := range points {
+			resp.History = append(resp.History, *point)
+		}
+		day := resp.History[start:]
+		sort.Slice(day, func(i, j int) bool {
+			return day[i].Symbol < day[j].Symbol
+		})
+	}
+	return resp
+}
+
+// Consolidated holdings response.
+type consolidatedHoldingsResponse struct {
+	AsOf            string                         `json:"asOf"`
+	TotalValueCents int64                          `json:"totalValueCents"`
+	Holdings        []consolidatedHoldingJSON      `json:"holdings"`
+	History         []consolidatedHistoryPointJSON `json:"history"`
+}
+
+// One security summed across accounts for API.
+type consolidatedHoldingJSON struct {
+	Symbol             string                    `json:"symbol"`
+	Symbols            []string                  `json:"symbols"`
+	CUSIP              string                    `json:"cusip,omitempty"`
+	Quantity           float64                   `json:"quantity"`
+	ValueCents         int64                     `json:"valueCents"`
+	CostBasisCents     *int64                    `json:"costBasisCents,omitempty"`
+	CostBasisPartial   bool                      `json:"costBasisPartial,omitempty"`
+	PercentOfPortfolio float64                   `json:"percentOfPortfolio"`
+	Accounts           []consolidatedAccountJSON `json:"accounts"`
+}
+
+// One account's part of a consolidated holding for API.
+type consolidatedAccountJSON struct {
+	AccountID        string  `json:"accountId"`
+	AccountName      string  `json:"accountName,omitempty"`
+	Symbol           string  `json:"symbol"`
+	Date             string  `json:"date"`
+	Quantity         float64 `json:"quantity"`
+	ValueCents       int64   `json:"valueCents"`
+	CostBasisCents   *int64  `json:"costBasisCents,omitempty"`
+	PercentOfHolding float64 `json:"percentOfHolding"`
+}
+
+// One security's total across accounts on one day for API.
+type consolidatedHistoryPointJSON struct {
+	Date         string  `json:"date"`
+	Symbol       string  `json:"symbol"`
+	Quantity     float64 `json:"quantity"`
+	ValueCents   int64   `json:"valueCents"`
+	AccountCount int     `json:"accountCount"`
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestBuildConsolidatedHoldings(t *testing.T) {
+	cusip := "922908769"
+	securities := []database.Security{
+		{Symbol: "VTI", CUSIP: &cusip},
+		{Symbol: "VTSAX"},
+	}
+	cost := func(cents int64) *int64 { return &cents }
+	holding := func(account string, day int, symbol string, quantity float64, cents int64, costBasis *int64) database.DailyHolding {
+		return database.DailyHolding{Date: database.DateOnly{Time: utcDay(2026, 10, day)}, AccountID: account, Symbol: symbol, Quantity: quantity, ValueCents: cents, CostBasisCents: costBasis}
+	}
+	daily := []database.DailyHolding{
+		holding("401k", 16, "VTI", 10, 300000, cost(250000)),
+		holding("401k", 17, "VTI", 10, 310000, cost(250000)),
+		holding("roth", 16, "VTI", 5, 150000, cost(100000)),
+		holding("roth", 17, "VTI", 5, 155000, cost(100000)),
+		// A monthly Fidelity statement lists the same fund by CUSIP, without cost basis. It isn't reported on
+		// the 17th, so its rows carry forward.
+		holding("fidelity", 16, "922908769", 1, 31000, nil),
+		holding("fidelity", 16, "VTSAX", 20, 300000, cost(280000)),
+	}
+
+	got := buildConsolidatedHoldings(daily, consolidationKeys(securities), map[string]string{"401k": "401k Plan"}, utcDay(2026, 10, 18))
+	if len(got.Holdings) != 2 || got.TotalValueCents != 796000 {
+		t.Fatalf("holdings = %+v total %d, want 2 securities worth 796000", got.Holdings, got.TotalValueCents)
+	}
+	vti := got.Holdings[0]
+	if vti.Symbol != "VTI" || vti.CUSIP != cusip || vti.Quantity != 16 || vti.ValueCents != 496000 {
+		t.Errorf("VTI = %+v, want 16 shares worth 496000 under CUSIP %s", vti, cusip)
+	}
+	if vti.CostBasisCents == nil || *vti.CostBasisCents != 350000 || !vti.CostBasisPartial {
+		t.Errorf("VTI cost basis = %v partial %v, want 350000 partial", vti.CostBasisCents, vti.CostBasisPartial)
+	}
+	if len(vti.Accounts) != 3 || vti.Accounts[0].AccountName != "401k Plan" || vti.Accounts[0].PercentOfHolding != 62.5 {
+		t.Errorf("VTI accounts = %+v", vti.Accounts)
+	}
+	if len(vti.Symbols) != 2 || vti.Symbols[1] != cusip || vti.PercentOfPortfolio != 62.31 {
+		t.Errorf("VTI symbols = %v percent %v", vti.Symbols, vti.PercentOfPortfolio)
+	}
+
+	want := []consolidatedHistoryPointJSON{
+		{Date: "2026-10-16", Symbol: "VTI", Quantity: 16, ValueCents: 481000, AccountCount: 3},
+		{Date: "2026-10-16", Symbol: "VTSAX", Quantity: 20, ValueCents: 300000, AccountCount: 1},
+		{Date: "2026-10-17", Symbol: "VTI", Quantity: 16, ValueCents: 496000, AccountCount: 3},
+		{Date: "2026-10-17", Symbol: "VTSAX", Quantity: 20, ValueCents: 300000, AccountCount: 1},
+	}
+	if len(got.History) != len(want) {
+		t.Fatalf("history = %+v, want %+v", got.History, want)
+	}
+	for i := range want {
+		if got.History[i] != want[i] {
+			t.Errorf("history %d = %+v, want %+v", i, got.History[i], want[i])
+		}
+	}
+}
+
+func TestBuildConsolidatedHoldingsCUSIPFirst(t *testing.T) {
+	cusip := "922908769"
+	securities := []database.Security{{Symbol: "VTI", CUSIP: &cusip}}
+	daily := []database.DailyHolding{
+		// Sorts first, so this row starts the group.
+		{Date: database.DateOnly{Time: utcDay(2026, 10, 17)}, AccountID: "a-fidelity", Symbol: cusip, Quantity: 1, ValueCents: 31000},
+		{Date: database.DateOnly{Time: utcDay(2026, 10, 17)}, AccountID: "b-roth", Symbol: "VTI", Quantity: 5, ValueCents: 155000},
+	}
+
+	got := buildConsolidatedHoldings(daily, consolidationKeys(securities), nil, utcDay(2026, 10, 18))
+	if len(got.Holdings) != 1 {
+		t.Fatalf("holdings = %+v, want 1 security", got.Holdings)
+	}
+	vti := got.Holdings[0]
+	if vti.CUSIP != cusip || vti.Symbol != "VTI" || vti.Quantity != 6 {
+		t.Errorf("holding = %+v, want 6 shares of VTI under CUSIP %s", vti, cusip)
+	}
+	if len(vti.Symbols) != 2 || vti.Symbols[0] != "VTI" || vti.Symbols[1] != cusip {
+		t.Errorf("symbols = %v, want [VTI %s]", vti.Symbols, cusip)
+	}
+}
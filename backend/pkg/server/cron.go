@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"math"
 	"net/http"
@@ -15,11 +16,30 @@ import (
 	// "github.com/matthewtzong/portfolio-tracker/backend/pkg/snaptrade"
 )
 
+// Job state name for the daily sync's last processed date.
+const dailySyncJobName = "daily_sync"
+
 // Cron Response.
 type cronSyncResponse struct {
-	PlaidSyncedItems        int  `json:"plaidSyncedItems"`
-	DailySnapshotWritten    bool `json:"dailySnapshotWritten"`
-	MonthlySnapshotsWritten int  `json:"monthlySnapshotsWritten"`
+	PlaidSyncedItems        int                   `json:"plaidSyncedItems"`
+	PlaidFailedItems        int                   `json:"plaidFailedItems"`
+	PlaidItems              []plaidItemSyncResult `json:"plaidItems"`
+	BudgetAlertsSent        int                   `json:"budgetAlertsSent"`
+	InvestmentTransactions  int                   `json:"investmentTransactions"`
+	LiabilitiesRefreshed    int                   `json:"liabilitiesRefreshed"`
+	DailySnapshotWritten    bool                  `json:"dailySnapshotWritten"`
+	MonthlySnapshotsWritten int                   `json:"monthlySnapshotsWritten"`
+	BenchmarkPrices         int                   `json:"benchmarkPrices"`
+	CatchUp                 dailySyncCatchUp      `json:"catchUp"`
+}
+
+// Days the daily sync caught up on. Holdings for missed days are revalued at stored prices; days that still
+// have no snapshot are carried forward and reported as estimated.
+type dailySyncCatchUp struct {
+	From             string   `json:"from,omitempty"`
+	RevaluedHoldings int      `json:"revaluedHoldings"`
+	RevaluedDates    []string `json:"revaluedDates"`
+	EstimatedDates   []string `json:"estimatedDates"`
 }
 
 // Registers cron routes.
@@ -60,13 +80,32 @@ func handleDailySync(w http.ResponseWriter, r *http.Request, deps apiDependencie
 		_ = checkAndUpdateSnaptradeConnectionStatuses(r.Context(), deps.db, deps.snaptradeClient)
 	*/
 
-	// Sync Plaid items with pending transactions.
-	plaidSynced, err := runPlaidSafetySync(r, deps)
+	// Sync Plaid items with pending transactions (failed items are reported, not fatal).
+	plaidResults, err := runPlaidSafetySync(r, deps)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Plaid sync failed: "+err.Error())
 		return
 	}
 
+	// Email budget alerts for thresholds crossed in the month being synced (yesterday's, so the 1st still covers
+	// the month that just ended).
+	budgetAlertsSent, err := evaluateBudgetAlerts(r.Context(), deps.db, targetDate)
+	if err != nil {
+		log.Printf("cron: budget alerts: %v", err)
+	}
+
+	// Sync investment activity (buys, sells, dividends, fees).
+	investmentTransactions, err := syncInvestmentTransactions(r.Context(), deps)
+	if err != nil {
+		log.Printf("cron: sync investment transactions: %v", err)
+	}
+
+	// Refresh credit card and loan details.
+	liabilitiesRefreshed, err := refreshLiabilities(r.Context(), deps)
+	if err != nil {
+		log.Printf("cron: refresh liabilities: %v", err)
+	}
+
 	// Fetch Plaid investment holdings/balances and write snapshots for the target date.
 	dailyWritten, err := writeInvestmentSnapshotsForDate(r, deps, targetDate)
 	if err != nil {
@@ -83,41 +122,149 @@ func handleDailySync(w http.ResponseWriter, r *http.Request, deps apiDependencie
 		}
 	*/
 
+	// Fetch new benchmark closes from the price provider.
+	benchmarkPrices, err := refreshBenchmarkPrices(r.Context(), deps)
+	if err != nil {
+		log.Printf("cron: refresh benchmark prices: %v", err)
+	}
+
+	// Catch up on days missed since the last processed date (weekends, failed runs, outages).
+	var catchUp dailySyncCatchUp
+	if dailyWritten {
+		catchUp, err = catchUpMissedDays(r, deps, calendarDay(targetDate), calendarDay(now))
+		if err != nil {
+			log.Printf("cron: catch up missed days: %v", err)
+		}
+	}
+
 	// Run retention job to prune old data.
 	_ = runRetentionJob(r.Context(), deps, targetDate)
 
 	// Returns the response.
+	plaidSynced := countSyncedItems(plaidResults)
 	resp := cronSyncResponse{
-		PlaidSyncedItems:     plaidSynced,
-		DailySnapshotWritten: dailyWritten,
+		PlaidSyncedItems:       plaidSynced,
+		PlaidFailedItems:       len(plaidResults) - plaidSynced,
+		PlaidItems:             plaidResults,
+		BudgetAlertsSent:       budgetAlertsSent,
+		InvestmentTransactions: investmentTransactions,
+		LiabilitiesRefreshed:   liabilitiesRefreshed,
+		DailySnapshotWritten:   dailyWritten,
+		BenchmarkPrices:        benchmarkPrices,
+		CatchUp:                catchUp,
 	}
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// Revalues every day from the day after the daily sync's last processed date (or the past week, if
+// earlier) through the target date, then records the day before the first still-estimated day (or the target
+// date) as processed, so estimated days are retried while they are in the daily window. Plaid only reports
+// current holdings, so missed days can only be rebuilt from stored prices.
+func catchUpMissedDays(r *http.Request, deps apiDependencies, targetDate, today time.Time) (dailySyncCatchUp, error) {
+	catchUp := dailySyncCatchUp{RevaluedDates: []string{}, EstimatedDates: []string{}}
+	if deps.db == nil {
+		return catchUp, nil
+	}
+
+	state, err := deps.db.GetJobState(r.Context(), dailySyncJobName)
+	if err != nil {
+		return catchUp, err
+	}
+	var lastProcessed *time.Time
+	if state != nil && state.LastProcessedDate != nil {
+		date := calendarDay(state.LastProcessedDate.Time)
+		lastProcessed = &date
+	}
+	from := catchUpStart(lastProcessed, targetDate, today)
+	catchUp.From = from.Format(dateLayout)
+
+	revalued, err := revalueMissedDays(r, deps, from, targetDate)
+	if err != nil {
+		return catchUp, err
+	}
+	catchUp.RevaluedHoldings = revalued.FilledHoldings
+	catchUp.RevaluedDates = revalued.FilledDates
+
+	snapshots, err := deps.db.ListDailySnapshots(r.Context(), from, targetDate)
+	if err != nil {
+		return catchUp, err
+	}
+	catchUp.EstimatedDates = missingSnapshotDates(snapshots, from, targetDate)
+
+	processed := catchUpProcessedThrough(lastProcessed, targetDate, catchUp.EstimatedDates)
+	if lastProcessed != nil && !processed.After(*lastProcessed) {
+		return catchUp, nil
+	}
+	err = deps.db.UpsertJobState(r.Context(), &database.JobState{
+		Name:              dailySyncJobName,
+		LastProcessedDate: &database.DateOnly{Time: processed},
+	})
+	return catchUp, err
+}
+
+// Returns the last date the daily sync can record as processed: the target date when no day was estimated,
+// else the day before the first estimated day. It never moves back before the last processed date.
+func catchUpProcessedThrough(lastProcessed *time.Time, targetDate time.Time, estimatedDates []string) time.Time {
+	processed := targetDate
+	if len(estimatedDates) > 0 {
+		first, err := time.Parse(dateLayout, estimatedDates[0])
+		if err == nil {
+			processed = first.AddDate(0, 0, -1)
+		}
+	}
+	if lastProcessed != nil && processed.Before(*lastProcessed) {
+		processed = *lastProcessed
+	}
+	return processed
+}
+
+// Returns the first day to catch up on: the day after the last processed date, or the past week when that
+// starts earlier or no date was recorded, but never before the daily holdings window.
+func catchUpStart(lastProcessed *time.Time, targetDate, today time.Time) time.Time {
+	from := targetDate.AddDate(0, 0, -revalueCronLookbackDays)
+	if lastProcessed != nil && lastProcessed.AddDate(0, 0, 1).Before(from) {
+		from = lastProcessed.AddDate(0, 0, 1)
+	}
+	if earliest := dailyHoldingsWindowStart(today); from.Before(earliest) {
+		from = earliest
+	}
+	return from
+}
+
+// Returns the dates between from and to (inclusive) without a daily snapshot.
+func missingSnapshotDates(snapshots []database.DailySnapshot, from, to time.Time) []string {
+	have := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		have[snapshot.Date.Format(dateLayout)] = true
+	}
+	missing := []string{}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if !have[date.Format(dateLayout)] {
+			missing = append(missing, date.Format(dateLayout))
+		}
+	}
+	return missing
+}
+
 // Syncs Plaid items with pending transactions using cursor-based sync.
-func runPlaidSafetySync(r *http.Request, deps apiDependencies) (int, error) {
+// Only listing the items can fail; per-item failures are returned in the results.
+func runPlaidSafetySync(r *http.Request, deps apiDependencies) ([]plaidItemSyncResult, error) {
+	results := []plaidItemSyncResult{}
 	if deps.db == nil || deps.plaidClient == nil {
-		return 0, nil
+		return results, nil
 	}
 
 	// Lists the items with pending transactions.
 	items, err := deps.db.ListPlaidItemsWithPendingTransactions(r.Context())
 	if err != nil {
-		return 0, err
+		return results, err
 	}
 
 	// Syncs the transactions for each item.
-	for _, item := range items {
-		if item.AccessToken == "manual" {
-			continue
-		}
-		err = SyncTransactionsForItem(r.Context(), deps.db, deps.plaidClient, &item)
-		if err != nil {
-			return 0, err
-		}
+	if len(items) > 0 {
+		results = syncPlaidItems(r.Context(), deps, items)
 	}
-
-	return len(items), nil
+	return results, nil
 }
 
 // Adds daily Plaid holdings/snapshots for a given date along with end of month monthly snapshots.
@@ -139,97 +286,145 @@ func writeInvestmentSnapshotsForDate(r *http.Request, deps apiDependencies, date
 		if item.AccessToken == "manual" {
 			continue
 		}
-		// Fetch accounts for this item to identify investment accounts.
-		accounts, err := deps.plaidClient.GetAccounts(r.Context(), item.AccessToken)
+		err = writeItemHoldingsForDate(r.Context(), deps, item, today)
 		if err != nil {
-			log.Printf("cron: failed to get accounts for item %s: %v", item.ItemID, err)
+			log.Printf("cron: %v", err)
 			continue
 		}
-
-		investmentAccountIDs := make(map[string]bool)
-		var dbAccounts []database.PlaidAccount
-		for _, acc := range accounts {
-			if acc.Type == "investment" {
-				investmentAccountIDs[acc.AccountID] = true
-			}
-			account := database.PlaidAccount{
-				PlaidItemID:    item.ItemID,
-				AccountID:      acc.AccountID,
-				Name:           acc.Name,
-				Type:           acc.Type,
-				CurrentBalance: acc.Balances.Current,
-			}
-			if acc.Mask != "" {
-				account.Mask = &acc.Mask
-			}
-			if acc.Subtype != "" {
-				account.Subtype = &acc.Subtype
-			}
-			dbAccounts = append(dbAccounts, account)
+		// The nightly fetch covers any refresh queued by a holdings webhook.
+		if item.HoldingsRefreshPending {
+			_ = deps.db.SetItemHoldingsRefreshPending(r.Context(), item.ItemID, false)
 		}
+	}
 
-		err = deps.db.UpsertPlaidAccounts(r.Context(), dbAccounts)
-		if err != nil {
-			log.Printf("cron: failed to upsert plaid accounts for item %s: %v", item.ItemID, err)
-		}
+	// Update snapshots (daily and monthly) for today.
+	err = updatePortfolioSnapshots(r, deps, today)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Refreshes today's holdings for items queued by a holdings webhook.
+func refreshPendingHoldings(r *http.Request, deps apiDependencies) (int, error) {
+	if deps.db == nil || deps.plaidClient == nil {
+		return 0, nil
+	}
 
-		if len(investmentAccountIDs) == 0 {
+	// Lists the items with a queued holdings refresh.
+	items, err := deps.db.ListPlaidItemsWithHoldingsRefreshPending(r.Context())
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	now := GetLocalNow()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, GetLocalLocation())
+
+	// Writes today's holdings for each item and clears the flag on success.
+	refreshed := 0
+	for _, item := range items {
+		if item.AccessToken == "manual" {
 			continue
 		}
-
-		// Fetch holdings for this item.
-		plaidHoldings, securities, err := deps.plaidClient.GetHoldings(r.Context(), item.AccessToken)
+		err = writeItemHoldingsForDate(r.Context(), deps, item, today)
 		if err != nil {
-			log.Printf("cron: failed to get holdings for item %s: %v", item.ItemID, err)
+			log.Printf("holdings refresh: %v", err)
 			continue
 		}
-
-		// Map security IDs to tickers.
-		securityTickerMap := make(map[string]string)
-		for _, sec := range securities {
-			if sec.Ticker != nil {
-				securityTickerMap[sec.SecurityID] = *sec.Ticker
-			} else if sec.Name != nil {
-				securityTickerMap[sec.SecurityID] = *sec.Name
-			} else {
-				securityTickerMap[sec.SecurityID] = "UNKNOWN"
-			}
+		err = deps.db.SetItemHoldingsRefreshPending(r.Context(), item.ItemID, false)
+		if err != nil {
+			log.Printf("holdings refresh: clear flag for item %s: %v", item.ItemID, err)
 		}
+		refreshed++
+	}
+	if refreshed == 0 {
+		return 0, nil
+	}
 
-		// Add holdings for investment accounts.
-		for _, ph := range plaidHoldings {
-			if !investmentAccountIDs[ph.AccountID] {
-				continue
-			}
+	// Rebuilds today's snapshot from the refreshed holdings.
+	err = updatePortfolioSnapshots(r, deps, today)
+	if err != nil {
+		return refreshed, err
+	}
+	return refreshed, nil
+}
 
-			var costBasisCents *int64
-			if ph.CostBasis != nil {
-				cbc := int64(math.Round(*ph.CostBasis * 100))
-				costBasisCents = &cbc
-			}
+// Fetches accounts and holdings for a single Plaid item and writes them for the given date.
+func writeItemHoldingsForDate(ctx context.Context, deps apiDependencies, item database.PlaidItem, today time.Time) error {
+	// Fetch accounts for this item to identify investment accounts.
+	accounts, err := deps.plaidClient.GetAccounts(ctx, item.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to get accounts for item %s: %w", item.ItemID, err)
+	}
 
-			holding := &database.DailyHolding{
-				Date:           database.DateOnly{Time: today},
-				AccountID:      ph.AccountID,
-				Symbol:         securityTickerMap[ph.SecurityID],
-				Quantity:       ph.Quantity,
-				ValueCents:     int64(math.Round(ph.InstitutionValue * 100)),
-				CostBasisCents: costBasisCents,
-			}
-			err = deps.db.UpsertDailyHolding(r.Context(), holding)
-			if err != nil {
-				log.Printf("cron: failed to upsert daily holding for %s: %v", holding.Symbol, err)
-			}
+	investmentAccountIDs := make(map[string]bool)
+	var dbAccounts []database.PlaidAccount
+	for _, acc := range accounts {
+		if acc.Type == "investment" {
+			investmentAccountIDs[acc.AccountID] = true
 		}
+		dbAccounts = append(dbAccounts, plaidAccountToDB(item.ItemID, acc))
 	}
 
-	// Update snapshots (daily and monthly) for today.
-	err = updatePortfolioSnapshots(r, deps, today)
+	err = deps.db.UpsertPlaidAccounts(ctx, dbAccounts)
 	if err != nil {
-		return false, err
+		log.Printf("cron: failed to upsert plaid accounts for item %s: %v", item.ItemID, err)
 	}
 
-	return true, nil
+	if len(investmentAccountIDs) == 0 {
+		return nil
+	}
+
+	// Fetch holdings for this item.
+	plaidHoldings, securities, err := deps.plaidClient.GetHoldings(ctx, item.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to get holdings for item %s: %w", item.ItemID, err)
+	}
+
+	// Map security IDs to tickers.
+	securityTickerMap := make(map[string]string)
+	for _, sec := range securities {
+		securityTickerMap[sec.SecurityID] = securitySymbol(sec)
+	}
+	err = deps.db.UpsertSecurities(ctx, plaidSecuritiesToDB(securities, today))
+	if err != nil {
+		log.Printf("cron: failed to upsert securities for item %s: %v", item.ItemID, err)
+	}
+	err = deps.db.UpsertPrices(ctx, plaidClosePricesToDB(securities, today))
+	if err != nil {
+		log.Printf("cron: failed to upsert close prices for item %s: %v", item.ItemID, err)
+	}
+
+	// Add holdings for investment accounts.
+	for _, ph := range plaidHoldings {
+		if !investmentAccountIDs[ph.AccountID] {
+			continue
+		}
+
+		var costBasisCents *int64
+		if ph.CostBasis != nil {
+			cbc := int64(math.Round(*ph.CostBasis * 100))
+			costBasisCents = &cbc
+		}
+
+		holding := &database.DailyHolding{
+			Date:           database.DateOnly{Time: today},
+			AccountID:      ph.AccountID,
+			Symbol:         securityTickerMap[ph.SecurityID],
+			Quantity:       ph.Quantity,
+			ValueCents:     int64(math.Round(ph.InstitutionValue * 100)),
+			CostBasisCents: costBasisCents,
+		}
+		err = deps.db.UpsertDailyHolding(ctx, holding)
+		if err != nil {
+			log.Printf("cron: failed to upsert daily holding for %s: %v", holding.Symbol, err)
+		}
+	}
+	return nil
 }
 
 /*
@@ -406,9 +601,10 @@ func runRetentionJob(ctx context.Context, deps apiDependencies, date time.Time)
 		}
 	}
 
-	// Prunes daily snapshots and holdings older than 30 days.
-	thirtyDaysAgo := today.AddDate(0, 0, -30)
-	dayBeingDeleted := thirtyDaysAgo
+	// Prunes daily snapshots before the daily holdings window (HOLDINGS_DAILY_RETENTION_DAYS, at least 30 days) and
+	// holdings past the daily tier. The catch-up only rebuilds snapshots inside the same window.
+	snapshotCutoff := dailyHoldingsWindowStart(today)
+	dayBeingDeleted := snapshotCutoff
 	nextDay := dayBeingDeleted.AddDate(0, 0, 1)
 	if nextDay.Month() != dayBeingDeleted.Month() {
 		// Writes the monthly snapshot for the day being deleted.
@@ -429,9 +625,17 @@ func runRetentionJob(ctx context.Context, deps apiDependencies, date time.Time)
 			}
 		}
 	}
-	// Deletes the daily snapshots and holdings older than 30 days.
-	_ = deps.db.DeleteDailySnapshotsOlderThan(ctx, thirtyDaysAgo)
-	_ = deps.db.DeleteDailyHoldingsOlderThan(ctx, thirtyDaysAgo)
+	// Deletes the daily snapshots before the window, and rolls daily holdings past the daily tier into weekly
+	// and monthly rollups before deleting them.
+	_ = deps.db.DeleteDailySnapshotsOlderThan(ctx, snapshotCutoff)
+	if err := rollUpAndPruneHoldings(ctx, deps, today, holdingsRetentionPolicyFromEnv()); err != nil {
+		log.Printf("retention: roll up holdings: %v", err)
+	}
+
+	// Prunes webhook events older than 90 days.
+	if err := deps.db.DeleteWebhookEventsOlderThan(ctx, today.AddDate(0, 0, -90)); err != nil {
+		log.Printf("retention: delete webhook events: %v", err)
+	}
 
 	// Prunes yearly monthly snapshots on December 31.
 	if today.Month() != 12 || today.Day() != 31 {
@@ -474,7 +678,8 @@ func runRetentionJob(ctx context.Context, deps apiDependencies, date time.Time)
 	return nil
 }
 
-// Creates monthly expense summary for a given month from transactions.
+// Creates monthly expense summary for a given month from transactions, plus a transfer summary for
+// non-expense categories (read by sinking funds).
 func createMonthlyExpenseSummary(ctx context.Context, deps apiDependencies, month time.Time) error {
 	// Get transactions for the month.
 	transactions, err := deps.db.ListTransactionsForMonth(ctx, month)
@@ -494,6 +699,10 @@ func createMonthlyExpenseSummary(ctx context.Context, deps apiDependencies, mont
 
 	categoryTotals := make(map[int64]int64)
 	categoryCounts := make(map[int64]int)
+	categoryDaily := make(map[int64][]int64)
+	transferTotals := make(map[int64]int64)
+	transferCounts := make(map[int64]int)
+	daysInMonth := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location()).Day()
 
 	// Loops through the transactions and aggregates the spend by category.
 	for _, transaction := range transactions {
@@ -501,15 +710,27 @@ func createMonthlyExpenseSummary(ctx context.Context, deps apiDependencies, mont
 			continue
 		}
 		category, ok := categoriesByID[*transaction.CategoryID]
-		if !ok || !category.Expense {
+		if !ok {
 			continue
 		}
 		delta := -transaction.AmountCents
 		if delta == 0 {
 			continue
 		}
+		if !category.Expense {
+			transferTotals[category.ID] += delta
+			transferCounts[category.ID]++
+			continue
+		}
 		categoryTotals[category.ID] += delta
 		categoryCounts[category.ID]++
+		// Keeps spend per day of month for budget forecasting.
+		if categoryDaily[category.ID] == nil {
+			categoryDaily[category.ID] = make([]int64, daysInMonth)
+		}
+		if day := transaction.Date.Day(); day >= 1 && day <= daysInMonth {
+			categoryDaily[category.ID][day-1] += delta
+		}
 	}
 
 	// Upsert monthly expense summaries.
@@ -519,9 +740,19 @@ func createMonthlyExpenseSummary(ctx context.Context, deps apiDependencies, mont
 			CategoryID:       categoryID,
 			TotalCents:       total,
 			TransactionCount: categoryCounts[categoryID],
+			DailyCents:       categoryDaily[categoryID],
 		}
 		_ = deps.db.UpsertMonthlyExpenseSummary(ctx, summary)
 	}
+	for categoryID, total := range transferTotals {
+		summary := &database.MonthlyTransferSummary{
+			Month:            database.DateOnly{Time: month},
+			CategoryID:       categoryID,
+			TotalCents:       total,
+			TransactionCount: transferCounts[categoryID],
+		}
+		_ = deps.db.UpsertMonthlyTransferSummary(ctx, summary)
+	}
 	return nil
 }
 
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestCatchUpStart(t *testing.T) {
+	today := utcDay(2026, 10, 18)
+	target := utcDay(2026, 10, 17)
+	date := func(d time.Time) *time.Time { return &d }
+	tests := []struct {
+		name          string
+		lastProcessed *time.Time
+		want          time.Time
+	}{
+		{name: "never run", want: utcDay(2026, 10, 10)},
+		{name: "ran yesterday", lastProcessed: date(utcDay(2026, 10, 16)), want: utcDay(2026, 10, 10)},
+		{name: "two week outage", lastProcessed: date(utcDay(2026, 10, 1)), want: utcDay(2026, 10, 2)},
+		{name: "beyond the daily window", lastProcessed: date(utcDay(2026, 8, 1)), want: utcDay(2026, 9, 18)},
+	}
+	for _, tt := range tests {
+		got := catchUpStart(tt.lastProcessed, target, today)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: catchUpStart = %s, want %s", tt.name, got.Format(dateLayout), tt.want.Format(dateLayout))
+		}
+	}
+}
+
+func TestMissingSnapshotDates(t *testing.T) {
+	snapshots := []database.DailySnapshot{
+		{Date: database.DateOnly{Time: utcDay(2026, 10, 15)}},
+		{Date: database.DateOnly{Time: utcDay(2026, 10, 17)}},
+	}
+	got := missingSnapshotDates(snapshots, utcDay(2026, 10, 14), utcDay(2026, 10, 17))
+	want := []string{"2026-10-14", "2026-10-16"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missing = %v, want %v", got, want)
+	}
+}
+
+func TestCatchUpProcessedThrough(t *testing.T) {
+	target := utcDay(2026, 10, 17)
+	date := func(d time.Time) *time.Time { return &d }
+	tests := []struct {
+		name          string
+		lastProcessed *time.Time
+		estimated     []string
+		want          time.Time
+	}{
+		{name: "nothing estimated", lastProcessed: date(utcDay(2026, 10, 16)), want: target},
+		{name: "stops before the first estimated day", lastProcessed: date(utcDay(2026, 10, 10)), estimated: []string{"2026-10-13", "2026-10-15"}, want: utcDay(2026, 10, 12)},
+		{name: "never moves back", lastProcessed: date(utcDay(2026, 10, 14)), estimated: []string{"2026-10-11"}, want: utcDay(2026, 10, 14)},
+		{name: "never run", estimated: []string{"2026-10-10"}, want: utcDay(2026, 10, 9)},
+	}
+	for _, tt := range tests {
+		got := catchUpProcessedThrough(tt.lastProcessed, target, tt.estimated)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: processed through = %s, want %s", tt.name, got.Format(dateLayout), tt.want.Format(dateLayout))
+		}
+	}
+}
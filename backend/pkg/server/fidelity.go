@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"math"
@@ -41,6 +43,15 @@ func registerFidelityRoutes(mux *http.ServeMux, deps apiDependencies) {
 		}
 		handleFidelityHoldingsUpload(w, r, deps)
 	})))
+
+	// Upload account history CSV (dividends, interest and capital gain distributions).
+	mux.Handle("/api/fidelity/upload-activity", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleFidelityActivityUpload(w, r, deps)
+	})))
 }
 
 // Handles the monthly statement CSV upload.
@@ -196,6 +207,42 @@ func handleFidelityHoldingsUpload(w http.ResponseWriter, r *http.Request, deps a
 	_, _ = w.Write([]byte(`{"message":"Successfully uploaded current holdings"}`))
 }
 
+// Handles the account history CSV upload, importing dividend, interest and capital gain distribution rows.
+func handleFidelityActivityUpload(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Gets file.
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to get file from request")
+		return
+	}
+	defer file.Close()
+
+	// Ensures the Fidelity account exists.
+	err = ensureFidelityAccountExists(r.Context(), deps)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to ensure Fidelity account exists: "+err.Error())
+		return
+	}
+
+	// Parses CSV.
+	transactions, err := parseFidelityActivityCSV(file)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to parse CSV: "+err.Error())
+		return
+	}
+
+	// Upserts by the row-derived ID, so re-uploading overlapping history is safe.
+	err = deps.db.UpsertInvestmentTransactions(r.Context(), transactions)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to save activity: "+err.Error())
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]int{"imported": len(transactions)})
+}
+
 // Parses the Fidelity monthly holdings CSV.
 func parseFidelityMonthlyCSV(r io.Reader) ([]database.DailyHolding, error) {
 	reader := csv.NewReader(r)
@@ -310,6 +357,102 @@ func parseFidelityHoldingsCSV(r io.Reader) ([]database.DailyHolding, error) {
 	return holdings, nil
 }
 
+// Parses the Fidelity account history CSV (Accounts_History.csv) and returns its income rows as investment
+// transactions for the manual account. Buys, sells and transfers are skipped. IDs are derived from each row
+// so a re-upload updates the same transactions.
+func parseFidelityActivityCSV(r io.Reader) ([]database.InvestmentTransaction, error) {
+	reader := csv.NewReader(r)
+	// Allow variable field counts.
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finds the header row below the export's preamble.
+	columns := make(map[string]int)
+	headerRow := -1
+	for i, row := range rows {
+		if len(row) > 0 && strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(row[0], "\ufeff")), "Run Date") {
+			for j, cell := range row {
+				columns[strings.ToLower(strings.TrimSpace(cell))] = j
+			}
+			headerRow = i
+			break
+		}
+	}
+	actionColumn, hasAction := columns["action"]
+	amountColumn, hasAmount := columns["amount ($)"]
+	if headerRow < 0 || !hasAction || !hasAmount {
+		return nil, errors.New("missing Run Date, Action and Amount ($) columns")
+	}
+	symbolColumn, hasSymbol := columns["symbol"]
+
+	var transactions []database.InvestmentTransaction
+	seen := make(map[string]int)
+	for _, row := range rows[headerRow+1:] {
+		if len(row) <= actionColumn || len(row) <= amountColumn {
+			continue
+		}
+		// The disclaimer footer has no date.
+		date, err := time.Parse("01/02/2006", strings.TrimSpace(row[0]))
+		if err != nil {
+			continue
+		}
+		action := strings.TrimSpace(row[actionColumn])
+		subtype := fidelityIncomeSubtype(action)
+		if subtype == "" {
+			continue
+		}
+
+		var symbol *string
+		if hasSymbol && len(row) > symbolColumn {
+			if value := strings.TrimSuffix(strings.TrimSpace(row[symbolColumn]), "**"); value != "" {
+				symbol = &value
+			}
+		}
+		amountCents := parseCents(row[amountColumn])
+
+		// Identical rows on the same day get a sequence number.
+		key := date.Format(dateLayout) + ":" + strings.ToUpper(action) + ":" + strconv.FormatInt(amountCents, 10)
+		seen[key]++
+		if seen[key] > 1 {
+			key += ":" + strconv.Itoa(seen[key])
+		}
+
+		transactions = append(transactions, database.InvestmentTransaction{
+			PlaidInvestmentTransactionID: "fidelity:" + key,
+			AccountID:                    FidelityManualAccountID,
+			Symbol:                       symbol,
+			Date:                         database.DateOnly{Time: date},
+			Name:                         action,
+			Type:                         "cash",
+			Subtype:                      subtype,
+			AmountCents:                  amountCents,
+		})
+	}
+	return transactions, nil
+}
+
+// Maps a Fidelity history action to the Plaid subtype for income, or "" when the row isn't income.
+func fidelityIncomeSubtype(action string) string {
+	upper := strings.ToUpper(action)
+	switch {
+	case strings.HasPrefix(upper, "REINVESTMENT"):
+		return ""
+	case strings.Contains(upper, "LONG-TERM CAP GAIN"):
+		return "long-term capital gain"
+	case strings.Contains(upper, "SHORT-TERM CAP GAIN"):
+		return "short-term capital gain"
+	case strings.HasPrefix(upper, "DIVIDEND RECEIVED"):
+		return "dividend"
+	case strings.HasPrefix(upper, "INTEREST EARNED"):
+		return "interest"
+	}
+	return ""
+}
+
 func parseCents(s string) int64 {
 	s = strings.ReplaceAll(s, "$", "")
 	s = strings.ReplaceAll(s, ",", "")
@@ -375,38 +518,60 @@ func ensureFidelityAccountExists(ctx context.Context, deps apiDependencies) erro
 
 func stringPtr(s string) *string { return &s }
 
-// Updates both the daily and monthly snapshots for a given date.
-func updatePortfolioSnapshots(r *http.Request, deps apiDependencies, date time.Time) error {
-	// Fetch all daily holdings for this date.
-	holdings, err := deps.db.ListDailyHoldings(r.Context(), date, date)
-	if err != nil {
-		return err
+// Sums a day's holdings across ALL accounts (Plaid + Manual), by account and in total. If we failed to get
+// daily holdings for an investment account that existed that day, its current balance is used instead.
+func portfolioDayTotals(holdings []database.DailyHolding, accounts []database.PlaidAccount, date time.Time) (int64, map[string]int64) {
+	totalValueCents, accountTotals := holdingsDayTotals(holdings)
+	for _, account := range accountsWithoutHoldings(accountTotals, accounts, date) {
+		cents := int64(math.Round(account.CurrentBalance * 100))
+		totalValueCents += cents
+		accountTotals[account.AccountID] = cents
 	}
+	return totalValueCents, accountTotals
+}
 
-	// Calculate total value for this date across ALL accounts (Plaid + Manual).
+// Sums a day's holdings by account and in total.
+func holdingsDayTotals(holdings []database.DailyHolding) (int64, map[string]int64) {
 	var totalValueCents int64
 	accountTotals := make(map[string]int64)
 	for _, holding := range holdings {
 		totalValueCents += holding.ValueCents
 		accountTotals[holding.AccountID] += holding.ValueCents
 	}
+	return totalValueCents, accountTotals
+}
 
-	// If we failed to get daily holdings for an account, add the total account balance to the total.
-	allAccounts, err := deps.db.ListPlaidAccounts(r.Context())
-	if err == nil {
-		for _, account := range allAccounts {
-			if isPlaidInvestment(account.Type) {
-				_, exists := accountTotals[account.AccountID]
-				if !exists {
-					cents := int64(math.Round(account.CurrentBalance * 100))
-					totalValueCents += cents
-					accountTotals[account.AccountID] = cents
-				}
-			}
+// Returns the investment accounts that existed on date but have no entry in accountTotals.
+func accountsWithoutHoldings(accountTotals map[string]int64, accounts []database.PlaidAccount, date time.Time) []database.PlaidAccount {
+	var missing []database.PlaidAccount
+	for _, account := range accounts {
+		if !isPlaidInvestment(account.Type) {
+			continue
+		}
+		if account.CreatedAt != nil && !account.CreatedAt.Before(date.AddDate(0, 0, 1)) {
+			continue
 		}
-	} else {
+		if _, exists := accountTotals[account.AccountID]; !exists {
+			missing = append(missing, account)
+		}
+	}
+	return missing
+}
+
+// Updates both the daily and monthly snapshots for a given date.
+func updatePortfolioSnapshots(r *http.Request, deps apiDependencies, date time.Time) error {
+	// Fetch all daily holdings for this date.
+	holdings, err := deps.db.ListDailyHoldings(r.Context(), date, date)
+	if err != nil {
+		return err
+	}
+
+	// Calculate total value for this date across ALL accounts (Plaid + Manual).
+	allAccounts, err := deps.db.ListPlaidAccounts(r.Context())
+	if err != nil {
 		log.Printf("fidelity: failed to list plaid accounts for gap-filling: %v", err)
 	}
+	totalValueCents, accountTotals := portfolioDayTotals(holdings, allAccounts, date)
 
 	// Upsert daily snapshot.
 	snapshot := &database.DailySnapshot{
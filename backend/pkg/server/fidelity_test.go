@@ -101,3 +101,40 @@ func TestParseFidelityHoldingsCSV(t *testing.T) {
 		t.Errorf("expected 100000 cents for SPAXX, got %d", holdings[1].ValueCents)
 	}
 }
+
+func TestParseFidelityActivityCSV(t *testing.T) {
+	// Fidelity account history export: preamble, header, rows, then a disclaimer footer.
+	csvData := `
+
+Run Date,Action,Symbol,Description,Type,Quantity,Price ($),Commission ($),Fees ($),Accrued Interest ($),Amount ($),Settlement Date
+03/31/2026,"DIVIDEND RECEIVED VANGUARD TOTAL STK MKT ETF (VTI) (Cash)", VTI,VANGUARD TOTAL STK MKT ETF,Cash,,,,,,92.50,
+03/31/2026,"REINVESTMENT VANGUARD TOTAL STK MKT ETF (VTI) (Cash)", VTI,VANGUARD TOTAL STK MKT ETF,Cash,0.3,308.33,,,,-92.50,
+03/31/2026,"INTEREST EARNED FDIC INSURED DEPOSIT (QPIQQ) (Cash)", ,FDIC INSURED DEPOSIT,Cash,,,,,,1.25,
+03/31/2026,"INTEREST EARNED FDIC INSURED DEPOSIT (QPIQQ) (Cash)", ,FDIC INSURED DEPOSIT,Cash,,,,,,1.25,
+12/20/2025,"LONG-TERM CAP GAIN VANGUARD TOTAL STK MKT ETF (VTI) (Cash)", VTI,VANGUARD TOTAL STK MKT ETF,Cash,,,,,,10.00,
+03/02/2026,"YOU BOUGHT VANGUARD TOTAL STK MKT ETF (VTI) (Cash)", VTI,VANGUARD TOTAL STK MKT ETF,Cash,1,300,,,,-300.00,03/04/2026
+
+"The data and information in this spreadsheet is provided to you solely for your use."
+`
+	transactions, err := parseFidelityActivityCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if len(transactions) != 4 {
+		t.Fatalf("expected 4 income rows, got %d", len(transactions))
+	}
+
+	if transactions[0].Subtype != "dividend" || transactions[0].Symbol == nil || *transactions[0].Symbol != "VTI" || transactions[0].AmountCents != 9250 {
+		t.Errorf("dividend = %+v, want VTI dividend of 9250", transactions[0])
+	}
+	if transactions[1].Subtype != "interest" || transactions[1].Symbol != nil {
+		t.Errorf("interest = %+v, want interest with no symbol", transactions[1])
+	}
+	// Identical rows keep distinct IDs.
+	if transactions[1].PlaidInvestmentTransactionID == transactions[2].PlaidInvestmentTransactionID {
+		t.Errorf("duplicate IDs %s", transactions[1].PlaidInvestmentTransactionID)
+	}
+	if transactions[3].Subtype != "long-term capital gain" || transactions[3].AccountID != FidelityManualAccountID {
+		t.Errorf("capital gain = %+v", transactions[3])
+	}
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Share quantities closer than this are treated as equal when matching lots.
+const lotQuantityTolerance = 1e-6
+
+// Returns unrealized gain/loss per holding, per account and overall from the latest daily holdings,
+// with short/long-term splits where lot dates are known and the change since each account's previous day.
+func handleGetPortfolioGains(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	now := GetLocalNow()
+	today := calendarDay(now)
+	daily, err := deps.db.ListDailyHoldings(r.Context(), dailyHoldingsWindowStart(today), today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list daily holdings: "+err.Error())
+		return
+	}
+	ledger, err := loadTaxLedger(r.Context(), deps.db)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to build tax lots: "+err.Error())
+		return
+	}
+	lotsByHolding := make(map[holdingKey][]taxLot)
+	for _, lot := range ledger.Open {
+		key := holdingKey{AccountID: lot.AccountID, Symbol: lot.Symbol}
+		lotsByHolding[key] = append(lotsByHolding[key], lot)
+	}
+	accountNames := loadAccountNames(r.Context(), deps.db)
+
+	// Builds each holding's gain, grouped by account.
+	resp := portfolioGainsResponse{AsOf: today.Format(dateLayout), Accounts: []accountGainsJSON{}, Holdings: []holdingGainJSON{}}
+	days := latestHoldingDays(daily)
+	accountIDs := make([]string, 0, len(days))
+	for accountID := range days {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+	for _, accountID := range accountIDs {
+		accountDays := days[accountID]
+		previousBySymbol := make(map[string]database.DailyHolding, len(accountDays.Previous))
+		for _, holding := range accountDays.Previous {
+			previousBySymbol[holding.Symbol] = holding
+		}
+
+		var accountHoldings []holdingGainJSON
+		for _, holding := range accountDays.Current {
+			if holding.Quantity == 0 && holding.ValueCents == 0 {
+				continue
+			}
+			var previous *database.DailyHolding
+			if prior, ok := previousBySymbol[holding.Symbol]; ok {
+				previous = &prior
+			}
+			lots := lotsByHolding[holdingKey{AccountID: accountID, Symbol: holding.Symbol}]
+			gain := holdingGain(holding, previous, lots, today)
+			gain.AccountName = accountNames[accountID]
+			accountHoldings = append(accountHoldings, gain)
+		}
+		if len(accountHoldings) == 0 {
+			continue
+		}
+		sort.Slice(accountHoldings, func(i, j int) bool {
+			return accountHoldings[i].ValueCents > accountHoldings[j].ValueCents
+		})
+
+		account := accountGainsJSON{
+			AccountID:        accountID,
+			AccountName:      accountNames[accountID],
+			Date:             accountDays.Date.Format(dateLayout),
+			gainsSummaryJSON: summarizeGains(accountHoldings),
+		}
+		if !accountDays.PreviousDate.IsZero() {
+			account.PreviousDate = accountDays.PreviousDate.Format(dateLayout)
+		}
+		resp.Accounts = append(resp.Accounts, account)
+		resp.Holdings = append(resp.Holdings, accountHoldings...)
+	}
+	resp.Total = summarizeGains(resp.Holdings)
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("portfolio gains encode: %v", err)
+	}
+}
+
+// Groups daily holdings by account and returns each account's latest day and the day before it that has rows.
+func latestHoldingDays(daily []database.DailyHolding) map[string]accountHoldingDays {
+	byAccountDate := make(map[string]map[time.Time][]database.DailyHolding)
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		if byAccountDate[holding.AccountID] == nil {
+			byAccountDate[holding.AccountID] = make(map[time.Time][]database.DailyHolding)
+		}
+		byAccountDate[holding.AccountID][date] = append(byAccountDate[holding.AccountID][date], holding)
+	}
+
+	days := make(map[string]accountHoldingDays, len(byAccountDate))
+	for accountID, byDate := range byAccountDate {
+		var latest, previous time.Time
+		for date := range byDate {
+			if date.After(latest) {
+				latest, previous = date, latest
+			} else if date.After(previous) {
+				previous = date
+			}
+		}
+		days[accountID] = accountHoldingDays{
+			Date:         latest,
+			Current:      byDate[latest],
+			PreviousDate: previous,
+			Previous:     byDate[previous],
+		}
+	}
+	return days
+}
+
+// Computes a holding's unrealized gain, its short/long-term split when the open lots account for the
+// whole position, and the change from the previous day's row.
+func holdingGain(holding database.DailyHolding, previous *database.DailyHolding, lots []taxLot, today time.Time) holdingGainJSON {
+	gain := holdingGainJSON{
+		AccountID:      holding.AccountID,
+		Symbol:         holding.Symbol,
+		Quantity:       holding.Quantity,
+		ValueCents:     holding.ValueCents,
+		CostBasisCents: holding.CostBasisCents,
+		Term:           "unknown",
+	}
+	if holding.CostBasisCents != nil {
+		unrealized := holding.ValueCents - *holding.CostBasisCents
+		gain.UnrealizedGainCents = &unrealized
+		gain.UnrealizedGainPercent = percentOf(unrealized, *holding.CostBasisCents)
+	}
+
+	// Splits by holding period only when the lots match the position held.
+	var lotQuantity float64
+	for _, lot := range lots {
+		lotQuantity += lot.Quantity
+	}
+	if len(lots) > 0 && holding.Quantity > 0 && math.Abs(lotQuantity-holding.Quantity) <= lotQuantityTolerance*math.Max(1, holding.Quantity) {
+		var shortTerm, longTerm int64
+		var hasShort, hasLong bool
+		for _, lot := range lots {
+			lotValue := int64(math.Round(float64(holding.ValueCents) * lot.Quantity / holding.Quantity))
+			if isLongTerm(lot.AcquiredDate, today) {
+				longTerm += lotValue - lot.CostCents
+				hasLong = true
+			} else {
+				shortTerm += lotValue - lot.CostCents
+				hasShort = true
+			}
+		}
+		gain.ShortTermGainCents = &shortTerm
+		gain.LongTermGainCents = &longTerm
+		switch {
+		case hasShort && hasLong:
+			gain.Term = "mixed"
+		case hasLong:
+			gain.Term = "long"
+		default:
+			gain.Term = "short"
+		}
+	}
+
+	if previous != nil {
+		change := holding.ValueCents - previous.ValueCents
+		gain.PreviousValueCents = &previous.ValueCents
+		gain.DayChangeCents = &change
+		gain.DayChangePercent = percentOf(change, previous.ValueCents)
+	}
+	return gain
+}
+
+// Reports whether a lot acquired on the date has been held more than a year.
+func isLongTerm(acquired, today time.Time) bool {
+	return today.After(acquired.AddDate(1, 0, 0))
+}
+
+// Adds up holdings. Cost basis and gains only count holdings with a known cost basis; gains without a
+// holding-period split are reported as unclassified.
+func summarizeGains(holdings []holdingGainJSON) gainsSummaryJSON {
+	var summary gainsSummaryJSON
+	var previousValue int64
+	for _, holding := range holdings {
+		summary.ValueCents += holding.ValueCents
+		if holding.CostBasisCents != nil && holding.UnrealizedGainCents != nil {
+			summary.CostBasisCents += *holding.CostBasisCents
+			summary.UnrealizedGainCents += *holding.UnrealizedGainCents
+			if holding.ShortTermGainCents != nil && holding.LongTermGainCents != nil {
+				// Lot costs can differ from the broker's basis; the difference stays unclassified.
+				summary.ShortTermGainCents += *holding.ShortTermGainCents
+				summary.LongTermGainCents += *holding.LongTermGainCents
+			}
+		}
+		if holding.DayChangeCents != nil {
+			summary.DayChangeCents += *holding.DayChangeCents
+			previousValue += *holding.PreviousValueCents
+		}
+	}
+	summary.UnclassifiedGainCents = summary.UnrealizedGainCents - summary.ShortTermGainCents - summary.LongTermGainCents
+	summary.UnrealizedGainPercent = percentOf(summary.UnrealizedGainCents, summary.CostBasisCents)
+	summary.DayChangePercent = percentOf(summary.DayChangeCents, previousValue)
+	return summary
+}
+
+// Returns part as a percentage of whole, rounded to two decimals, or nil when whole is not positive.
+func percentOf(part, whole int64) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+	percent := math.Round(float64(part)*10000/float64(whole)) / 100
+	return &percent
+}
+
+// One account's latest and previous days of holdings.
+type accountHoldingDays struct {
+	Date         time.Time
+	Current      []database.DailyHolding
+	PreviousDate time.Time
+	Previous     []database.DailyHolding
+}
+
+// One holding's gains for API.
+type holdingGainJSON struct {
+	AccountID             string   `json:"accountId"`
+	AccountName           string   `json:"accountName,omitempty"`
+	Symbol                string   `json:"symbol"`
+	Quantity              float64  `json:"quantity"`
+	ValueCents            int64    `json:"valueCents"`
+	CostBasisCents        *int64   `json:"costBasisCents"`
+	UnrealizedGainCents   *int64   `json:"unrealizedGainCents"`
+	UnrealizedGainPercent *float64 `json:"unrealizedGainPercent"`
+	Term                  string   `json:"term"`
+	ShortTermGainCents    *int64   `json:"shortTermGainCents,omitempty"`
+	LongTermGainCents     *int64   `json:"longTermGainCents,omitempty"`
+	PreviousValueCents    *int64   `json:"previousValueCents,omitempty"`
+	DayChangeCents        *int64   `json:"dayChangeCents,omitempty"`
+	DayChangePercent      *float64 `json:"dayChangePercent,omitempty"`
+}
+
+// Gains added up over several holdings.
+type gainsSummaryJSON struct {
+	ValueCents            int64    `json:"valueCents"`
+	CostBasisCents        int64    `json:"costBasisCents"`
+	UnrealizedGainCents   int64    `json:"unrealizedGainCents"`
+	UnrealizedGainPercent *float64 `json:"unrealizedGainPercent"`
+	ShortTermGainCents    int64    `json:"shortTermGainCents"`
+	LongTermGainCents     int64    `json:"longTermGainCents"`
+	UnclassifiedGainCents int64    `json:"unclassifiedGainCents"`
+	DayChangeCents        int64    `json:"dayChangeCents"`
+	DayChangePercent      *float64 `json:"dayChangePercent"`
+}
+
+// One account's gains for API.
+type accountGainsJSON struct {
+	AccountID    string `json:"accountId"`
+	AccountName  string `json:"accountName,omitempty"`
+	Date         string `json:"date"`
+	PreviousDate string `json:"previousDate,omitempty"`
+	gainsSummaryJSON
+}
+
+// Portfolio gains response.
+type portfolioGainsResponse struct {
+	AsOf     string             `json:"asOf"`
+	Total    gainsSummaryJSON   `json:"total"`
+	Accounts []accountGainsJSON `json:"accounts"`
+	Holdings []holdingGainJSON  `json:"holdings"`
+}
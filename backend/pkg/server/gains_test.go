@@ -0,0 +1,70 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestHoldingGain(t *testing.T) {
+	today := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	costBasis := int64(175000)
+	holding := database.DailyHolding{AccountID: "a", Symbol: "VTI", Quantity: 10, ValueCents: 300000, CostBasisCents: &costBasis}
+	previous := database.DailyHolding{AccountID: "a", Symbol: "VTI", Quantity: 10, ValueCents: 296000}
+	lots := []taxLot{
+		{AcquiredDate: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Quantity: 5, CostCents: 75000},
+		{AcquiredDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Quantity: 5, CostCents: 100000},
+	}
+
+	got := holdingGain(holding, &previous, lots, today)
+	if got.UnrealizedGainCents == nil || *got.UnrealizedGainCents != 125000 || *got.UnrealizedGainPercent != 71.43 {
+		t.Errorf("unrealized = %v / %v, want 125000 / 71.43", got.UnrealizedGainCents, got.UnrealizedGainPercent)
+	}
+	if got.Term != "mixed" || *got.LongTermGainCents != 75000 || *got.ShortTermGainCents != 50000 {
+		t.Errorf("term split = %s long %v short %v, want mixed 75000 50000", got.Term, *got.LongTermGainCents, *got.ShortTermGainCents)
+	}
+	if *got.DayChangeCents != 4000 || *got.DayChangePercent != 1.35 {
+		t.Errorf("day change = %v / %v, want 4000 / 1.35", *got.DayChangeCents, *got.DayChangePercent)
+	}
+
+	// Lots that do not cover the position leave the term unknown.
+	got = holdingGain(holding, nil, lots[:1], today)
+	if got.Term != "unknown" || got.ShortTermGainCents != nil || got.DayChangeCents != nil {
+		t.Errorf("partial lots = %+v, want unknown term and no day change", got)
+	}
+}
+
+func TestSummarizeGains(t *testing.T) {
+	ptr := func(v int64) *int64 { return &v }
+	holdings := []holdingGainJSON{
+		{ValueCents: 300000, CostBasisCents: ptr(175000), UnrealizedGainCents: ptr(125000), ShortTermGainCents: ptr(50000), LongTermGainCents: ptr(75000), PreviousValueCents: ptr(296000), DayChangeCents: ptr(4000)},
+		{ValueCents: 100000, CostBasisCents: ptr(125000), UnrealizedGainCents: ptr(-25000)},
+		{ValueCents: 50000},
+	}
+
+	got := summarizeGains(holdings)
+	if got.ValueCents != 450000 || got.CostBasisCents != 300000 || got.UnrealizedGainCents != 100000 {
+		t.Errorf("totals = %+v", got)
+	}
+	if got.ShortTermGainCents != 50000 || got.LongTermGainCents != 75000 || got.UnclassifiedGainCents != -25000 {
+		t.Errorf("term split = %+v", got)
+	}
+	if *got.UnrealizedGainPercent != 33.33 || got.DayChangeCents != 4000 || *got.DayChangePercent != 1.35 {
+		t.Errorf("percentages = %v / %v", *got.UnrealizedGainPercent, *got.DayChangePercent)
+	}
+}
+
+func TestLatestHoldingDays(t *testing.T) {
+	row := func(account string, d int, cents int64) database.DailyHolding {
+		return database.DailyHolding{AccountID: account, Symbol: "VTI", Date: database.DateOnly{Time: time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)}, ValueCents: cents}
+	}
+	days := latestHoldingDays([]database.DailyHolding{row("a", 15, 1), row("a", 17, 3), row("a", 16, 2), row("b", 12, 9)})
+
+	if a := days["a"]; a.Date.Day() != 17 || a.Current[0].ValueCents != 3 || a.PreviousDate.Day() != 16 || a.Previous[0].ValueCents != 2 {
+		t.Errorf("account a days = %+v", a)
+	}
+	if b := days["b"]; b.Date.Day() != 12 || !b.PreviousDate.IsZero() || b.Previous != nil {
+		t.Errorf("account b days = %+v", b)
+	}
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Tiers of holdings history, finest first.
+const (
+	holdingsTierDaily   = "daily"
+	holdingsTierWeekly  = "weekly"
+	holdingsTierMonthly = "monthly"
+)
+
+// Default weeks weekly rollups are kept after their daily rows are pruned.
+const defaultHoldingsWeeklyRetentionWeeks = 52
+
+// How long each holdings tier is kept. Monthly rollups are kept forever when MonthlyMonths is 0.
+type holdingsRetentionPolicy struct {
+	DailyDays     int
+	WeeklyWeeks   int
+	MonthlyMonths int
+}
+
+// Reads the holdings tier lengths from HOLDINGS_DAILY_RETENTION_DAYS, HOLDINGS_WEEKLY_RETENTION_WEEKS and
+// HOLDINGS_MONTHLY_RETENTION_MONTHS. Daily rows are kept at least dailyHoldingsRetentionDays.
+func holdingsRetentionPolicyFromEnv() holdingsRetentionPolicy {
+	return holdingsRetentionPolicy{
+		DailyDays:     retentionLengthFromEnv("HOLDINGS_DAILY_RETENTION_DAYS", dailyHoldingsRetentionDays, dailyHoldingsRetentionDays),
+		WeeklyWeeks:   retentionLengthFromEnv("HOLDINGS_WEEKLY_RETENTION_WEEKS", defaultHoldingsWeeklyRetentionWeeks, 0),
+		MonthlyMonths: retentionLengthFromEnv("HOLDINGS_MONTHLY_RETENTION_MONTHS", 0, 0),
+	}
+}
+
+// Parses a tier length from the environment, falling back on a missing or invalid value and raising it to min.
+func retentionLengthFromEnv(key string, fallback, min int) int {
+	length := fallback
+	if value := getEnv(key, ""); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			log.Printf("retention: invalid %s %q, using %d", key, value, fallback)
+		} else {
+			length = parsed
+		}
+	}
+	if length < min {
+		length = min
+	}
+	return length
+}
+
+// Returns the first day daily holdings are kept from: the Monday of the week DailyDays ago, so only whole weeks
+// are rolled up and pruned.
+func holdingsDailyCutoff(today time.Time, policy holdingsRetentionPolicy) time.Time {
+	return weekStart(today.AddDate(0, 0, -policy.DailyDays))
+}
+
+// Returns the first day of the daily holdings window the gains, performance, allocation, income, consolidated
+// and revalue endpoints read: DailyDays before today under the configured policy.
+func dailyHoldingsWindowStart(today time.Time) time.Time {
+	return today.AddDate(0, 0, -holdingsRetentionPolicyFromEnv().DailyDays)
+}
+
+// Returns the Monday of the date's week.
+func weekStart(date time.Time) time.Time {
+	day := calendarDay(date)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// Rolls daily holdings older than the daily tier into weekly and monthly rollups, then prunes the daily rows
+// and any rollups past their tier's length.
+func rollUpAndPruneHoldings(ctx context.Context, deps apiDependencies, today time.Time, policy holdingsRetentionPolicy) error {
+	dailyCutoff := holdingsDailyCutoff(today, policy)
+	daily, err := deps.db.ListDailyHoldingsBefore(ctx, dailyCutoff)
+	if err != nil {
+		return err
+	}
+
+	rollups := rollUpHoldings(daily)
+	err = deps.db.UpsertHoldingRollups(ctx, rollups)
+	if err != nil {
+		return err
+	}
+	// Drops symbols an account held earlier in a period but not on the period's latest day.
+	type periodKey struct {
+		Tier        string
+		PeriodStart time.Time
+		AccountID   string
+	}
+	latest := make(map[periodKey]time.Time)
+	for _, rollup := range rollups {
+		key := periodKey{Tier: rollup.Tier, PeriodStart: rollup.PeriodStart.Time, AccountID: rollup.AccountID}
+		latest[key] = rollup.AsOf.Time
+	}
+	for key, asOf := range latest {
+		err = deps.db.DeleteStaleHoldingRollups(ctx, key.Tier, key.PeriodStart, key.AccountID, asOf)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = deps.db.DeleteDailyHoldingsOlderThan(ctx, dailyCutoff)
+	if err != nil {
+		return err
+	}
+	weeklyCutoff := dailyCutoff.AddDate(0, 0, -7*policy.WeeklyWeeks)
+	err = deps.db.DeleteHoldingRollupsBefore(ctx, holdingsTierWeekly, weeklyCutoff)
+	if err != nil {
+		return err
+	}
+	if policy.MonthlyMonths > 0 {
+		return deps.db.DeleteHoldingRollupsBefore(ctx, holdingsTierMonthly, monthStart(weeklyCutoff).AddDate(0, -policy.MonthlyMonths, 0))
+	}
+	return nil
+}
+
+// Builds weekly and monthly rollups from daily holdings: for each account, the rows of its latest day in each
+// week (starting Monday) and in each month. Rollups are ordered by tier, period, account and symbol.
+func rollUpHoldings(daily []database.DailyHolding) []database.HoldingRollup {
+	type periodKey struct {
+		Tier        string
+		PeriodStart time.Time
+		AccountID   string
+	}
+	latest := make(map[periodKey]time.Time)
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		for _, key := range []periodKey{
+			{Tier: holdingsTierWeekly, PeriodStart: weekStart(date), AccountID: holding.AccountID},
+			{Tier: holdingsTierMonthly, PeriodStart: monthStart(date), AccountID: holding.AccountID},
+		} {
+			if date.After(latest[key]) {
+				latest[key] = date
+			}
+		}
+	}
+
+	var rollups []database.HoldingRollup
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		for _, key := range []periodKey{
+			{Tier: holdingsTierWeekly, PeriodStart: weekStart(date), AccountID: holding.AccountID},
+			{Tier: holdingsTierMonthly, PeriodStart: monthStart(date), AccountID: holding.AccountID},
+		} {
+			if !latest[key].Equal(date) {
+				continue
+			}
+			rollups = append(rollups, database.HoldingRollup{
+				Tier:           key.Tier,
+				PeriodStart:    database.DateOnly{Time: key.PeriodStart},
+				AsOf:           database.DateOnly{Time: date},
+				AccountID:      holding.AccountID,
+				Symbol:         holding.Symbol,
+				Quantity:       holding.Quantity,
+				ValueCents:     holding.ValueCents,
+				CostBasisCents: holding.CostBasisCents,
+			})
+		}
+	}
+	sort.Slice(rollups, func(i, j int) bool {
+		a, b := rollups[i], rollups[j]
+		if a.Tier != b.Tier {
+			return a.Tier > b.Tier
+		}
+		if !a.PeriodStart.Equal(b.PeriodStart.Time) {
+			return a.PeriodStart.Before(b.PeriodStart.Time)
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.Symbol < b.Symbol
+	})
+	return rollups
+}
+
+// Stitches the tiers into one series per account: daily rows where the account has them, weekly rollups before
+// its first daily day, and monthly rollups before its first weekly one. Points are ordered by date; positions
+// with no quantity are skipped.
+func stitchHoldingsHistory(daily []database.DailyHolding, weekly, monthly []database.HoldingRollup, accountNames map[string]string) []HoldingDataPoint {
+	// Finds where each account's finer tiers start.
+	dailyStart := make(map[string]time.Time)
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		if start, ok := dailyStart[holding.AccountID]; !ok || date.Before(start) {
+			dailyStart[holding.AccountID] = date
+		}
+	}
+	before := func(starts map[string]time.Time, accountID string, date time.Time) bool {
+		start, ok := starts[accountID]
+		return !ok || date.Before(start)
+	}
+	weeklyStart := make(map[string]time.Time, len(dailyStart))
+	for accountID, start := range dailyStart {
+		weeklyStart[accountID] = start
+	}
+	for _, rollup := range weekly {
+		date := calendarDay(rollup.AsOf.Time)
+		if before(dailyStart, rollup.AccountID, date) && before(weeklyStart, rollup.AccountID, date) {
+			weeklyStart[rollup.AccountID] = date
+		}
+	}
+
+	points := make([]HoldingDataPoint, 0, len(daily))
+	addRollups := func(rollups []database.HoldingRollup, starts map[string]time.Time, tier string) {
+		for _, rollup := range rollups {
+			if rollup.Quantity == 0 || !before(starts, rollup.AccountID, calendarDay(rollup.AsOf.Time)) {
+				continue
+			}
+			points = append(points, HoldingDataPoint{
+				Date:           rollup.AsOf.Format(dateLayout),
+				Tier:           tier,
+				AccountID:      rollup.AccountID,
+				AccountName:    accountNames[rollup.AccountID],
+				Symbol:         rollup.Symbol,
+				Quantity:       rollup.Quantity,
+				ValueCents:     rollup.ValueCents,
+				CostBasisCents: rollup.CostBasisCents,
+			})
+		}
+	}
+	addRollups(monthly, weeklyStart, holdingsTierMonthly)
+	addRollups(weekly, dailyStart, holdingsTierWeekly)
+	for _, holding := range daily {
+		if holding.Quantity == 0 {
+			continue
+		}
+		points = append(points, HoldingDataPoint{
+			Date:           holding.Date.Format(dateLayout),
+			Tier:           holdingsTierDaily,
+			AccountID:      holding.AccountID,
+			AccountName:    accountNames[holding.AccountID],
+			Symbol:         holding.Symbol,
+			Quantity:       holding.Quantity,
+			ValueCents:     holding.ValueCents,
+			CostBasisCents: holding.CostBasisCents,
+		})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Date < points[j].Date
+	})
+	return points
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestRollUpHoldings(t *testing.T) {
+	holding := func(account string, d int, symbol string, cents int64) database.DailyHolding {
+		return database.DailyHolding{Date: database.DateOnly{Time: utcDay(2026, 3, d)}, AccountID: account, Symbol: symbol, Quantity: 1, ValueCents: cents}
+	}
+	daily := []database.DailyHolding{
+		// Week of Monday the 23rd; VTI is sold by Friday the 27th.
+		holding("brokerage", 23, "VTI", 100),
+		holding("brokerage", 23, "BND", 200),
+		holding("brokerage", 27, "BND", 210),
+		// Week of Monday the 30th, which ends in April.
+		holding("brokerage", 30, "BND", 220),
+		holding("brokerage", 31, "BND", 230),
+		holding("ira", 24, "VXUS", 300),
+	}
+
+	got := rollUpHoldings(daily)
+	want := []struct {
+		tier, periodStart, asOf, account, symbol string
+		cents                                    int64
+	}{
+		{holdingsTierWeekly, "2026-03-23", "2026-03-27", "brokerage", "BND", 210},
+		{holdingsTierWeekly, "2026-03-23", "2026-03-24", "ira", "VXUS", 300},
+		{holdingsTierWeekly, "2026-03-30", "2026-03-31", "brokerage", "BND", 230},
+		{holdingsTierMonthly, "2026-03-01", "2026-03-31", "brokerage", "BND", 230},
+		{holdingsTierMonthly, "2026-03-01", "2026-03-24", "ira", "VXUS", 300},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rollups = %+v, want %d", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Tier != w.tier || g.PeriodStart.Format(dateLayout) != w.periodStart || g.AsOf.Format(dateLayout) != w.asOf ||
+			g.AccountID != w.account || g.Symbol != w.symbol || g.ValueCents != w.cents {
+			t.Errorf("rollup %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestStitchHoldingsHistory(t *testing.T) {
+	rollup := func(tier string, m time.Month, d int, account string, cents int64) database.HoldingRollup {
+		return database.HoldingRollup{Tier: tier, AsOf: database.DateOnly{Time: utcDay(2026, m, d)}, AccountID: account, Symbol: "VTI", Quantity: 1, ValueCents: cents}
+	}
+	daily := []database.DailyHolding{
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 2)}, AccountID: "brokerage", Symbol: "VTI", Quantity: 1, ValueCents: 500},
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 3)}, AccountID: "brokerage", Symbol: "VTI", Quantity: 0, ValueCents: 0},
+	}
+	weekly := []database.HoldingRollup{
+		rollup(holdingsTierWeekly, 2, 20, "brokerage", 400),
+		rollup(holdingsTierWeekly, 2, 27, "brokerage", 450),
+		// Overlaps the daily tier, so it's dropped.
+		rollup(holdingsTierWeekly, 3, 6, "brokerage", 999),
+		// The IRA has no daily rows in the range.
+		rollup(holdingsTierWeekly, 2, 27, "ira", 50),
+	}
+	monthly := []database.HoldingRollup{
+		rollup(holdingsTierMonthly, 1, 30, "brokerage", 300),
+		// Covered by the weekly tier.
+		rollup(holdingsTierMonthly, 2, 27, "brokerage", 999),
+		rollup(holdingsTierMonthly, 1, 30, "ira", 40),
+	}
+
+	got := stitchHoldingsHistory(daily, weekly, monthly, map[string]string{"ira": "IRA"})
+	want := []struct {
+		date, tier, account string
+		cents               int64
+	}{
+		{"2026-01-30", holdingsTierMonthly, "brokerage", 300},
+		{"2026-01-30", holdingsTierMonthly, "ira", 40},
+		{"2026-02-20", holdingsTierWeekly, "brokerage", 400},
+		{"2026-02-27", holdingsTierWeekly, "brokerage", 450},
+		{"2026-02-27", holdingsTierWeekly, "ira", 50},
+		{"2026-03-02", holdingsTierDaily, "brokerage", 500},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("points = %+v, want %d", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Date != w.date || g.Tier != w.tier || g.AccountID != w.account || g.ValueCents != w.cents {
+			t.Errorf("point %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if got[1].AccountName != "IRA" {
+		t.Errorf("account name = %q, want IRA", got[1].AccountName)
+	}
+}
+
+func TestHoldingsRetentionPolicyFromEnv(t *testing.T) {
+	t.Setenv("HOLDINGS_DAILY_RETENTION_DAYS", "7")
+	t.Setenv("HOLDINGS_WEEKLY_RETENTION_WEEKS", "26")
+	t.Setenv("HOLDINGS_MONTHLY_RETENTION_MONTHS", "bad")
+
+	got := holdingsRetentionPolicyFromEnv()
+	want := holdingsRetentionPolicy{DailyDays: dailyHoldingsRetentionDays, WeeklyWeeks: 26, MonthlyMonths: 0}
+	if got != want {
+		t.Errorf("policy = %+v, want %+v", got, want)
+	}
+
+	// Wednesday, April 15: 30 days earlier is Monday, March 16.
+	if cutoff := holdingsDailyCutoff(utcDay(2026, 4, 15), got); !cutoff.Equal(utcDay(2026, 3, 16)) {
+		t.Errorf("cutoff = %s, want 2026-03-16", cutoff.Format(dateLayout))
+	}
+	// Thursday, April 16: the cutoff stays on that week's Monday.
+	if cutoff := holdingsDailyCutoff(utcDay(2026, 4, 16), got); !cutoff.Equal(utcDay(2026, 3, 16)) {
+		t.Errorf("cutoff = %s, want 2026-03-16", cutoff.Format(dateLayout))
+	}
+}
+
+func TestDailyHoldingsWindowStart(t *testing.T) {
+	today := utcDay(2026, 4, 15)
+	if start := dailyHoldingsWindowStart(today); !start.Equal(utcDay(2026, 3, 16)) {
+		t.Errorf("default window start = %s, want 2026-03-16", start.Format(dateLayout))
+	}
+	t.Setenv("HOLDINGS_DAILY_RETENTION_DAYS", "90")
+	if start := dailyHoldingsWindowStart(today); !start.Equal(utcDay(2026, 1, 15)) {
+		t.Errorf("window start = %s, want 2026-01-15", start.Format(dateLayout))
+	}
+}
@@ -0,0 +1,369 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Income kinds.
+const (
+	incomeDividend    = "dividend"
+	incomeInterest    = "interest"
+	incomeCapitalGain = "capital_gain"
+)
+
+// Symbol reported for income with no security, such as interest on uninvested cash.
+const incomeCashSymbol = "CASH"
+
+// Default and maximum months of history returned by the income endpoint.
+const (
+	defaultIncomeMonths = 12
+	maxIncomeMonths     = 120
+)
+
+// Returns dividend, interest and capital gain distribution income by month, symbol and account, trailing
+// 12-month yield on cost, and a projection of the next 12 months of dividends.
+func handleGetPortfolioIncome(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	months := defaultIncomeMonths
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxIncomeMonths {
+			writeJSONError(w, http.StatusBadRequest, "months must be between 1 and 120")
+			return
+		}
+		months = parsed
+	}
+
+	today := calendarDay(GetLocalNow())
+	since := incomeWindowStart(today, months)
+	if trailingStart := today.AddDate(-1, 0, 0); trailingStart.Before(since) {
+		since = trailingStart
+	}
+	transactions, err := deps.db.ListInvestmentTransactionsSince(r.Context(), since)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list investment transactions: "+err.Error())
+		return
+	}
+	daily, err := deps.db.ListDailyHoldings(r.Context(), dailyHoldingsWindowStart(today), today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list daily holdings: "+err.Error())
+		return
+	}
+	var current []database.DailyHolding
+	for _, days := range latestHoldingDays(daily) {
+		current = append(current, days.Current...)
+	}
+
+	resp := buildIncome(transactions, current, loadAccountNames(r.Context(), deps.db), today, months)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("portfolio income encode: %v", err)
+	}
+}
+
+// Returns the first day of the earliest month in a window of months ending with today's month.
+func incomeWindowStart(today time.Time, months int) time.Time {
+	return time.Date(today.Year(), today.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Returns the income kind of an investment transaction, or "" when it isn't income. Reinvested dividends
+// are buys and don't count; the cash dividend that paid for them does.
+func incomeKind(transaction database.InvestmentTransaction) string {
+	transactionType := strings.ToLower(transaction.Type)
+	if transactionType == "buy" || transactionType == "sell" {
+		return ""
+	}
+	subtype := strings.ToLower(transaction.Subtype)
+	switch {
+	case strings.Contains(subtype, "capital gain"):
+		return incomeCapitalGain
+	case strings.Contains(subtype, "dividend") && !strings.Contains(subtype, "reinvest"):
+		return incomeDividend
+	case subtype == "interest":
+		return incomeInterest
+	}
+	return ""
+}
+
+// Returns the change in shares held from an investment transaction.
+func quantityChange(transaction database.InvestmentTransaction) float64 {
+	switch strings.ToLower(transaction.Type) {
+	case "buy":
+		return math.Abs(transaction.Quantity)
+	case "sell":
+		return -math.Abs(transaction.Quantity)
+	case "transfer":
+		return transaction.Quantity
+	}
+	return 0
+}
+
+// Summarizes income over the months ending with today's month. Yield on cost divides the trailing 12 months'
+// income per symbol by the current holdings' cost basis. Each dividend paid in the trailing 12 months is
+// projected to recur a year later at the holding's latest per-share dividend times its current quantity; the
+// shares held on each payment date are worked back from the current quantity and later buys and sells.
+func buildIncome(transactions []database.InvestmentTransaction, current []database.DailyHolding, accountNames map[string]string, today time.Time, months int) portfolioIncomeResponse {
+	start := incomeWindowStart(today, months)
+	trailingStart := today.AddDate(-1, 0, 0)
+	resp := portfolioIncomeResponse{
+		AsOf:       today.Format(dateLayout),
+		From:       start.Format(dateLayout),
+		ByMonth:    []incomeMonthJSON{},
+		BySymbol:   []incomeSymbolJSON{},
+		ByAccount:  []incomeAccountJSON{},
+		Projection: incomeProjectionJSON{ByMonth: []projectedMonthJSON{}, Holdings: []projectedHoldingJSON{}},
+	}
+
+	monthIndex := make(map[string]int, months)
+	for month := start; !month.After(today); month = month.AddDate(0, 1, 0) {
+		monthIndex[month.Format("2006-01")] = len(resp.ByMonth)
+		resp.ByMonth = append(resp.ByMonth, incomeMonthJSON{Month: month.Format("2006-01")})
+	}
+
+	currentByHolding := make(map[holdingKey]database.DailyHolding, len(current))
+	costBasisBySymbol := make(map[string]int64)
+	for _, holding := range current {
+		currentByHolding[holdingKey{AccountID: holding.AccountID, Symbol: holding.Symbol}] = holding
+		if holding.CostBasisCents != nil {
+			costBasisBySymbol[holding.Symbol] += *holding.CostBasisCents
+		}
+	}
+
+	// Orders the transactions newest first so shares can be worked back from today.
+	ordered := make([]database.InvestmentTransaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Date.After(ordered[j].Date.Time)
+	})
+
+	symbols := make(map[string]*incomeSymbolJSON)
+	accounts := make(map[string]*incomeAccountJSON)
+	sharesLater := make(map[holdingKey]float64)
+	type dividendPayment struct {
+		Date          time.Time
+		PerShareCents float64
+	}
+	payments := make(map[holdingKey][]dividendPayment)
+	for _, transaction := range ordered {
+		symbol := incomeCashSymbol
+		if transaction.Symbol != nil && *transaction.Symbol != "" {
+			symbol = *transaction.Symbol
+		}
+		key := holdingKey{AccountID: transaction.AccountID, Symbol: symbol}
+		date := calendarDay(transaction.Date.Time)
+		kind := incomeKind(transaction)
+		if kind == "" {
+			sharesLater[key] += quantityChange(transaction)
+			continue
+		}
+		amount := transaction.AmountCents
+
+		if kind == incomeDividend && date.After(trailingStart) {
+			if holding, ok := currentByHolding[key]; ok {
+				if shares := holding.Quantity - sharesLater[key]; shares > lotQuantityTolerance {
+					payments[key] = append(payments[key], dividendPayment{Date: date, PerShareCents: float64(amount) / shares})
+				}
+			}
+		}
+
+		if symbols[symbol] == nil {
+			symbols[symbol] = &incomeSymbolJSON{Symbol: symbol}
+		}
+		if date.After(trailingStart) {
+			symbols[symbol].Trailing12MonthCents += amount
+			resp.Trailing12MonthCents += amount
+		}
+		if date.Before(start) || date.After(today) {
+			continue
+		}
+
+		if accounts[transaction.AccountID] == nil {
+			accounts[transaction.AccountID] = &incomeAccountJSON{AccountID: transaction.AccountID, AccountName: accountNames[transaction.AccountID]}
+		}
+		month := &resp.ByMonth[monthIndex[date.Format("2006-01")]]
+		account := accounts[transaction.AccountID]
+		switch kind {
+		case incomeDividend:
+			month.DividendCents += amount
+			account.DividendCents += amount
+			symbols[symbol].DividendCents += amount
+		case incomeInterest:
+			month.InterestCents += amount
+			account.InterestCents += amount
+			symbols[symbol].InterestCents += amount
+		case incomeCapitalGain:
+			month.CapitalGainCents += amount
+			account.CapitalGainCents += amount
+			symbols[symbol].CapitalGainCents += amount
+		}
+		month.TotalCents += amount
+		account.TotalCents += amount
+		symbols[symbol].TotalCents += amount
+		resp.TotalCents += amount
+	}
+
+	// Projects the next 12 months of dividends from the trailing payments.
+	projectedMonths := make(map[string]int64)
+	for key, holdingPayments := range payments {
+		holding := currentByHolding[key]
+		latest := holdingPayments[0]
+		perPayment := int64(math.Round(latest.PerShareCents * holding.Quantity))
+		for _, payment := range holdingPayments {
+			projectedMonths[payment.Date.AddDate(1, 0, 0).Format("2006-01")] += perPayment
+		}
+		projected := perPayment * int64(len(holdingPayments))
+		resp.Projection.Holdings = append(resp.Projection.Holdings, projectedHoldingJSON{
+			AccountID:                   key.AccountID,
+			AccountName:                 accountNames[key.AccountID],
+			Symbol:                      key.Symbol,
+			Quantity:                    holding.Quantity,
+			PerShareCents:               math.Round(latest.PerShareCents*10000) / 10000,
+			PaymentsPerYear:             len(holdingPayments),
+			Projected12MonthCents:       projected,
+			LastPaymentDate:             latest.Date.Format(dateLayout),
+			ProjectedYieldOnCostPercent: yieldOnCost(projected, holding.CostBasisCents),
+		})
+		resp.Projection.TotalCents += projected
+		if symbol := symbols[key.Symbol]; symbol != nil {
+			symbol.Projected12MonthCents += projected
+		}
+	}
+	sort.Slice(resp.Projection.Holdings, func(i, j int) bool {
+		a, b := resp.Projection.Holdings[i], resp.Projection.Holdings[j]
+		if a.Projected12MonthCents != b.Projected12MonthCents {
+			return a.Projected12MonthCents > b.Projected12MonthCents
+		}
+		return a.AccountID+a.Symbol < b.AccountID+b.Symbol
+	})
+	nextMonth := time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		month := nextMonth.AddDate(0, i, 0).Format("2006-01")
+		resp.Projection.ByMonth = append(resp.Projection.ByMonth, projectedMonthJSON{Month: month, DividendCents: projectedMonths[month]})
+	}
+
+	// Yield on cost per symbol and overall, for symbols still held with a cost basis.
+	var incomeWithCost, totalCost int64
+	for symbol, summary := range symbols {
+		if cost, ok := costBasisBySymbol[symbol]; ok && cost > 0 {
+			summary.CostBasisCents = &cost
+			summary.YieldOnCostPercent = yieldOnCost(summary.Trailing12MonthCents, &cost)
+			incomeWithCost += summary.Trailing12MonthCents
+			totalCost += cost
+		}
+		resp.BySymbol = append(resp.BySymbol, *summary)
+	}
+	if totalCost > 0 {
+		resp.YieldOnCostPercent = yieldOnCost(incomeWithCost, &totalCost)
+	}
+	sort.Slice(resp.BySymbol, func(i, j int) bool {
+		if resp.BySymbol[i].TotalCents != resp.BySymbol[j].TotalCents {
+			return resp.BySymbol[i].TotalCents > resp.BySymbol[j].TotalCents
+		}
+		return resp.BySymbol[i].Symbol < resp.BySymbol[j].Symbol
+	})
+
+	for _, account := range accounts {
+		resp.ByAccount = append(resp.ByAccount, *account)
+	}
+	sort.Slice(resp.ByAccount, func(i, j int) bool {
+		if resp.ByAccount[i].TotalCents != resp.ByAccount[j].TotalCents {
+			return resp.ByAccount[i].TotalCents > resp.ByAccount[j].TotalCents
+		}
+		return resp.ByAccount[i].AccountID < resp.ByAccount[j].AccountID
+	})
+	return resp
+}
+
+// Returns income as a percentage of cost basis, or nil without a positive cost basis.
+func yieldOnCost(incomeCents int64, costBasisCents *int64) *float64 {
+	if costBasisCents == nil || *costBasisCents <= 0 {
+		return nil
+	}
+	percent := roundPercent(float64(incomeCents) / float64(*costBasisCents) * 100)
+	return &percent
+}
+
+// Income response.
+type portfolioIncomeResponse struct {
+	AsOf                 string               `json:"asOf"`
+	From                 string               `json:"from"`
+	TotalCents           int64                `json:"totalCents"`
+	Trailing12MonthCents int64                `json:"trailing12MonthCents"`
+	YieldOnCostPercent   *float64             `json:"yieldOnCostPercent,omitempty"`
+	ByMonth              []incomeMonthJSON    `json:"byMonth"`
+	BySymbol             []incomeSymbolJSON   `json:"bySymbol"`
+	ByAccount            []incomeAccountJSON  `json:"byAccount"`
+	Projection           incomeProjectionJSON `json:"projection"`
+}
+
+// One month's income for API.
+type incomeMonthJSON struct {
+	Month            string `json:"month"`
+	DividendCents    int64  `json:"dividendCents"`
+	InterestCents    int64  `json:"interestCents"`
+	CapitalGainCents int64  `json:"capitalGainCents"`
+	TotalCents       int64  `json:"totalCents"`
+}
+
+// One symbol's income for API. Trailing and projected amounts cover 12 months regardless of the window.
+type incomeSymbolJSON struct {
+	Symbol                string   `json:"symbol"`
+	DividendCents         int64    `json:"dividendCents"`
+	InterestCents         int64    `json:"interestCents"`
+	CapitalGainCents      int64    `json:"capitalGainCents"`
+	TotalCents            int64    `json:"totalCents"`
+	Trailing12MonthCents  int64    `json:"trailing12MonthCents"`
+	CostBasisCents        *int64   `json:"costBasisCents,omitempty"`
+	YieldOnCostPercent    *float64 `json:"yieldOnCostPercent,omitempty"`
+	Projected12MonthCents int64    `json:"projected12MonthCents"`
+}
+
+// One account's income for API.
+type incomeAccountJSON struct {
+	AccountID        string `json:"accountId"`
+	AccountName      string `json:"accountName,omitempty"`
+	DividendCents    int64  `json:"dividendCents"`
+	InterestCents    int64  `json:"interestCents"`
+	CapitalGainCents int64  `json:"capitalGainCents"`
+	TotalCents       int64  `json:"totalCents"`
+}
+
+// Projected dividends for the next 12 months.
+type incomeProjectionJSON struct {
+	TotalCents int64                  `json:"totalCents"`
+	ByMonth    []projectedMonthJSON   `json:"byMonth"`
+	Holdings   []projectedHoldingJSON `json:"holdings"`
+}
+
+// One month's projected dividends for API.
+type projectedMonthJSON struct {
+	Month         string `json:"month"`
+	DividendCents int64  `json:"dividendCents"`
+}
+
+// One holding's projected dividends for API.
+type projectedHoldingJSON struct {
+	AccountID                   string   `json:"accountId"`
+	AccountName                 string   `json:"accountName,omitempty"`
+	Symbol                      string   `json:"symbol"`
+	Quantity                    float64  `json:"quantity"`
+	PerShareCents               float64  `json:"perShareCents"`
+	PaymentsPerYear             int      `json:"paymentsPerYear"`
+	LastPaymentDate             string   `json:"lastPaymentDate"`
+	Projected12MonthCents       int64    `json:"projected12MonthCents"`
+	ProjectedYieldOnCostPercent *float64 `json:"projectedYieldOnCostPercent,omitempty"`
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestIncomeKind(t *testing.T) {
+	tests := []struct {
+		transactionType, subtype string
+		want                     string
+	}{
+		{"cash", "dividend", incomeDividend},
+		{"cash", "qualified dividend", incomeDividend},
+		{"buy", "dividend reinvestment", ""},
+		{"cash", "interest", incomeInterest},
+		{"cash", "long-term capital gain", incomeCapitalGain},
+		{"cash", "deposit", ""},
+		{"fee", "management fee", ""},
+	}
+	for _, tt := range tests {
+		got := incomeKind(database.InvestmentTransaction{Type: tt.transactionType, Subtype: tt.subtype})
+		if got != tt.want {
+			t.Errorf("incomeKind(%s/%s) = %q, want %q", tt.transactionType, tt.subtype, got, tt.want)
+		}
+	}
+}
+
+func TestBuildIncome(t *testing.T) {
+	symbol := func(s string) *string { return &s }
+	transaction := func(month, day int, transactionType, subtype, ticker string, quantity float64, cents int64) database.InvestmentTransaction {
+		row := database.InvestmentTransaction{
+			AccountID:   "brokerage",
+			Date:        database.DateOnly{Time: utcDay(2026, 1, 1).AddDate(0, month-1, day-1)},
+			Type:        transactionType,
+			Subtype:     subtype,
+			Quantity:    quantity,
+			AmountCents: cents,
+		}
+		if ticker != "" {
+			row.Symbol = symbol(ticker)
+		}
+		return row
+	}
+	transactions := []database.InvestmentTransaction{
+		// 10 shares earn 1.00 in March; 10 more are bought before June's 1.10.
+		transaction(3, 27, "cash", "dividend", "VTI", 0, 1000),
+		transaction(4, 15, "buy", "buy", "VTI", 10, -300000),
+		transaction(6, 26, "cash", "dividend", "VTI", 0, 2200),
+		transaction(6, 30, "cash", "interest", "", 0, 150),
+		transaction(9, 25, "buy", "dividend reinvestment", "VTI", 0.1, -2200),
+	}
+	costBasis := int64(600000)
+	current := []database.DailyHolding{{AccountID: "brokerage", Symbol: "VTI", Quantity: 20.1, ValueCents: 643200, CostBasisCents: &costBasis}}
+
+	got := buildIncome(transactions, current, map[string]string{"brokerage": "Brokerage"}, utcDay(2026, 10, 18), 12)
+	if got.TotalCents != 3350 || got.Trailing12MonthCents != 3350 {
+		t.Errorf("total = %d trailing = %d, want 3350 3350", got.TotalCents, got.Trailing12MonthCents)
+	}
+	if len(got.ByMonth) != 12 || got.ByMonth[0].Month != "2025-11" || got.ByMonth[7].DividendCents != 2200 || got.ByMonth[7].InterestCents != 150 {
+		t.Errorf("by month = %+v", got.ByMonth)
+	}
+	if len(got.ByAccount) != 1 || got.ByAccount[0].AccountName != "Brokerage" || got.ByAccount[0].TotalCents != 3350 {
+		t.Errorf("by account = %+v", got.ByAccount)
+	}
+	if len(got.BySymbol) != 2 || got.BySymbol[0].Symbol != "VTI" || got.BySymbol[1].Symbol != incomeCashSymbol {
+		t.Fatalf("by symbol = %+v", got.BySymbol)
+	}
+	if vti := got.BySymbol[0]; vti.YieldOnCostPercent == nil || *vti.YieldOnCostPercent != 0.53 {
+		t.Errorf("VTI yield on cost = %v, want 0.53", vti.YieldOnCostPercent)
+	}
+
+	// Both payments recur at June's 1.10 a share (on the 20 shares held before the reinvestment) on the
+	// current 20.1 shares.
+	if len(got.Projection.Holdings) != 1 {
+		t.Fatalf("projection holdings = %+v", got.Projection.Holdings)
+	}
+	holding := got.Projection.Holdings[0]
+	if holding.PerShareCents != 110 || holding.PaymentsPerYear != 2 || holding.Projected12MonthCents != 4422 || got.Projection.TotalCents != 4422 {
+		t.Errorf("projection = %+v total %d, want 110/share x2 = 4422", holding, got.Projection.TotalCents)
+	}
+	if len(got.Projection.ByMonth) != 12 || got.Projection.ByMonth[0].Month != "2026-11" || got.Projection.ByMonth[7].DividendCents != 2211 {
+		t.Errorf("projection by month = %+v", got.Projection.ByMonth)
+	}
+}
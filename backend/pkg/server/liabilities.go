@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Registers the liabilities route.
+func registerLiabilitiesRoutes(mux *http.ServeMux, deps apiDependencies) {
+	// GET /api/liabilities returns credit card and loan details with monthly interest cost and utilization.
+	mux.Handle("/api/liabilities", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetLiabilities(w, r, deps)
+	})))
+}
+
+// Returns stored liabilities joined with their accounts.
+func handleGetLiabilities(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	liabilities, err := deps.db.ListLiabilities(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list liabilities: "+err.Error())
+		return
+	}
+	plaidAccounts, err := deps.db.ListPlaidAccounts(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list Plaid accounts: "+err.Error())
+		return
+	}
+	accountsByID := make(map[string]database.PlaidAccount, len(plaidAccounts))
+	for _, account := range plaidAccounts {
+		accountsByID[account.AccountID] = account
+	}
+
+	resp := liabilitiesResponse{Liabilities: make([]liabilityJSON, 0, len(liabilities))}
+	var totalCreditBalanceCents, totalCreditLimitCents int64
+	for _, liability := range liabilities {
+		account := accountsByID[liability.AccountID]
+		balanceCents := int64(math.Round(account.CurrentBalance * 100))
+		output := toLiabilityJSON(liability, account, balanceCents)
+		resp.Liabilities = append(resp.Liabilities, output)
+		resp.TotalBalanceCents += balanceCents
+		resp.TotalMonthlyInterestCents += output.MonthlyInterestCents
+
+		if liability.LiabilityType == "credit" && liability.CreditLimitCents != nil {
+			totalCreditBalanceCents += balanceCents
+			totalCreditLimitCents += *liability.CreditLimitCents
+		}
+	}
+	resp.TotalCreditLimitCents = totalCreditLimitCents
+	resp.OverallUtilizationPercent = creditUtilizationPercent(totalCreditBalanceCents, &totalCreditLimitCents)
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("liabilities encode: %v", err)
+	}
+}
+
+// Refreshes liabilities for every Plaid item with credit or loan accounts. Returns the number of rows written.
+// Items without the liabilities product (or other per-item failures) are logged and skipped.
+func refreshLiabilities(ctx context.Context, deps apiDependencies) (int, error) {
+	if deps.db == nil || deps.plaidClient == nil {
+		return 0, nil
+	}
+
+	items, err := deps.db.ListPlaidItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+	accounts, err := deps.db.ListPlaidAccounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Finds the liability accounts we already store, by item.
+	liabilityAccounts := make(map[string]bool)
+	itemsWithLiabilities := make(map[string]bool)
+	for _, account := range accounts {
+		if isPlaidLiability(account.Type) {
+			liabilityAccounts[account.AccountID] = true
+			itemsWithLiabilities[account.PlaidItemID] = true
+		}
+	}
+
+	now := GetLocalNow()
+	written := 0
+	for _, item := range items {
+		if item.AccessToken == "manual" || !itemsWithLiabilities[item.ItemID] {
+			continue
+		}
+		plaidLiabilities, plaidAccounts, err := deps.plaidClient.GetLiabilities(ctx, item.AccessToken)
+		if err != nil {
+			log.Printf("cron: failed to get liabilities for item %s: %v", item.ItemID, err)
+			continue
+		}
+
+		// Only keeps rows for accounts in plaid_accounts (liabilities.account_id references it).
+		var rows []database.Liability
+		for _, liability := range plaidLiabilitiesToDB(plaidLiabilities, plaidAccounts, now) {
+			if liabilityAccounts[liability.AccountID] {
+				rows = append(rows, liability)
+			}
+		}
+		err = deps.db.UpsertLiabilities(ctx, rows)
+		if err != nil {
+			log.Printf("cron: upsert liabilities for item %s: %v", item.ItemID, err)
+			continue
+		}
+		written += len(rows)
+	}
+	return written, nil
+}
+
+// Converts Plaid liabilities into our DB model. Credit limits come from the accounts in the same response.
+func plaidLiabilitiesToDB(liabilities *plaid.Liabilities, accounts []plaid.Account, now time.Time) []database.Liability {
+	limitsByAccount := make(map[string]*int64)
+	for _, account := range accounts {
+		limitsByAccount[account.AccountID] = optionalCents(account.Balances.Limit)
+	}
+
+	var rows []database.Liability
+	for _, credit := range liabilities.Credit {
+		if credit.AccountID == nil {
+			continue
+		}
+		row := database.Liability{
+			AccountID:                 *credit.AccountID,
+			LiabilityType:             "credit",
+			APRs:                      make([]database.LiabilityAPR, 0, len(credit.APRs)),
+			LastStatementBalanceCents: optionalCents(credit.LastStatementBalance),
+			LastStatementIssueDate:    optionalDate(credit.LastStatementIssueDate),
+			MinimumPaymentCents:       optionalCents(credit.MinimumPaymentAmount),
+			NextPaymentDueDate:        optionalDate(credit.NextPaymentDueDate),
+			LastPaymentAmountCents:    optionalCents(credit.LastPaymentAmount),
+			LastPaymentDate:           optionalDate(credit.LastPaymentDate),
+			IsOverdue:                 credit.IsOverdue,
+			CreditLimitCents:          limitsByAccount[*credit.AccountID],
+			UpdatedAt:                 now,
+		}
+		for _, apr := range credit.APRs {
+			row.APRs = append(row.APRs, database.LiabilityAPR{
+				APRPercentage:            apr.APRPercentage,
+				APRType:                  apr.APRType,
+				BalanceSubjectToAPRCents: optionalCents(apr.BalanceSubjectToAPR),
+				InterestChargeCents:      optionalCents(apr.InterestChargeAmount),
+			})
+			// The purchase APR is the card's headline rate.
+			if apr.APRType == "purchase_apr" {
+				rate := apr.APRPercentage
+				row.InterestRatePercentage = &rate
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	for _, student := range liabilities.Student {
+		if student.AccountID == nil {
+			continue
+		}
+		rate := student.InterestRatePercentage
+		rows = append(rows, database.Liability{
+			AccountID:                 *student.AccountID,
+			LiabilityType:             "student",
+			APRs:                      []database.LiabilityAPR{},
+			InterestRatePercentage:    &rate,
+			LastStatementBalanceCents: optionalCents(student.LastStatementBalance),
+			LastStatementIssueDate:    optionalDate(student.LastStatementIssueDate),
+			MinimumPaymentCents:       optionalCents(student.MinimumPaymentAmount),
+			NextPaymentDueDate:        optionalDate(student.NextPaymentDueDate),
+			LastPaymentAmountCents:    optionalCents(student.LastPaymentAmount),
+			LastPaymentDate:           optionalDate(student.LastPaymentDate),
+			IsOverdue:                 student.IsOverdue,
+			OriginationPrincipalCents: optionalCents(student.OriginationPrincipalAmount),
+			OutstandingInterestCents:  optionalCents(student.OutstandingInterestAmount),
+			PayoffDate:                optionalDate(student.ExpectedPayoffDate),
+			LoanName:                  student.LoanName,
+			UpdatedAt:                 now,
+		})
+	}
+
+	for _, mortgage := range liabilities.Mortgage {
+		var isOverdue *bool
+		if mortgage.PastDueAmount != nil {
+			overdue := *mortgage.PastDueAmount > 0
+			isOverdue = &overdue
+		}
+		rows = append(rows, database.Liability{
+			AccountID:                 mortgage.AccountID,
+			LiabilityType:             "mortgage",
+			APRs:                      []database.LiabilityAPR{},
+			InterestRatePercentage:    mortgage.InterestRate.Percentage,
+			InterestRateType:          mortgage.InterestRate.Type,
+			MinimumPaymentCents:       optionalCents(mortgage.NextMonthlyPayment),
+			NextPaymentDueDate:        optionalDate(mortgage.NextPaymentDueDate),
+			LastPaymentAmountCents:    optionalCents(mortgage.LastPaymentAmount),
+			LastPaymentDate:           optionalDate(mortgage.LastPaymentDate),
+			IsOverdue:                 isOverdue,
+			OriginationPrincipalCents: optionalCents(mortgage.OriginationPrincipalAmount),
+			PayoffDate:                optionalDate(mortgage.MaturityDate),
+			LoanName:                  mortgage.LoanTypeDescription,
+			UpdatedAt:                 now,
+		})
+	}
+	return rows
+}
+
+// Converts a liability row to its API model with computed interest cost and utilization.
+func toLiabilityJSON(liability database.Liability, account database.PlaidAccount, balanceCents int64) liabilityJSON {
+	output := liabilityJSON{
+		AccountID:                 liability.AccountID,
+		AccountName:               account.Name,
+		Mask:                      account.Mask,
+		LiabilityType:             liability.LiabilityType,
+		BalanceCents:              balanceCents,
+		APRs:                      make([]liabilityAPRJSON, len(liability.APRs)),
+		InterestRatePercentage:    liability.InterestRatePercentage,
+		InterestRateType:          liability.InterestRateType,
+		LastStatementBalanceCents: liability.LastStatementBalanceCents,
+		LastStatementIssueDate:    formatOptionalDate(liability.LastStatementIssueDate),
+		MinimumPaymentCents:       liability.MinimumPaymentCents,
+		NextPaymentDueDate:        formatOptionalDate(liability.NextPaymentDueDate),
+		LastPaymentAmountCents:    liability.LastPaymentAmountCents,
+		LastPaymentDate:           formatOptionalDate(liability.LastPaymentDate),
+		IsOverdue:                 liability.IsOverdue,
+		CreditLimitCents:          liability.CreditLimitCents,
+		OriginationPrincipalCents: liability.OriginationPrincipalCents,
+		OutstandingInterestCents:  liability.OutstandingInterestCents,
+		PayoffDate:                formatOptionalDate(liability.PayoffDate),
+		LoanName:                  liability.LoanName,
+		MonthlyInterestCents:      monthlyInterestCents(liability, balanceCents),
+		UpdatedAt:                 liability.UpdatedAt,
+	}
+	for i, apr := range liability.APRs {
+		output.APRs[i] = liabilityAPRJSON{
+			APRPercentage:            apr.APRPercentage,
+			APRType:                  apr.APRType,
+			BalanceSubjectToAPRCents: apr.BalanceSubjectToAPRCents,
+			InterestChargeCents:      apr.InterestChargeCents,
+		}
+	}
+	if liability.LiabilityType == "credit" {
+		output.UtilizationPercent = creditUtilizationPercent(balanceCents, liability.CreditLimitCents)
+	}
+	return output
+}
+
+// Estimates one month of interest on a liability.
+// Credit cards use the balance subject to each APR when Plaid reports it, otherwise the whole balance at the purchase APR.
+func monthlyInterestCents(liability database.Liability, balanceCents int64) int64 {
+	if balanceCents <= 0 {
+		return 0
+	}
+
+	var interest float64
+	hasAPRBalances := false
+	for _, apr := range liability.APRs {
+		if apr.BalanceSubjectToAPRCents != nil {
+			hasAPRBalances = true
+			interest += float64(*apr.BalanceSubjectToAPRCents) * apr.APRPercentage / 100 / 12
+		}
+	}
+	if !hasAPRBalances && liability.InterestRatePercentage != nil {
+		interest = float64(balanceCents) * *liability.InterestRatePercentage / 100 / 12
+	}
+	return int64(math.Round(interest))
+}
+
+// Returns balance / limit as a percentage, or nil when there is no usable limit.
+func creditUtilizationPercent(balanceCents int64, limitCents *int64) *float64 {
+	if limitCents == nil || *limitCents <= 0 {
+		return nil
+	}
+	utilization := math.Round(float64(balanceCents)/float64(*limitCents)*10000) / 100
+	return &utilization
+}
+
+// Converts an optional dollar amount to cents.
+func optionalCents(amount *float64) *int64 {
+	if amount == nil {
+		return nil
+	}
+	cents := int64(math.Round(*amount * 100))
+	return &cents
+}
+
+// Parses an optional YYYY-MM-DD date.
+func optionalDate(value *string) *database.DateOnly {
+	if value == nil {
+		return nil
+	}
+	date, err := time.Parse(dateLayout, *value)
+	if err != nil {
+		return nil
+	}
+	return &database.DateOnly{Time: date}
+}
+
+// Formats an optional date as YYYY-MM-DD.
+func formatOptionalDate(date *database.DateOnly) *string {
+	if date == nil || date.IsZero() {
+		return nil
+	}
+	formatted := date.Format(dateLayout)
+	return &formatted
+}
+
+// Credit card APR for API.
+type liabilityAPRJSON struct {
+	APRPercentage            float64 `json:"aprPercentage"`
+	APRType                  string  `json:"aprType"`
+	BalanceSubjectToAPRCents *int64  `json:"balanceSubjectToAprCents,omitempty"`
+	InterestChargeCents      *int64  `json:"interestChargeCents,omitempty"`
+}
+
+// Liability for API.
+type liabilityJSON struct {
+	AccountID                 string             `json:"accountId"`
+	AccountName               string             `json:"accountName"`
+	Mask                      *string            `json:"mask,omitempty"`
+	LiabilityType             string             `json:"liabilityType"`
+	BalanceCents              int64              `json:"balanceCents"`
+	APRs                      []liabilityAPRJSON `json:"aprs"`
+	InterestRatePercentage    *float64           `json:"interestRatePercentage,omitempty"`
+	InterestRateType          *string            `json:"interestRateType,omitempty"`
+	LastStatementBalanceCents *int64             `json:"lastStatementBalanceCents,omitempty"`
+	LastStatementIssueDate    *string            `json:"lastStatementIssueDate,omitempty"`
+	MinimumPaymentCents       *int64             `json:"minimumPaymentCents,omitempty"`
+	NextPaymentDueDate        *string            `json:"nextPaymentDueDate,omitempty"`
+	LastPaymentAmountCents    *int64             `json:"lastPaymentAmountCents,omitempty"`
+	LastPaymentDate           *string            `json:"lastPaymentDate,omitempty"`
+	IsOverdue                 *bool              `json:"isOverdue,omitempty"`
+	CreditLimitCents          *int64             `json:"creditLimitCents,omitempty"`
+	UtilizationPercent        *float64           `json:"utilizationPercent,omitempty"`
+	OriginationPrincipalCents *int64             `json:"originationPrincipalCents,omitempty"`
+	OutstandingInterestCents  *int64             `json:"outstandingInterestCents,omitempty"`
+	PayoffDate                *string            `json:"payoffDate,omitempty"`
+	LoanName                  *string            `json:"loanName,omitempty"`
+	MonthlyInterestCents      int64              `json:"monthlyInterestCents"`
+	UpdatedAt                 time.Time          `json:"updatedAt"`
+}
+
+// Liabilities response.
+type liabilitiesResponse struct {
+	Liabilities               []liabilityJSON `json:"liabilities"`
+	TotalBalanceCents         int64           `json:"totalBalanceCents"`
+	TotalMonthlyInterestCents int64           `json:"totalMonthlyInterestCents"`
+	TotalCreditLimitCents     int64           `json:"totalCreditLimitCents"`
+	OverallUtilizationPercent *float64        `json:"overallUtilizationPercent,omitempty"`
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+)
+
+func TestMonthlyInterestCents(t *testing.T) {
+	purchaseBalance := int64(100000)
+	cashBalance := int64(20000)
+	rate := 24.0
+
+	tests := []struct {
+		name         string
+		liability    database.Liability
+		balanceCents int64
+		want         int64
+	}{
+		{
+			name: "credit uses balance subject to each APR",
+			liability: database.Liability{LiabilityType: "credit", APRs: []database.LiabilityAPR{
+				{APRPercentage: 24, APRType: "purchase_apr", BalanceSubjectToAPRCents: &purchaseBalance},
+				{APRPercentage: 30, APRType: "cash_apr", BalanceSubjectToAPRCents: &cashBalance},
+			}},
+			balanceCents: 120000,
+			want:         2500, // 1000.00 * 2% + 200.00 * 2.5%
+		},
+		{
+			name:         "falls back to whole balance at headline rate",
+			liability:    database.Liability{LiabilityType: "credit", InterestRatePercentage: &rate, APRs: []database.LiabilityAPR{{APRPercentage: 24, APRType: "purchase_apr"}}},
+			balanceCents: 50000,
+			want:         1000,
+		},
+		{
+			name:         "paid off card costs nothing",
+			liability:    database.Liability{LiabilityType: "credit", InterestRatePercentage: &rate},
+			balanceCents: 0,
+			want:         0,
+		},
+		{
+			name:         "loan without rate",
+			liability:    database.Liability{LiabilityType: "student"},
+			balanceCents: 1000000,
+			want:         0,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := monthlyInterestCents(tt.liability, tt.balanceCents); got != tt.want {
+			t.Errorf("%s: monthlyInterestCents = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreditUtilizationPercent(t *testing.T) {
+	limit := int64(500000)
+	got := creditUtilizationPercent(123456, &limit)
+	if got == nil || *got != 24.69 {
+		t.Errorf("creditUtilizationPercent = %v, want 24.69", got)
+	}
+
+	zero := int64(0)
+	if got := creditUtilizationPercent(100, &zero); got != nil {
+		t.Errorf("creditUtilizationPercent with zero limit = %v, want nil", *got)
+	}
+	if got := creditUtilizationPercent(100, nil); got != nil {
+		t.Errorf("creditUtilizationPercent with no limit = %v, want nil", *got)
+	}
+}
+
+func TestPlaidLiabilitiesToDB(t *testing.T) {
+	cardID := "card-1"
+	loanID := "loan-1"
+	minimum := 35.0
+	due := "2026-04-15"
+	now := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	var accounts []plaid.Account
+	err := json.Unmarshal([]byte(`[{"account_id":"card-1","type":"credit","balances":{"current":1234.56,"limit":5000}}]`), &accounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := plaidLiabilitiesToDB(&plaid.Liabilities{
+		Credit: []plaid.CreditLiability{{
+			AccountID:            &cardID,
+			APRs:                 []plaid.CreditAPR{{APRPercentage: 27.49, APRType: "purchase_apr"}, {APRPercentage: 29.99, APRType: "cash_apr"}},
+			MinimumPaymentAmount: &minimum,
+			NextPaymentDueDate:   &due,
+		}},
+		Student: []plaid.StudentLoanLiability{{AccountID: &loanID, InterestRatePercentage: 5.5}},
+	}, accounts, now)
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	card, loan := rows[0], rows[1]
+
+	if card.CreditLimitCents == nil || *card.CreditLimitCents != 500000 {
+		t.Errorf("card CreditLimitCents = %v, want 500000", card.CreditLimitCents)
+	}
+	if card.InterestRatePercentage == nil || *card.InterestRatePercentage != 27.49 {
+		t.Errorf("card InterestRatePercentage = %v, want purchase APR 27.49", card.InterestRatePercentage)
+	}
+	if len(card.APRs) != 2 {
+		t.Errorf("card APRs = %d, want 2", len(card.APRs))
+	}
+	if card.MinimumPaymentCents == nil || *card.MinimumPaymentCents != 3500 {
+		t.Errorf("card MinimumPaymentCents = %v, want 3500", card.MinimumPaymentCents)
+	}
+	if card.NextPaymentDueDate == nil || card.NextPaymentDueDate.Format(dateLayout) != due {
+		t.Errorf("card NextPaymentDueDate = %v, want %s", card.NextPaymentDueDate, due)
+	}
+	if loan.LiabilityType != "student" || loan.InterestRatePercentage == nil || *loan.InterestRatePercentage != 5.5 {
+		t.Errorf("loan = %s at %v, want student at 5.5", loan.LiabilityType, loan.InterestRatePercentage)
+	}
+	if loan.APRs == nil {
+		t.Errorf("loan APRs is nil, want empty slice (aprs column is NOT NULL)")
+	}
+}
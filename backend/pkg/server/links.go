@@ -7,14 +7,16 @@ import (
 	"strings"
 
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/marketdata"
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
 )
 
 // Client dependencies for the link management routes.
 type apiDependencies struct {
-	db          *database.Client
-	plaidClient *plaid.Client
+	db            *database.Client
+	plaidClient   *plaid.Client
+	priceProvider marketdata.PriceProvider
 	// snaptradeClient *snaptrade.Client
 }
 
@@ -115,6 +117,15 @@ func registerLinkManagementRoutes(mux *http.ServeMux, deps apiDependencies) {
 		}
 		handleRemovePlaidItem(w, r, deps)
 	})))
+
+	// POST /api/plaid/items/{id}/resync replays an item's transaction history from an empty cursor.
+	mux.Handle("/api/plaid/items/{id}/resync", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleResyncPlaidItem(w, r, deps)
+	})))
 }
 
 // Creates a Plaid link token.
@@ -242,20 +253,7 @@ func handleExchangePlaidPublicToken(w http.ResponseWriter, r *http.Request, deps
 	// Build the Plaid accounts for the database.
 	var dbAccounts []database.PlaidAccount
 	for _, a := range accounts {
-		account := database.PlaidAccount{
-			PlaidItemID:    itemID,
-			AccountID:      a.AccountID,
-			Name:           a.Name,
-			Type:           a.Type,
-			CurrentBalance: a.Balances.Current,
-		}
-		if a.Mask != "" {
-			account.Mask = &a.Mask
-		}
-		if a.Subtype != "" {
-			account.Subtype = &a.Subtype
-		}
-		dbAccounts = append(dbAccounts, account)
+		dbAccounts = append(dbAccounts, plaidAccountToDB(itemID, a))
 	}
 
 	// Save accounts to DB, reconnecting will update existing accounts (upsert by account_id)
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Minimum days of daily holdings kept by the retention cron; HOLDINGS_DAILY_RETENTION_DAYS can raise it.
+const dailyHoldingsRetentionDays = 30
+
+// Returns time-weighted and money-weighted (XIRR) returns for the total portfolio, each investment account
+// and each holding over MTD, YTD, 1Y and since inception, or a custom from/to period.
+func handleGetPortfolioPerformance(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	now := GetLocalNow()
+	today := calendarDay(now)
+	periods, err := parsePerformancePeriods(r.URL.Query().Get("period"), r.URL.Query().Get("from"), r.URL.Query().Get("to"), today)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Loads the value history from every retention tier.
+	dailyStart := dailyHoldingsWindowStart(today)
+	yearly, err := deps.db.ListAllYearlyPortfolioSummaries(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list yearly portfolio summaries: "+err.Error())
+		return
+	}
+	monthly, err := deps.db.ListMonthlySnapshots(r.Context(), time.Date(2000, time.January, 1, 0, 0, 0, 0, GetLocalLocation()), today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list monthly snapshots: "+err.Error())
+		return
+	}
+	daily, err := deps.db.ListDailyHoldings(r.Context(), dailyStart, today)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list daily holdings: "+err.Error())
+		return
+	}
+	accountValues := buildAccountValuations(yearly, monthly, daily, dailyStart)
+	holdingValues := buildHoldingValuations(daily)
+	totalValues := sumValuations(accountValues)
+
+	resp := portfolioPerformanceResponse{AsOf: today.Format(dateLayout), Periods: []periodPerformanceJSON{}}
+	if len(totalValues) == 0 {
+		_ = json.NewEncoder(w).Encode(resp)
+		return
+	}
+	firstValuation := totalValues[0].Date
+
+	// Loads cash flows: investment activity, or deposits detected in the bank feed for accounts without any.
+	accountIDs := make([]string, 0, len(accountValues))
+	for accountID := range accountValues {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+	accountFlows, holdingFlows, hasActivity, err := loadCashFlows(r.Context(), deps.db, accountIDs, firstValuation)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	accountNames := loadAccountNames(r.Context(), deps.db)
+
+	var totalFlows []cashFlow
+	for accountID := range accountValues {
+		totalFlows = append(totalFlows, accountFlows[accountID]...)
+	}
+
+	// Sorts holdings so the output is stable.
+	holdingKeys := make([]holdingKey, 0, len(holdingValues))
+	for key := range holdingValues {
+		holdingKeys = append(holdingKeys, key)
+	}
+	sort.Slice(holdingKeys, func(i, j int) bool {
+		if holdingKeys[i].AccountID != holdingKeys[j].AccountID {
+			return holdingKeys[i].AccountID < holdingKeys[j].AccountID
+		}
+		return holdingKeys[i].Symbol < holdingKeys[j].Symbol
+	})
+
+	for _, period := range periods {
+		output := periodPerformanceJSON{
+			Period:   period.Key,
+			Accounts: []accountPerformanceJSON{},
+			Holdings: []holdingPerformanceJSON{},
+		}
+		if !period.Start.IsZero() {
+			output.RequestedStart = period.Start.Format(dateLayout)
+		}
+		if result, ok := periodReturn(totalValues, totalFlows, period.Start, period.End); ok {
+			output.Total = toPerformanceJSON(result)
+		}
+		for _, accountID := range accountIDs {
+			result, ok := periodReturn(accountValues[accountID], accountFlows[accountID], period.Start, period.End)
+			if !ok {
+				continue
+			}
+			source := "investment_transactions"
+			if !hasActivity[accountID] {
+				source = "detected_deposits"
+			}
+			output.Accounts = append(output.Accounts, accountPerformanceJSON{
+				AccountID:       accountID,
+				AccountName:     accountNames[accountID],
+				CashFlowSource:  source,
+				performanceJSON: toPerformanceJSON(result),
+			})
+		}
+		for _, key := range holdingKeys {
+			result, ok := periodReturn(holdingValues[key], holdingFlows[key], period.Start, period.End)
+			if !ok {
+				continue
+			}
+			output.Holdings = append(output.Holdings, holdingPerformanceJSON{
+				AccountID:       key.AccountID,
+				AccountName:     accountNames[key.AccountID],
+				Symbol:          key.Symbol,
+				performanceJSON: toPerformanceJSON(result),
+			})
+		}
+		resp.Periods = append(resp.Periods, output)
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("portfolio performance encode: %v", err)
+	}
+}
+
+// Loads cash flows since the date for the accounts: investment activity by account and holding, or, for
+// accounts without any, transfers detected in their transaction feed. hasActivity marks the former.
+func loadCashFlows(ctx context.Context, db *database.Client, accountIDs []string, since time.Time) (map[string][]cashFlow, map[holdingKey][]cashFlow, map[string]bool, error) {
+	investmentTransactions, err := db.ListInvestmentTransactionsSince(ctx, since)
+	if err != nil {
+		return nil, nil, nil, errors.New("failed to list investment transactions: " + err.Error())
+	}
+	accountFlows, holdingFlows := investmentCashFlows(investmentTransactions)
+	hasActivity := make(map[string]bool)
+	for _, transaction := range investmentTransactions {
+		hasActivity[transaction.AccountID] = true
+	}
+
+	detectIDs := make(map[string]bool)
+	var detectList []string
+	for _, accountID := range accountIDs {
+		if !hasActivity[accountID] {
+			detectIDs[accountID] = true
+			detectList = append(detectList, accountID)
+		}
+	}
+	if len(detectList) == 0 {
+		return accountFlows, holdingFlows, hasActivity, nil
+	}
+	bankTransactions, err := db.ListTransactionsForAccountsSince(ctx, detectList, since)
+	if err != nil {
+		return nil, nil, nil, errors.New("failed to list account transactions: " + err.Error())
+	}
+	categories, err := db.ListCategories(ctx)
+	if err != nil {
+		return nil, nil, nil, errors.New("failed to list categories: " + err.Error())
+	}
+	transferCategoryIDs := make(map[int64]bool)
+	for _, category := range categories {
+		if category.Name == "Transfer" || category.Name == "Investments" {
+			transferCategoryIDs[category.ID] = true
+		}
+	}
+	for accountID, flows := range detectedDepositFlows(bankTransactions, detectIDs, transferCategoryIDs) {
+		accountFlows[accountID] = flows
+	}
+	return accountFlows, holdingFlows, hasActivity, nil
+}
+
+// Returns the periods to measure. The start is the valuation date the period grows from (the close of the
+// prior month for MTD, of the prior year for YTD); a zero start means since inception.
+func parsePerformancePeriods(period, from, to string, today time.Time) ([]performancePeriod, error) {
+	if from != "" || to != "" {
+		start, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return nil, errors.New("from must be YYYY-MM-DD")
+		}
+		end := today
+		if to != "" {
+			end, err = time.Parse(dateLayout, to)
+			if err != nil {
+				return nil, errors.New("to must be YYYY-MM-DD")
+			}
+		}
+		if !end.After(start) {
+			return nil, errors.New("to must be after from")
+		}
+		return []performancePeriod{{Key: "custom", Start: start, End: end}}, nil
+	}
+
+	all := []performancePeriod{
+		{Key: "mtd", Start: time.Date(today.Year(), today.Month(), 0, 0, 0, 0, 0, time.UTC), End: today},
+		{Key: "ytd", Start: time.Date(today.Year()-1, time.December, 31, 0, 0, 0, 0, time.UTC), End: today},
+		{Key: "1y", Start: today.AddDate(-1, 0, 0), End: today},
+		{Key: "itd", End: today},
+	}
+	if period == "" || period == "all" {
+		return all, nil
+	}
+	for _, candidate := range all {
+		if candidate.Key == period {
+			return []performancePeriod{candidate}, nil
+		}
+	}
+	return nil, errors.New("period must be one of mtd, ytd, 1y, itd, all")
+}
+
+// Converts a period result into our API model, with rates as percentages.
+func toPerformanceJSON(result periodReturnResult) performanceJSON {
+	percent := func(rate *float64) *float64 {
+		if rate == nil || math.IsNaN(*rate) || math.IsInf(*rate, 0) {
+			return nil
+		}
+		value := math.Round(*rate*10000) / 100
+		return &value
+	}
+	return performanceJSON{
+		StartDate:                  result.StartDate.Format(dateLayout),
+		EndDate:                    result.EndDate.Format(dateLayout),
+		StartValueCents:            result.StartValueCents,
+		EndValueCents:              result.EndValueCents,
+		NetCashFlowCents:           result.NetCashFlowCents,
+		GainCents:                  result.GainCents,
+		TimeWeightedReturnPercent:  percent(result.TimeWeightedReturn),
+		MoneyWeightedReturnPercent: percent(result.MoneyWeightedReturn),
+		Partial:                    result.Partial,
+	}
+}
+
+// A period to measure returns over.
+type performancePeriod struct {
+	Key   string
+	Start time.Time
+	End   time.Time
+}
+
+// Returns over one period for API. Partial means the history starts after the requested start.
+type performanceJSON struct {
+	StartDate                  string   `json:"startDate"`
+	EndDate                    string   `json:"endDate"`
+	StartValueCents            int64    `json:"startValueCents"`
+	EndValueCents              int64    `json:"endValueCents"`
+	NetCashFlowCents           int64    `json:"netCashFlowCents"`
+	GainCents                  int64    `json:"gainCents"`
+	TimeWeightedReturnPercent  *float64 `json:"timeWeightedReturnPercent"`
+	MoneyWeightedReturnPercent *float64 `json:"moneyWeightedReturnPercent"`
+	Partial                    bool     `json:"partial"`
+}
+
+// Account returns for API.
+type accountPerformanceJSON struct {
+	AccountID      string `json:"accountId"`
+	AccountName    string `json:"accountName,omitempty"`
+	CashFlowSource string `json:"cashFlowSource"`
+	performanceJSON
+}
+
+// Holding returns for API.
+type holdingPerformanceJSON struct {
+	AccountID   string `json:"accountId"`
+	AccountName string `json:"accountName,omitempty"`
+	Symbol      string `json:"symbol"`
+	performanceJSON
+}
+
+// Returns for one period.
+type periodPerformanceJSON struct {
+	Period         string                   `json:"period"`
+	RequestedStart string                   `json:"requestedStart,omitempty"`
+	Total          performanceJSON          `json:"total"`
+	Accounts       []accountPerformanceJSON `json:"accounts"`
+	Holdings       []holdingPerformanceJSON `json:"holdings"`
+}
+
+// Portfolio performance response.
+type portfolioPerformanceResponse struct {
+	AsOf    string                  `json:"asOf"`
+	Periods []periodPerformanceJSON `json:"periods"`
+}
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -35,11 +37,15 @@ type HoldingsResponse struct {
 type SnapshotDataPoint struct {
 	Date                string `json:"date"`
 	PortfolioValueCents int64  `json:"portfolioValueCents"`
+	// Set on days without a snapshot, whose value is carried forward from the previous day.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // Holding data point for charts in JSON format.
 type HoldingDataPoint struct {
-	Date           string  `json:"date"`
+	Date string `json:"date"`
+	// The tier the point comes from: daily, weekly or monthly.
+	Tier           string  `json:"tier,omitempty"`
 	AccountID      string  `json:"accountId"`
 	AccountName    string  `json:"accountName,omitempty"`
 	Symbol         string  `json:"symbol"`
@@ -56,6 +62,7 @@ type SnapshotsResponse struct {
 
 // Portfolio holdings history in JSON format.
 type HoldingsHistoryResponse struct {
+	// Daily rows, preceded by weekly and monthly rollups for days whose daily rows were pruned.
 	Daily []HoldingDataPoint `json:"daily"`
 }
 
@@ -100,6 +107,16 @@ func registerPortfolioRoutes(mux *http.ServeMux, deps apiDependencies) {
 		handleGetHoldingsHistory(w, r, deps)
 	})))
 
+	// GET /api/portfolio/holdings/consolidated returns holdings grouped by security across accounts, with a
+	// per-account breakdown and daily history; symbol limits it to one security.
+	mux.Handle("/api/portfolio/holdings/consolidated", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetConsolidatedHoldings(w, r, deps)
+	})))
+
 	// GET /api/portfolio/summary/yearly returns yearly portfolio summary by account (end-of-year value per account).
 	mux.Handle("/api/portfolio/summary/yearly", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -108,6 +125,93 @@ func registerPortfolioRoutes(mux *http.ServeMux, deps apiDependencies) {
 		}
 		handleGetYearlyPortfolioSummary(w, r, deps)
 	})))
+
+	// GET /api/portfolio/activity returns investment transactions, filterable by accountId, symbol, type, from and to.
+	mux.Handle("/api/portfolio/activity", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetPortfolioActivity(w, r, deps)
+	})))
+
+	// GET /api/portfolio/performance returns time-weighted and money-weighted returns by period, filterable by period or from/to.
+	mux.Handle("/api/portfolio/performance", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetPortfolioPerformance(w, r, deps)
+	})))
+
+	// GET /api/portfolio/gains returns unrealized gain/loss and day-over-day change per holding, per account and overall.
+	mux.Handle("/api/portfolio/gains", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetPortfolioGains(w, r, deps)
+	})))
+
+	// GET /api/portfolio/income?months= returns dividend, interest and capital gain income by month, symbol and
+	// account, trailing 12-month yield on cost and a 12-month dividend projection.
+	mux.Handle("/api/portfolio/income", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetPortfolioIncome(w, r, deps)
+	})))
+
+	// GET /api/portfolio/attribution?from=&to= splits each month's change in portfolio value and net worth into
+	// contributions, withdrawals and market gain or loss.
+	mux.Handle("/api/portfolio/attribution", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetPortfolioAttribution(w, r, deps)
+	})))
+
+	// GET /api/portfolio/allocation returns current vs target weights by asset class and suggested rebalancing
+	// trades, with optional tolerance, newCashCents and cashOnly parameters.
+	mux.Handle("/api/portfolio/allocation", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetPortfolioAllocation(w, r, deps)
+	})))
+
+	// GET/PUT /api/portfolio/allocation/targets reads or replaces the target allocation.
+	mux.Handle("/api/portfolio/allocation/targets", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetAllocationTargets(w, r, deps)
+		case http.MethodPut:
+			handleUpdateAllocationTargets(w, r, deps)
+		default:
+			methodNotAllowed(w, http.MethodGet)
+		}
+	})))
+
+	// GET /api/portfolio/securities returns each security's Plaid type and asset class.
+	mux.Handle("/api/portfolio/securities", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetSecurities(w, r, deps)
+	})))
+
+	// PUT /api/portfolio/securities/{symbol}/asset-class sets or clears a symbol's asset class override.
+	mux.Handle("/api/portfolio/securities/{symbol}/asset-class", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			methodNotAllowed(w, http.MethodPut)
+			return
+		}
+		handleUpdateSecurityAssetClass(w, r, deps)
+	})))
 }
 
 // Fetches current holdings from Plaid.
@@ -245,17 +349,26 @@ func handleGetSnapshots(w http.ResponseWriter, r *http.Request, deps apiDependen
 		return
 	}
 
-	// Get necessary dates and parameters.
-	accountID := r.URL.Query().Get("accountId")
-	now := GetLocalNow()
+	resp, err := loadSnapshotSeries(r.Context(), deps.db, r.URL.Query().Get("accountId"), GetLocalNow())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Builds the daily (last 30 days) and monthly (since January two years ago) value series for one account,
+// or summed across accounts when accountID is empty.
+func loadSnapshotSeries(ctx context.Context, db *database.Client, accountID string, now time.Time) (SnapshotsResponse, error) {
+	// Get necessary dates.
 	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, GetLocalLocation())
 	dailyStart := dayStart.AddDate(0, 0, -30)
 
 	// List the daily snapshots.
-	dailySnapshots, err := deps.db.ListDailySnapshots(r.Context(), dailyStart, dayStart)
+	dailySnapshots, err := db.ListDailySnapshots(ctx, dailyStart, dayStart)
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "failed to list daily snapshots: "+err.Error())
-		return
+		return SnapshotsResponse{}, fmt.Errorf("failed to list daily snapshots: %w", err)
 	}
 
 	// List the monthly snapshots
@@ -263,10 +376,9 @@ func handleGetSnapshots(w http.ResponseWriter, r *http.Request, deps apiDependen
 	monthlyPoints := make([]SnapshotDataPoint, 0)
 	if accountID != "" {
 		// List the monthly snapshots for a given account.
-		byAccount, err := deps.db.ListMonthlySnapshotsByAccount(r.Context(), monthlyStart, dayStart, accountID)
+		byAccount, err := db.ListMonthlySnapshotsByAccount(ctx, monthlyStart, dayStart, accountID)
 		if err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to list monthly snapshots by account: "+err.Error())
-			return
+			return SnapshotsResponse{}, fmt.Errorf("failed to list monthly snapshots by account: %w", err)
 		}
 		// Convert the monthly snapshots to the data points.
 		monthlyPoints = make([]SnapshotDataPoint, 0, len(byAccount))
@@ -278,10 +390,9 @@ func handleGetSnapshots(w http.ResponseWriter, r *http.Request, deps apiDependen
 		}
 	} else {
 		// List the total monthly snapshots across all accounts and aggregate them.
-		allMonthlySnapshots, err := deps.db.ListMonthlySnapshots(r.Context(), monthlyStart, dayStart)
+		allMonthlySnapshots, err := db.ListMonthlySnapshots(ctx, monthlyStart, dayStart)
 		if err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "failed to list monthly snapshots: "+err.Error())
-			return
+			return SnapshotsResponse{}, fmt.Errorf("failed to list monthly snapshots: %w", err)
 		}
 		monthlySum := make(map[string]int64)
 		for _, snapshot := range allMonthlySnapshots {
@@ -299,7 +410,7 @@ func handleGetSnapshots(w http.ResponseWriter, r *http.Request, deps apiDependen
 	dailyPoints := make([]SnapshotDataPoint, 0)
 	if accountID != "" {
 		// Get the daily holdings for each account.
-		recentHoldings, _ := deps.db.ListDailyHoldingsByAccount(r.Context(), accountID, dailyStart, dayStart)
+		recentHoldings, _ := db.ListDailyHoldingsByAccount(ctx, accountID, dailyStart, dayStart)
 		holdingsByDate := make(map[string]int64)
 		for _, holding := range recentHoldings {
 			dateStr := holding.Date.Format(dateLayout)
@@ -323,20 +434,7 @@ func handleGetSnapshots(w http.ResponseWriter, r *http.Request, deps apiDependen
 			snapshotMap[snapshot.Date.Format(dateLayout)] = snapshot.PortfolioValueCents
 		}
 
-		var lastValue int64
-		for d := dailyStart; !d.After(dayStart); d = d.AddDate(0, 0, 1) {
-			dateStr := d.Format(dateLayout)
-			if val, ok := snapshotMap[dateStr]; ok {
-				lastValue = val
-			}
-			// Only add the data point if the last value is greater than 0.
-			if lastValue > 0 {
-				dailyPoints = append(dailyPoints, SnapshotDataPoint{
-					Date:                dateStr,
-					PortfolioValueCents: lastValue,
-				})
-			}
-		}
+		dailyPoints = carryForwardDailyPoints(snapshotMap, dailyStart, dayStart)
 	}
 
 	// Include the current month in the monthly chart even if it hasn't ended yet.
@@ -360,12 +458,33 @@ func handleGetSnapshots(w http.ResponseWriter, r *http.Request, deps apiDependen
 
 	sortSnapshotDataPoints(monthlyPoints)
 
-	resp := SnapshotsResponse{
+	return SnapshotsResponse{
 		Daily:   dailyPoints,
 		Monthly: monthlyPoints,
-	}
+	}, nil
+}
 
-	_ = json.NewEncoder(w).Encode(resp)
+// Returns a point for every day from start to end (inclusive), carrying the last snapshot value forward over
+// days without one. Carried-forward points are marked estimated; days before the first value are left out.
+func carryForwardDailyPoints(snapshotMap map[string]int64, start, end time.Time) []SnapshotDataPoint {
+	points := make([]SnapshotDataPoint, 0)
+	var lastValue int64
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format(dateLayout)
+		val, ok := snapshotMap[dateStr]
+		if ok {
+			lastValue = val
+		}
+		// Only add the data point if the last value is greater than 0.
+		if lastValue > 0 {
+			points = append(points, SnapshotDataPoint{
+				Date:                dateStr,
+				PortfolioValueCents: lastValue,
+				Estimated:           !ok,
+			})
+		}
+	}
+	return points
 }
 
 // Sorts the snapshot data points by date.
@@ -373,7 +492,8 @@ func sortSnapshotDataPoints(points []SnapshotDataPoint) {
 	sort.Slice(points, func(i, j int) bool { return points[i].Date < points[j].Date })
 }
 
-// Fetches daily holdings history.
+// Fetches holdings history between from and to (default: the last 30 days), stitching in weekly and monthly
+// rollups for days whose daily rows have been pruned.
 func handleGetHoldingsHistory(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -391,71 +511,75 @@ func handleGetHoldingsHistory(w http.ResponseWriter, r *http.Request, deps apiDe
 	// Get the account ID and symbol from the query parameters.
 	accountID := r.URL.Query().Get("accountId")
 	symbol := r.URL.Query().Get("symbol")
+	if accountID != "" {
+		symbol = ""
+	}
 
-	now := GetLocalNow()
-	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, GetLocalLocation())
-	dailyStart := dayStart.AddDate(0, 0, -30)
+	today := calendarDay(GetLocalNow())
+	from, to, err := parseHoldingsHistoryRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"), today)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var dailyHoldings []database.DailyHolding
-	var err error
-
 	if accountID != "" {
-		dailyHoldings, err = deps.db.ListDailyHoldingsByAccount(r.Context(), accountID, dailyStart, dayStart)
+		dailyHoldings, err = deps.db.ListDailyHoldingsByAccount(r.Context(), accountID, from, to)
 	} else if symbol != "" {
-		dailyHoldings, err = deps.db.ListDailyHoldingsBySymbol(r.Context(), symbol, dailyStart, dayStart)
+		dailyHoldings, err = deps.db.ListDailyHoldingsBySymbol(r.Context(), symbol, from, to)
 	} else {
-		dailyHoldings, err = deps.db.ListDailyHoldings(r.Context(), dailyStart, dayStart)
+		dailyHoldings, err = deps.db.ListDailyHoldings(r.Context(), from, to)
 	}
-
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "failed to list daily holdings: "+err.Error())
 		return
 	}
 
-	// Fetch Plaid accounts to get account names.
-	plaidAccounts, _ := deps.db.ListPlaidAccounts(r.Context())
-	accountMap := make(map[string]string)
-	for _, account := range plaidAccounts {
-		accountMap[account.AccountID] = account.Name
-	}
-	// Group holdings by date for easier iteration.
-	holdingsByDate := make(map[string][]database.DailyHolding)
-	for _, holding := range dailyHoldings {
-		dateStr := holding.Date.Format(dateLayout)
-		holdingsByDate[dateStr] = append(holdingsByDate[dateStr], holding)
-	}
-
-	dailyPoints := make([]HoldingDataPoint, 0)
-
-	// Iterate through the daily holdings and add them to the daily points.
-	for d := dailyStart; !d.After(dayStart); d = d.AddDate(0, 0, 1) {
-		dateStr := d.Format(dateLayout)
-
-		if newHoldings, ok := holdingsByDate[dateStr]; ok {
-			for _, nh := range newHoldings {
-				if nh.Quantity == 0 {
-					continue
-				}
-				dailyPoints = append(dailyPoints, HoldingDataPoint{
-					Date:           dateStr,
-					AccountID:      nh.AccountID,
-					AccountName:    accountMap[nh.AccountID],
-					Symbol:         nh.Symbol,
-					Quantity:       nh.Quantity,
-					ValueCents:     nh.ValueCents,
-					CostBasisCents: nh.CostBasisCents,
-				})
-			}
+	// Reads the rollups only when the range reaches before the daily tier.
+	var weekly, monthly []database.HoldingRollup
+	if from.Before(holdingsDailyCutoff(today, holdingsRetentionPolicyFromEnv())) {
+		weekly, err = deps.db.ListHoldingRollups(r.Context(), holdingsTierWeekly, from, to, accountID, symbol)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to list weekly holdings: "+err.Error())
+			return
+		}
+		monthly, err = deps.db.ListHoldingRollups(r.Context(), holdingsTierMonthly, from, to, accountID, symbol)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to list monthly holdings: "+err.Error())
+			return
 		}
 	}
 
 	resp := HoldingsHistoryResponse{
-		Daily: dailyPoints,
+		Daily: stitchHoldingsHistory(dailyHoldings, weekly, monthly, loadAccountNames(r.Context(), deps.db)),
 	}
 
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// Parses the holdings history range. to defaults to today and from to 30 days before it.
+func parseHoldingsHistoryRange(fromValue, toValue string, today time.Time) (time.Time, time.Time, error) {
+	to := today
+	var err error
+	if toValue != "" {
+		to, err = time.Parse(dateLayout, toValue)
+		if err != nil {
+			return to, to, errors.New("to must be YYYY-MM-DD")
+		}
+	}
+	from := to.AddDate(0, 0, -dailyHoldingsRetentionDays)
+	if fromValue != "" {
+		from, err = time.Parse(dateLayout, fromValue)
+		if err != nil {
+			return from, to, errors.New("from must be YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+	return from, to, nil
+}
+
 // Returns yearly portfolio summary by account (end-of-year value per account).
 func handleGetYearlyPortfolioSummary(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
 	w.Header().Set("Content-Type", "application/json")
@@ -102,3 +102,24 @@ func TestBuildPortfolioSnapshotsCSVIncludesHeadersAndRows(t *testing.T) {
 		t.Fatalf("unexpected data row: %#v", row)
 	}
 }
+
+// Test that days without a snapshot carry the previous value forward and are marked estimated.
+func TestCarryForwardDailyPointsMarksEstimated(t *testing.T) {
+	snapshots := map[string]int64{"2026-10-02": 100000, "2026-10-05": 105000}
+
+	got := carryForwardDailyPoints(snapshots, utcDay(2026, 10, 1), utcDay(2026, 10, 5))
+	want := []SnapshotDataPoint{
+		{Date: "2026-10-02", PortfolioValueCents: 100000},
+		{Date: "2026-10-03", PortfolioValueCents: 100000, Estimated: true},
+		{Date: "2026-10-04", PortfolioValueCents: 100000, Estimated: true},
+		{Date: "2026-10-05", PortfolioValueCents: 105000},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("points = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
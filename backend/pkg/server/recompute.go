@@ -0,0 +1,436 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Longest range one recompute request may cover.
+const maxRecomputeYears = 5
+
+// Registers the recompute routes.
+func registerRecomputeRoutes(mux *http.ServeMux, deps apiDependencies) {
+	// POST /api/portfolio/recompute?from=&to=&dryRun= rebuilds daily totals, month-end snapshots, monthly net
+	// worth and yearly summaries for the range from the stored holdings.
+	mux.Handle("/api/portfolio/recompute", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleRecompute(w, r, deps)
+	})))
+}
+
+// Recomputes the portfolio rollups for a date range and reports each row that changed. With dryRun=true
+// nothing is written.
+func handleRecompute(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	from, to, err := parseRecomputeRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	today := calendarDay(GetLocalNow())
+	input, err := loadRecomputeInput(r.Context(), deps.db, from, to)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	plan := planRecompute(input, from, to, today)
+
+	resp := recomputeResponse{From: from.Format(dateLayout), To: to.Format(dateLayout), DryRun: dryRun, recomputePlan: plan}
+	if !dryRun {
+		err = applyRecompute(r.Context(), deps.db, plan)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("recompute encode: %v", err)
+	}
+}
+
+// Parses the required recompute range.
+func parseRecomputeRange(fromValue, toValue string) (time.Time, time.Time, error) {
+	from, err := time.Parse(dateLayout, fromValue)
+	if err != nil {
+		return from, from, errors.New("from must be YYYY-MM-DD")
+	}
+	to, err := time.Parse(dateLayout, toValue)
+	if err != nil {
+		return from, to, errors.New("to must be YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+	if to.After(from.AddDate(maxRecomputeYears, 0, 0)) {
+		return from, to, fmt.Errorf("range must not exceed %d years", maxRecomputeYears)
+	}
+	return from, to, nil
+}
+
+// Loads the holdings in the range and the rollups built from them. Monthly snapshots cover whole years so
+// yearly summaries can be rebuilt.
+func loadRecomputeInput(ctx context.Context, db *database.Client, from, to time.Time) (recomputeInput, error) {
+	var input recomputeInput
+	var err error
+	input.Daily, err = db.ListDailyHoldings(ctx, from, to)
+	if err != nil {
+		return input, errors.New("failed to list daily holdings: " + err.Error())
+	}
+	input.Accounts, err = db.ListPlaidAccounts(ctx)
+	if err != nil {
+		return input, errors.New("failed to list accounts: " + err.Error())
+	}
+	input.DailySnapshots, err = db.ListDailySnapshots(ctx, from, to)
+	if err != nil {
+		return input, errors.New("failed to list daily snapshots: " + err.Error())
+	}
+	yearStart := time.Date(from.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(to.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+	input.Monthly, err = db.ListMonthlySnapshots(ctx, yearStart, yearEnd)
+	if err != nil {
+		return input, errors.New("failed to list monthly snapshots: " + err.Error())
+	}
+	input.NetWorth, err = db.ListMonthlyNetWorth(ctx, time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC), monthEnd(to))
+	if err != nil {
+		return input, errors.New("failed to list monthly net worth: " + err.Error())
+	}
+	for year := from.Year(); year <= to.Year(); year++ {
+		summaries, err := db.ListYearlyPortfolioSummaries(ctx, year)
+		if err != nil {
+			return input, errors.New("failed to list yearly portfolio summaries: " + err.Error())
+		}
+		input.Yearly = append(input.Yearly, summaries...)
+	}
+	return input, nil
+}
+
+// Works out which rollups differ from what the holdings imply:
+//   - each day's total across accounts, for days in the range with holdings; days where an investment account
+//     that existed has no holdings are skipped, since only its current balance is known;
+//   - each account's month-end value, for month ends in the range with holdings (existing rows for the month
+//     are updated in place, whatever day of the month they're dated);
+//   - monthly net worth for months in the range, with investments set to the month's snapshot total; cash and
+//     liabilities aren't kept historically, so months without a net worth row are skipped;
+//   - each completed year's summary, from every account's latest monthly snapshot that year.
+//
+// Rows that already match are left out, so applying the plan again changes nothing.
+func planRecompute(input recomputeInput, from, to, today time.Time) recomputePlan {
+	plan := recomputePlan{
+		Daily:    []dailyTotalChangeJSON{},
+		Monthly:  []monthlySnapshotChangeJSON{},
+		NetWorth: []netWorthChangeJSON{},
+		Yearly:   []yearlySummaryChangeJSON{},
+		Skipped:  []string{},
+	}
+
+	// Sums the holdings by day and by account and day.
+	holdingsByDate := make(map[time.Time][]database.DailyHolding)
+	for _, holding := range input.Daily {
+		date := calendarDay(holding.Date.Time)
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+		holdingsByDate[date] = append(holdingsByDate[date], holding)
+	}
+	// Investment accounts without holdings on a day are not filled in with today's balance; those days' totals
+	// and the accounts' monthly rows are left as stored.
+	dayTotals := make(map[time.Time]int64, len(holdingsByDate))
+	accountDayTotals := make(map[time.Time]map[string]int64, len(holdingsByDate))
+	incompleteDays := make(map[time.Time]bool)
+	daysWithoutHoldings := make(map[string]int)
+	for date, holdings := range holdingsByDate {
+		dayTotals[date], accountDayTotals[date] = holdingsDayTotals(holdings)
+		for _, account := range accountsWithoutHoldings(accountDayTotals[date], input.Accounts, date) {
+			incompleteDays[date] = true
+			daysWithoutHoldings[account.AccountID]++
+		}
+	}
+	missingAccountIDs := make([]string, 0, len(daysWithoutHoldings))
+	for accountID := range daysWithoutHoldings {
+		missingAccountIDs = append(missingAccountIDs, accountID)
+	}
+	sort.Strings(missingAccountIDs)
+	for _, accountID := range missingAccountIDs {
+		plan.Skipped = append(plan.Skipped, fmt.Sprintf("account %s: no holdings on %d day(s) in the range; its current balance isn't applied to past dates, so those days' totals are left as stored", accountID, daysWithoutHoldings[accountID]))
+	}
+
+	// Daily totals.
+	existingDaily := make(map[time.Time]int64, len(input.DailySnapshots))
+	for _, snapshot := range input.DailySnapshots {
+		existingDaily[calendarDay(snapshot.Date.Time)] = snapshot.PortfolioValueCents
+	}
+	for _, date := range sortedDates(dayTotals) {
+		if incompleteDays[date] {
+			continue
+		}
+		total := dayTotals[date]
+		previous, ok := existingDaily[date]
+		if ok && previous == total {
+			continue
+		}
+		change := dailyTotalChangeJSON{Date: date.Format(dateLayout), NewValueCents: total}
+		if ok {
+			change.OldValueCents = &previous
+		}
+		plan.Daily = append(plan.Daily, change)
+	}
+
+	// Month-end snapshots, keyed by the first of the month so rows dated either way match.
+	type monthAccount struct {
+		Month     time.Time
+		AccountID string
+	}
+	monthly := make(map[monthAccount]database.MonthlySnapshot, len(input.Monthly))
+	for _, snapshot := range input.Monthly {
+		monthly[monthAccount{Month: monthStart(snapshot.Month.Time), AccountID: snapshot.AccountID}] = snapshot
+	}
+	for _, date := range sortedDates(dayTotals) {
+		if !date.Equal(monthEnd(date)) {
+			continue
+		}
+		accountTotals := accountDayTotals[date]
+		accountIDs := make([]string, 0, len(accountTotals))
+		for accountID := range accountTotals {
+			accountIDs = append(accountIDs, accountID)
+		}
+		sort.Strings(accountIDs)
+		for _, accountID := range accountIDs {
+			key := monthAccount{Month: monthStart(date), AccountID: accountID}
+			existing, ok := monthly[key]
+			total := accountTotals[accountID]
+			if ok && existing.PortfolioValueCents == total {
+				continue
+			}
+			change := monthlySnapshotChangeJSON{Month: date.Format(dateLayout), AccountID: accountID, NewValueCents: total}
+			if ok {
+				change.Month = calendarDay(existing.Month.Time).Format(dateLayout)
+				previous := existing.PortfolioValueCents
+				change.OldValueCents = &previous
+			}
+			plan.Monthly = append(plan.Monthly, change)
+			month, _ := time.Parse(dateLayout, change.Month)
+			monthly[key] = database.MonthlySnapshot{Month: database.DateOnly{Time: month}, AccountID: accountID, PortfolioValueCents: total}
+		}
+	}
+
+	// Monthly net worth.
+	monthTotals := make(map[time.Time]int64)
+	for key, snapshot := range monthly {
+		monthTotals[key.Month] += snapshot.PortfolioValueCents
+	}
+	for _, row := range input.NetWorth {
+		month := monthStart(row.Month.Time)
+		if monthEnd(month).Before(from) || month.After(to) {
+			continue
+		}
+		investments, ok := monthTotals[month]
+		if !ok || investments == row.InvestmentsCents {
+			continue
+		}
+		plan.NetWorth = append(plan.NetWorth, netWorthChangeJSON{
+			Month:               calendarDay(row.Month.Time).Format(dateLayout),
+			CashCents:           row.CashCents,
+			LiabilitiesCents:    row.LiabilitiesCents,
+			OldInvestmentsCents: row.InvestmentsCents,
+			NewInvestmentsCents: investments,
+			OldNetWorthCents:    row.NetWorthCents,
+			NewNetWorthCents:    row.CashCents + investments - row.LiabilitiesCents,
+		})
+	}
+	netWorthMonths := make(map[time.Time]bool, len(input.NetWorth))
+	for _, row := range input.NetWorth {
+		netWorthMonths[monthStart(row.Month.Time)] = true
+	}
+	for _, month := range sortedDates(monthTotals) {
+		if !netWorthMonths[month] && !monthEnd(month).Before(from) && !month.After(to) && monthEnd(month).Before(today) {
+			plan.Skipped = append(plan.Skipped, "monthly net worth for "+month.Format("2006-01")+": no existing row to take cash and liabilities from")
+		}
+	}
+
+	// Yearly summaries for completed years.
+	existingYearly := make(map[int]map[string]int64)
+	for _, summary := range input.Yearly {
+		if existingYearly[summary.Year] == nil {
+			existingYearly[summary.Year] = make(map[string]int64)
+		}
+		existingYearly[summary.Year][summary.AccountID] = summary.PortfolioValueCents
+	}
+	for year := from.Year(); year <= to.Year(); year++ {
+		if year >= today.Year() {
+			continue
+		}
+		latest := make(map[string]database.MonthlySnapshot)
+		for key, snapshot := range monthly {
+			if key.Month.Year() != year {
+				continue
+			}
+			if current, ok := latest[key.AccountID]; !ok || key.Month.After(monthStart(current.Month.Time)) {
+				latest[key.AccountID] = snapshot
+			}
+		}
+		if len(latest) == 0 {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("yearly summary for %d: no monthly snapshots left for the year", year))
+			continue
+		}
+		accountIDs := make([]string, 0, len(latest))
+		for accountID := range latest {
+			accountIDs = append(accountIDs, accountID)
+		}
+		sort.Strings(accountIDs)
+		for _, accountID := range accountIDs {
+			total := latest[accountID].PortfolioValueCents
+			previous, ok := existingYearly[year][accountID]
+			if ok && previous == total {
+				continue
+			}
+			change := yearlySummaryChangeJSON{Year: year, AccountID: accountID, NewValueCents: total}
+			if ok {
+				change.OldValueCents = &previous
+			}
+			plan.Yearly = append(plan.Yearly, change)
+		}
+	}
+	return plan
+}
+
+// Writes the planned rows.
+func applyRecompute(ctx context.Context, db *database.Client, plan recomputePlan) error {
+	for _, change := range plan.Daily {
+		date, _ := time.Parse(dateLayout, change.Date)
+		err := db.UpsertDailySnapshot(ctx, &database.DailySnapshot{Date: database.DateOnly{Time: date}, PortfolioValueCents: change.NewValueCents})
+		if err != nil {
+			return errors.New("failed to upsert daily snapshot: " + err.Error())
+		}
+	}
+	for _, change := range plan.Monthly {
+		month, _ := time.Parse(dateLayout, change.Month)
+		err := db.UpsertMonthlySnapshot(ctx, &database.MonthlySnapshot{Month: database.DateOnly{Time: month}, AccountID: change.AccountID, PortfolioValueCents: change.NewValueCents})
+		if err != nil {
+			return errors.New("failed to upsert monthly snapshot: " + err.Error())
+		}
+	}
+	for _, change := range plan.NetWorth {
+		month, _ := time.Parse(dateLayout, change.Month)
+		err := db.UpsertMonthlyNetWorth(ctx, &database.MonthlyNetWorth{
+			Month:            database.DateOnly{Time: month},
+			NetWorthCents:    change.NewNetWorthCents,
+			CashCents:        change.CashCents,
+			InvestmentsCents: change.NewInvestmentsCents,
+			LiabilitiesCents: change.LiabilitiesCents,
+		})
+		if err != nil {
+			return errors.New("failed to upsert monthly net worth: " + err.Error())
+		}
+	}
+	for _, change := range plan.Yearly {
+		err := db.UpsertYearlyPortfolioSummary(ctx, &database.YearlyPortfolioSummary{Year: change.Year, AccountID: change.AccountID, PortfolioValueCents: change.NewValueCents})
+		if err != nil {
+			return errors.New("failed to upsert yearly portfolio summary: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// Returns the map's dates in order.
+func sortedDates[V any](byDate map[time.Time]V) []time.Time {
+	dates := make([]time.Time, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates
+}
+
+// Returns the first day of the date's month.
+func monthStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Returns the last day of the date's month.
+func monthEnd(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Stored rows a recompute reads.
+type recomputeInput struct {
+	Daily          []database.DailyHolding
+	Accounts       []database.PlaidAccount
+	DailySnapshots []database.DailySnapshot
+	Monthly        []database.MonthlySnapshot
+	NetWorth       []database.MonthlyNetWorth
+	Yearly         []database.YearlyPortfolioSummary
+}
+
+// Rows a recompute changes.
+type recomputePlan struct {
+	Daily    []dailyTotalChangeJSON      `json:"dailySnapshots"`
+	Monthly  []monthlySnapshotChangeJSON `json:"monthlySnapshots"`
+	NetWorth []netWorthChangeJSON        `json:"monthlyNetWorth"`
+	Yearly   []yearlySummaryChangeJSON   `json:"yearlySummaries"`
+	Skipped  []string                    `json:"skipped"`
+}
+
+// Recompute response.
+type recomputeResponse struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dryRun"`
+	recomputePlan
+}
+
+// A changed daily total for API. OldValueCents is omitted for new rows.
+type dailyTotalChangeJSON struct {
+	Date          string `json:"date"`
+	OldValueCents *int64 `json:"oldValueCents,omitempty"`
+	NewValueCents int64  `json:"newValueCents"`
+}
+
+// A changed month-end account snapshot for API.
+type monthlySnapshotChangeJSON struct {
+	Month         string `json:"month"`
+	AccountID     string `json:"accountId"`
+	OldValueCents *int64 `json:"oldValueCents,omitempty"`
+	NewValueCents int64  `json:"newValueCents"`
+}
+
+// A changed monthly net worth row for API.
+type netWorthChangeJSON struct {
+	Month               string `json:"month"`
+	CashCents           int64  `json:"cashCents"`
+	LiabilitiesCents    int64  `json:"liabilitiesCents"`
+	OldInvestmentsCents int64  `json:"oldInvestmentsCents"`
+	NewInvestmentsCents int64  `json:"newInvestmentsCents"`
+	OldNetWorthCents    int64  `json:"oldNetWorthCents"`
+	NewNetWorthCents    int64  `json:"newNetWorthCents"`
+}
+
+// A changed yearly account summary for API.
+type yearlySummaryChangeJSON struct {
+	Year          int    `json:"year"`
+	AccountID     string `json:"accountId"`
+	OldValueCents *int64 `json:"oldValueCents,omitempty"`
+	NewValueCents int64  `json:"newValueCents"`
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestPlanRecompute(t *testing.T) {
+	holding := func(account string, year, month, day int, cents int64) database.DailyHolding {
+		return database.DailyHolding{Date: database.DateOnly{Time: utcDay(year, time.Month(month), day)}, AccountID: account, Symbol: "VTI", ValueCents: cents}
+	}
+	input := recomputeInput{
+		Daily: []database.DailyHolding{
+			// A late Fidelity statement added the December 31 holdings.
+			holding("brokerage", 2025, 12, 30, 100000),
+			holding("brokerage", 2025, 12, 31, 101000),
+			holding("fidelity", 2025, 12, 31, 50000),
+		},
+		DailySnapshots: []database.DailySnapshot{
+			{Date: database.DateOnly{Time: utcDay(2025, 12, 30)}, PortfolioValueCents: 100000},
+			{Date: database.DateOnly{Time: utcDay(2025, 12, 31)}, PortfolioValueCents: 101000},
+		},
+		Monthly: []database.MonthlySnapshot{
+			{Month: database.DateOnly{Time: utcDay(2025, 11, 30)}, AccountID: "brokerage", PortfolioValueCents: 95000},
+			// Dated the first of the month by the retention job.
+			{Month: database.DateOnly{Time: utcDay(2025, 12, 1)}, AccountID: "brokerage", PortfolioValueCents: 101000},
+		},
+		NetWorth: []database.MonthlyNetWorth{
+			{Month: database.DateOnly{Time: utcDay(2025, 12, 31)}, NetWorthCents: 111000, CashCents: 20000, InvestmentsCents: 101000, LiabilitiesCents: 10000},
+		},
+		Yearly: []database.YearlyPortfolioSummary{{Year: 2025, AccountID: "brokerage", PortfolioValueCents: 101000}},
+	}
+
+	plan := planRecompute(input, utcDay(2025, 12, 1), utcDay(2025, 12, 31), utcDay(2026, 10, 18))
+	if len(plan.Daily) != 1 || plan.Daily[0].Date != "2025-12-31" || *plan.Daily[0].OldValueCents != 101000 || plan.Daily[0].NewValueCents != 151000 {
+		t.Errorf("daily = %+v, want 2025-12-31 101000 -> 151000", plan.Daily)
+	}
+	if len(plan.Monthly) != 1 || plan.Monthly[0].AccountID != "fidelity" || plan.Monthly[0].Month != "2025-12-31" || plan.Monthly[0].OldValueCents != nil {
+		t.Errorf("monthly = %+v, want a new fidelity row for 2025-12-31", plan.Monthly)
+	}
+	if len(plan.NetWorth) != 1 || plan.NetWorth[0].NewInvestmentsCents != 151000 || plan.NetWorth[0].NewNetWorthCents != 161000 {
+		t.Errorf("net worth = %+v, want investments 151000 and net worth 161000", plan.NetWorth)
+	}
+	if len(plan.Yearly) != 1 || plan.Yearly[0].AccountID != "fidelity" || plan.Yearly[0].NewValueCents != 50000 {
+		t.Errorf("yearly = %+v, want a new fidelity summary of 50000", plan.Yearly)
+	}
+
+	// Applying the plan leaves nothing to change.
+	input.DailySnapshots[1].PortfolioValueCents = 151000
+	input.Monthly = append(input.Monthly, database.MonthlySnapshot{Month: database.DateOnly{Time: utcDay(2025, 12, 31)}, AccountID: "fidelity", PortfolioValueCents: 50000})
+	input.NetWorth[0].InvestmentsCents, input.NetWorth[0].NetWorthCents = 151000, 161000
+	input.Yearly = append(input.Yearly, database.YearlyPortfolioSummary{Year: 2025, AccountID: "fidelity", PortfolioValueCents: 50000})
+	again := planRecompute(input, utcDay(2025, 12, 1), utcDay(2025, 12, 31), utcDay(2026, 10, 18))
+	if len(again.Daily)+len(again.Monthly)+len(again.NetWorth)+len(again.Yearly) != 0 {
+		t.Errorf("second plan = %+v, want no changes", again)
+	}
+}
+
+func TestPlanRecomputeAccountWithoutHoldings(t *testing.T) {
+	created := utcDay(2025, 6, 1)
+	linkedLater := utcDay(2026, 2, 1)
+	input := recomputeInput{
+		Daily: []database.DailyHolding{
+			{Date: database.DateOnly{Time: utcDay(2025, 12, 30)}, AccountID: "brokerage", Symbol: "VTI", ValueCents: 99000},
+			{Date: database.DateOnly{Time: utcDay(2025, 12, 30)}, AccountID: "ira", Symbol: "VTI", ValueCents: 24000},
+			{Date: database.DateOnly{Time: utcDay(2025, 12, 31)}, AccountID: "brokerage", Symbol: "VTI", ValueCents: 100000},
+		},
+		Accounts: []database.PlaidAccount{
+			// Has a balance today but no holdings on the 31st.
+			{AccountID: "ira", Type: "investment", CurrentBalance: 250.50, CreatedAt: &created},
+			{AccountID: "checking", Type: "depository", CurrentBalance: 900, CreatedAt: &created},
+			// Linked after the days being recomputed.
+			{AccountID: "hsa", Type: "investment", CurrentBalance: 100, CreatedAt: &linkedLater},
+		},
+		DailySnapshots: []database.DailySnapshot{
+			{Date: database.DateOnly{Time: utcDay(2025, 12, 31)}, PortfolioValueCents: 124000},
+		},
+		Monthly: []database.MonthlySnapshot{
+			{Month: database.DateOnly{Time: utcDay(2025, 12, 31)}, AccountID: "ira", PortfolioValueCents: 24000},
+		},
+	}
+
+	plan := planRecompute(input, utcDay(2025, 12, 1), utcDay(2025, 12, 31), utcDay(2026, 10, 18))
+	if len(plan.Daily) != 1 || plan.Daily[0].Date != "2025-12-30" || plan.Daily[0].NewValueCents != 123000 {
+		t.Errorf("daily = %+v, want only 2025-12-30 at 123000", plan.Daily)
+	}
+	if len(plan.Monthly) != 1 || plan.Monthly[0].AccountID != "brokerage" {
+		t.Errorf("monthly = %+v, want only a brokerage row", plan.Monthly)
+	}
+	if len(plan.Skipped) == 0 || !strings.Contains(plan.Skipped[0], "account ira") {
+		t.Errorf("skipped = %q, want the ira account", plan.Skipped)
+	}
+
+	// The daily sync still counts the current balance.
+	total, accountTotals := portfolioDayTotals(input.Daily[2:], input.Accounts, utcDay(2025, 12, 31))
+	if total != 125050 || len(accountTotals) != 2 || accountTotals["ira"] != 25050 {
+		t.Errorf("portfolioDayTotals = %d %v, want 125050 with brokerage and ira", total, accountTotals)
+	}
+}
+
+func TestParseRecomputeRange(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{"2026-01-01", "2026-03-31", false},
+		{"", "2026-03-31", true},
+		{"2026-03-31", "2026-01-01", true},
+		{"2015-01-01", "2026-01-01", true},
+	}
+	for _, tt := range tests {
+		_, _, err := parseRecomputeRange(tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRecomputeRange(%q, %q) err = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
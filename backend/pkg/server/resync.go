@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Handles a full transaction resync for one Plaid item: replays /transactions/sync from an empty cursor and reconciles stored rows.
+func handleResyncPlaidItem(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil || deps.plaidClient == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database or Plaid not configured")
+		return
+	}
+
+	// Gets the item.
+	itemID := r.PathValue("id")
+	item, err := deps.db.GetPlaidItemByItemID(r.Context(), itemID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to get item: "+err.Error())
+		return
+	}
+	if item == nil {
+		writeJSONError(w, http.StatusNotFound, "item not found")
+		return
+	}
+	if item.AccessToken == "manual" {
+		writeJSONError(w, http.StatusBadRequest, "manual items have no Plaid transactions")
+		return
+	}
+
+	report, err := resyncTransactionsForItem(r.Context(), deps, item)
+	if err != nil {
+		log.Printf("resync transactions for item %s: %v", item.ItemID, err)
+		writeJSONError(w, http.StatusInternalServerError, "resync failed: "+err.Error())
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		log.Printf("resync encode: %v", err)
+	}
+}
+
+// Replays the item's full transaction history and reconciles it with stored transactions.
+func resyncTransactionsForItem(ctx context.Context, deps apiDependencies, item *database.PlaidItem) (*resyncReport, error) {
+	mapping, err := loadCategoryMapping(ctx, deps.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replays /transactions/sync from the beginning (empty cursor).
+	fetched := make(map[string]database.Transaction)
+	removedByPlaid := make(map[string]bool)
+	cursor := ""
+	for {
+		result, err := deps.plaidClient.TransactionsSync(ctx, item.AccessToken, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, transaction := range result.Added {
+			fetched[transaction.TransactionID] = plaidTransactionToDB(transaction, mapping.plaidNameToCategoryID, mapping.uncategorizedID, mapping.rules)
+		}
+		for _, transaction := range result.Modified {
+			fetched[transaction.TransactionID] = plaidTransactionToDB(transaction, mapping.plaidNameToCategoryID, mapping.uncategorizedID, mapping.rules)
+		}
+		for _, removed := range result.Removed {
+			delete(fetched, removed.TransactionID)
+			removedByPlaid[removed.TransactionID] = true
+		}
+		cursor = result.NextCursor
+		if !result.HasMore {
+			break
+		}
+	}
+
+	// Gets the stored transactions for the item's accounts inside the retention window.
+	accounts, err := deps.db.ListPlaidAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var accountIDs []string
+	for _, account := range accounts {
+		if account.PlaidItemID == item.ItemID {
+			accountIDs = append(accountIDs, account.AccountID)
+		}
+	}
+	cutoff := transactionRetentionCutoff(GetLocalNow())
+	stored, err := deps.db.ListTransactionsForAccountsSince(ctx, accountIDs, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedList := make([]database.Transaction, 0, len(fetched))
+	for _, transaction := range fetched {
+		fetchedList = append(fetchedList, transaction)
+	}
+	reconciled := reconcileTransactions(stored, fetchedList, removedByPlaid, cutoff)
+
+	// Applies the changes, then stores the new cursor.
+	err = deps.db.UpsertTransactions(ctx, reconciled.upsert)
+	if err != nil {
+		return nil, err
+	}
+	removed := reconciled.report.RemovedTransactionIDs
+	for start := 0; start < len(removed); start += transactionLookupChunkSize {
+		end := min(start+transactionLookupChunkSize, len(removed))
+		err = deps.db.DeleteTransactionsByPlaidIDs(ctx, removed[start:end])
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = deps.db.UpdatePlaidItemCursorAndPending(ctx, item.ItemID, cursor, false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := reconciled.report
+	report.ItemID = item.ItemID
+	return &report, nil
+}
+
+// Returns the earliest date kept by transaction retention (first day of the month 11 months ago).
+func transactionRetentionCutoff(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()-11, 1, 0, 0, 0, 0, GetLocalLocation())
+}
+
+// Compares stored transactions with the full set Plaid reports and returns what to upsert and delete.
+// Rows before the retention cutoff are skipped, and user category overrides are kept. Plaid only returns
+// recent history (about 90 days by default), so a stored row is deleted only if Plaid removed it or it is
+// dated on or after the earliest fetched transaction and Plaid no longer reports it.
+func reconcileTransactions(stored, fetched []database.Transaction, removedByPlaid map[string]bool, cutoff time.Time) transactionReconcileResult {
+	result := transactionReconcileResult{
+		report: resyncReport{
+			RetentionCutoff:       cutoff.Format(dateLayout),
+			RemovedTransactionIDs: []string{},
+		},
+	}
+
+	storedByID := make(map[string]database.Transaction, len(stored))
+	for _, transaction := range stored {
+		storedByID[transaction.PlaidTransactionID] = transaction
+	}
+
+	// Finds the start of the fetched window.
+	var fetchedFrom time.Time
+	for _, transaction := range fetched {
+		if fetchedFrom.IsZero() || transaction.Date.Before(fetchedFrom) {
+			fetchedFrom = calendarDay(transaction.Date.Time)
+		}
+	}
+	if !fetchedFrom.IsZero() {
+		result.report.FetchedFrom = fetchedFrom.Format(dateLayout)
+	}
+
+	// Upserts what Plaid still reports.
+	seen := make(map[string]bool, len(fetched))
+	for _, transaction := range fetched {
+		if transaction.Date.Before(cutoff) {
+			result.report.SkippedBeforeRetention++
+			continue
+		}
+		result.report.Fetched++
+		seen[transaction.PlaidTransactionID] = true
+
+		existing, ok := storedByID[transaction.PlaidTransactionID]
+		if !ok {
+			result.report.Added++
+			result.upsert = append(result.upsert, transaction)
+			continue
+		}
+		if existing.CategoryOverridden {
+			transaction.CategoryID = existing.CategoryID
+			transaction.CategoryOverridden = true
+			result.report.CategoriesPreserved++
+		}
+		if transactionsEqual(existing, transaction) {
+			result.report.Unchanged++
+			continue
+		}
+		result.report.Updated++
+		result.upsert = append(result.upsert, transaction)
+	}
+
+	// Deletes stored rows Plaid removed or no longer reports within the fetched window.
+	for _, transaction := range stored {
+		if seen[transaction.PlaidTransactionID] {
+			continue
+		}
+		inWindow := !fetchedFrom.IsZero() && !calendarDay(transaction.Date.Time).Before(fetchedFrom)
+		if !removedByPlaid[transaction.PlaidTransactionID] && !inWindow {
+			result.report.KeptBeforeFetched++
+			continue
+		}
+		result.report.RemovedTransactionIDs = append(result.report.RemovedTransactionIDs, transaction.PlaidTransactionID)
+	}
+	sort.Strings(result.report.RemovedTransactionIDs)
+	result.report.Removed = len(result.report.RemovedTransactionIDs)
+	return result
+}
+
+// Reports whether two transactions have the same synced fields.
+func transactionsEqual(a, b database.Transaction) bool {
+	return a.PlaidAccountID == b.PlaidAccountID &&
+		a.Date.Format(dateLayout) == b.Date.Format(dateLayout) &&
+		a.AmountCents == b.AmountCents &&
+		a.Name == b.Name &&
+		equalStringPtr(a.MerchantName, b.MerchantName) &&
+		equalInt64Ptr(a.CategoryID, b.CategoryID) &&
+		a.CategoryOverridden == b.CategoryOverridden &&
+		a.Pending == b.Pending
+}
+
+// Reports whether two optional strings are equal.
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Reports whether two optional IDs are equal.
+func equalInt64Ptr(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Rows to write after reconciling, plus the report returned to the caller.
+type transactionReconcileResult struct {
+	upsert []database.Transaction
+	report resyncReport
+}
+
+// Resync report for API.
+type resyncReport struct {
+	ItemID                 string   `json:"itemId"`
+	RetentionCutoff        string   `json:"retentionCutoff"`
+	FetchedFrom            string   `json:"fetchedFrom,omitempty"`
+	Fetched                int      `json:"fetched"`
+	Added                  int      `json:"added"`
+	Updated                int      `json:"updated"`
+	Unchanged              int      `json:"unchanged"`
+	Removed                int      `json:"removed"`
+	RemovedTransactionIDs  []string `json:"removedTransactionIds"`
+	SkippedBeforeRetention int      `json:"skippedBeforeRetention"`
+	CategoriesPreserved    int      `json:"categoriesPreserved"`
+	// Stored rows older than anything Plaid returned, kept since Plaid's history doesn't reach them.
+	KeptBeforeFetched int `json:"keptBeforeFetched"`
+}
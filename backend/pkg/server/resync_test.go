@@ -0,0 +1,105 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestReconcileTransactions(t *testing.T) {
+	cutoff := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) database.DateOnly {
+		year := 2026
+		if month >= 10 {
+			year = 2025
+		}
+		return database.DateOnly{Time: time.Date(year, month, d, 0, 0, 0, 0, time.UTC)}
+	}
+	food, venmo := int64(2), int64(16)
+
+	stored := []database.Transaction{
+		{PlaidTransactionID: "same", PlaidAccountID: "acc", Date: day(1, 5), AmountCents: -500, Name: "Coffee", CategoryID: &food},
+		{PlaidTransactionID: "changed", PlaidAccountID: "acc", Date: day(2, 1), AmountCents: -1000, Name: "Store", CategoryID: &food, Pending: true},
+		{PlaidTransactionID: "override", PlaidAccountID: "acc", Date: day(3, 1), AmountCents: -2000, Name: "Dinner", CategoryID: &venmo, CategoryOverridden: true},
+		{PlaidTransactionID: "gone", PlaidAccountID: "acc", Date: day(3, 2), AmountCents: -300, Name: "Snack", CategoryID: &food},
+	}
+	fetched := []database.Transaction{
+		{PlaidTransactionID: "same", PlaidAccountID: "acc", Date: day(1, 5), AmountCents: -500, Name: "Coffee", CategoryID: &food},
+		{PlaidTransactionID: "changed", PlaidAccountID: "acc", Date: day(2, 1), AmountCents: -1050, Name: "Store", CategoryID: &food},
+		{PlaidTransactionID: "override", PlaidAccountID: "acc", Date: day(3, 1), AmountCents: -2000, Name: "Dinner", CategoryID: &food},
+		{PlaidTransactionID: "new", PlaidAccountID: "acc", Date: day(3, 3), AmountCents: -700, Name: "Lunch", CategoryID: &food},
+		{PlaidTransactionID: "old", PlaidAccountID: "acc", Date: day(10, 15), AmountCents: -100, Name: "Old", CategoryID: &food},
+	}
+
+	result := reconcileTransactions(stored, fetched, nil, cutoff)
+	report := result.report
+
+	if report.Fetched != 4 || report.Added != 1 || report.Updated != 1 || report.Unchanged != 2 || report.Removed != 1 {
+		t.Errorf("report counts = fetched %d added %d updated %d unchanged %d removed %d, want 4/1/1/2/1",
+			report.Fetched, report.Added, report.Updated, report.Unchanged, report.Removed)
+	}
+	if report.SkippedBeforeRetention != 1 {
+		t.Errorf("SkippedBeforeRetention = %d, want 1", report.SkippedBeforeRetention)
+	}
+	if report.CategoriesPreserved != 1 {
+		t.Errorf("CategoriesPreserved = %d, want 1", report.CategoriesPreserved)
+	}
+	if len(report.RemovedTransactionIDs) != 1 || report.RemovedTransactionIDs[0] != "gone" {
+		t.Errorf("RemovedTransactionIDs = %v, want [gone]", report.RemovedTransactionIDs)
+	}
+	if report.RetentionCutoff != "2025-11-01" {
+		t.Errorf("RetentionCutoff = %s, want 2025-11-01", report.RetentionCutoff)
+	}
+
+	upserted := make(map[string]database.Transaction)
+	for _, transaction := range result.upsert {
+		upserted[transaction.PlaidTransactionID] = transaction
+	}
+	if len(upserted) != 2 {
+		t.Fatalf("upserted %d transactions, want 2 (changed, new)", len(upserted))
+	}
+	if _, ok := upserted["changed"]; !ok {
+		t.Errorf("changed transaction was not upserted")
+	}
+	if _, ok := upserted["new"]; !ok {
+		t.Errorf("new transaction was not upserted")
+	}
+}
+
+func TestReconcileTransactionsShortHistory(t *testing.T) {
+	cutoff := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) database.DateOnly {
+		return database.DateOnly{Time: time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)}
+	}
+	food, venmo := int64(2), int64(16)
+
+	// Stored history goes back to January; Plaid only returns the last 90 days, from July.
+	stored := []database.Transaction{
+		{PlaidTransactionID: "january", PlaidAccountID: "acc", Date: day(1, 10), AmountCents: -500, Name: "Coffee", CategoryID: &food},
+		{PlaidTransactionID: "override", PlaidAccountID: "acc", Date: day(3, 1), AmountCents: -2000, Name: "Dinner", CategoryID: &venmo, CategoryOverridden: true},
+		{PlaidTransactionID: "removed", PlaidAccountID: "acc", Date: day(4, 2), AmountCents: -300, Name: "Refunded", CategoryID: &food},
+		{PlaidTransactionID: "recent", PlaidAccountID: "acc", Date: day(7, 20), AmountCents: -900, Name: "Gas", CategoryID: &food},
+		{PlaidTransactionID: "gone", PlaidAccountID: "acc", Date: day(8, 2), AmountCents: -300, Name: "Snack", CategoryID: &food},
+	}
+	fetched := []database.Transaction{
+		{PlaidTransactionID: "first", PlaidAccountID: "acc", Date: day(7, 15), AmountCents: -100, Name: "Parking", CategoryID: &food},
+		{PlaidTransactionID: "recent", PlaidAccountID: "acc", Date: day(7, 20), AmountCents: -900, Name: "Gas", CategoryID: &food},
+	}
+
+	report := reconcileTransactions(stored, fetched, map[string]bool{"removed": true}, cutoff).report
+	if len(report.RemovedTransactionIDs) != 2 || report.RemovedTransactionIDs[0] != "gone" || report.RemovedTransactionIDs[1] != "removed" {
+		t.Errorf("RemovedTransactionIDs = %v, want [gone removed]", report.RemovedTransactionIDs)
+	}
+	if report.KeptBeforeFetched != 2 || report.FetchedFrom != "2026-07-15" {
+		t.Errorf("kept %d before %s, want 2 before 2026-07-15", report.KeptBeforeFetched, report.FetchedFrom)
+	}
+}
+
+func TestTransactionRetentionCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, GetLocalLocation())
+	got := transactionRetentionCutoff(now)
+	if got.Format(dateLayout) != "2025-04-01" {
+		t.Errorf("transactionRetentionCutoff = %s, want 2025-04-01", got.Format(dateLayout))
+	}
+}
@@ -0,0 +1,393 @@
+package server
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Investment transaction subtypes that move money into or out of an account (Plaid type "cash").
+var externalCashSubtypes = map[string]bool{
+	"deposit":      true,
+	"withdrawal":   true,
+	"contribution": true,
+	"distribution": true,
+}
+
+// Investment transaction subtypes that transfer cash or securities between institutions (Plaid type "transfer").
+// Other transfer subtypes (splits, mergers, spin-offs) move value within the account.
+var externalTransferSubtypes = map[string]bool{
+	"transfer": true,
+	"send":     true,
+	"request":  true,
+}
+
+// Returns the measured period for a valuation series and its cash flows. Start is the valuation on or before
+// start (or the first one after it, marking the result partial); flows after that valuation and on or before end count.
+// A zero start measures since the first valuation.
+func periodReturn(points []valuationPoint, flows []cashFlow, start, end time.Time) (periodReturnResult, bool) {
+	// Finds the valuations that bound the period.
+	startIndex, endIndex := -1, -1
+	for i, point := range points {
+		if point.Date.After(end) {
+			break
+		}
+		endIndex = i
+		if !start.IsZero() && !point.Date.After(start) {
+			startIndex = i
+		}
+	}
+	if endIndex < 0 {
+		return periodReturnResult{}, false
+	}
+	partial := false
+	if startIndex < 0 {
+		startIndex = 0
+		partial = !start.IsZero()
+	}
+	window := points[startIndex : endIndex+1]
+	startDate, endDate := window[0].Date, window[len(window)-1].Date
+
+	var windowFlows []cashFlow
+	var netFlows int64
+	for _, flow := range flows {
+		if flow.Date.After(startDate) && !flow.Date.After(endDate) {
+			windowFlows = append(windowFlows, flow)
+			netFlows += flow.AmountCents
+		}
+	}
+
+	result := periodReturnResult{
+		StartDate:        startDate,
+		EndDate:          endDate,
+		StartValueCents:  window[0].ValueCents,
+		EndValueCents:    window[len(window)-1].ValueCents,
+		NetCashFlowCents: netFlows,
+		Partial:          partial,
+	}
+	result.GainCents = result.EndValueCents - result.StartValueCents - netFlows
+	if len(window) > 1 {
+		if twr, ok := timeWeightedReturn(window, windowFlows); ok {
+			result.TimeWeightedReturn = &twr
+		}
+		// The investor pays in the starting value and deposits and receives withdrawals and the ending value.
+		amounts := []datedAmount{{Date: startDate, AmountCents: -result.StartValueCents}}
+		for _, flow := range windowFlows {
+			amounts = append(amounts, datedAmount{Date: flow.Date, AmountCents: -flow.AmountCents})
+		}
+		amounts = append(amounts, datedAmount{Date: endDate, AmountCents: result.EndValueCents})
+		if rate, ok := xirr(amounts); ok {
+			result.MoneyWeightedReturn = &rate
+		}
+	}
+	return result, true
+}
+
+// Chains Modified Dietz returns between consecutive valuations. Flows are weighted by how much of the
+// sub-period they were invested for, so a flow on the closing date only affects the ending value.
+// Sub-periods with nothing invested are skipped. Returns false if no sub-period had capital at work.
+func timeWeightedReturn(points []valuationPoint, flows []cashFlow) (float64, bool) {
+	growth := 1.0
+	measured := false
+	flowIndex := 0
+	sorted := sortedCashFlows(flows)
+	for i := 1; i < len(points); i++ {
+		previous, current := points[i-1], points[i]
+		days := current.Date.Sub(previous.Date).Hours() / 24
+		if days <= 0 {
+			continue
+		}
+
+		var net, weighted float64
+		for flowIndex < len(sorted) && !sorted[flowIndex].Date.After(current.Date) {
+			flow := sorted[flowIndex]
+			flowIndex++
+			if !flow.Date.After(previous.Date) {
+				continue
+			}
+			amount := float64(flow.AmountCents)
+			net += amount
+			weighted += amount * current.Date.Sub(flow.Date).Hours() / 24 / days
+		}
+
+		invested := float64(previous.ValueCents) + weighted
+		if invested <= 0 {
+			continue
+		}
+		growth *= 1 + (float64(current.ValueCents)-float64(previous.ValueCents)-net)/invested
+		measured = true
+	}
+	return growth - 1, measured
+}
+
+// Solves for the annual rate that makes the net present value of the dated amounts zero (XIRR).
+// Needs at least one positive and one negative amount. Uses Newton's method, falling back to bisection.
+func xirr(amounts []datedAmount) (float64, bool) {
+	if len(amounts) < 2 {
+		return 0, false
+	}
+	var hasPositive, hasNegative bool
+	first := amounts[0].Date
+	for _, amount := range amounts {
+		hasPositive = hasPositive || amount.AmountCents > 0
+		hasNegative = hasNegative || amount.AmountCents < 0
+		if amount.Date.Before(first) {
+			first = amount.Date
+		}
+	}
+	if !hasPositive || !hasNegative {
+		return 0, false
+	}
+
+	npv := func(rate float64) (float64, float64) {
+		var value, derivative float64
+		for _, amount := range amounts {
+			years := amount.Date.Sub(first).Hours() / 24 / 365
+			discount := math.Pow(1+rate, -years)
+			value += float64(amount.AmountCents) * discount
+			derivative -= years * float64(amount.AmountCents) * discount / (1 + rate)
+		}
+		return value, derivative
+	}
+
+	rate := 0.1
+	for range 100 {
+		value, derivative := npv(rate)
+		if math.Abs(value) < 1e-6 {
+			return rate, true
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-10 {
+			return next, true
+		}
+		rate = next
+	}
+
+	// Bisection over a wide bracket when Newton's method does not converge.
+	low, high := -0.9999, 100.0
+	lowValue, _ := npv(low)
+	highValue, _ := npv(high)
+	if lowValue*highValue > 0 {
+		return 0, false
+	}
+	for range 200 {
+		mid := (low + high) / 2
+		midValue, _ := npv(mid)
+		if math.Abs(midValue) < 1e-6 || high-low < 1e-10 {
+			return mid, true
+		}
+		if lowValue*midValue < 0 {
+			high = mid
+		} else {
+			low, lowValue = mid, midValue
+		}
+	}
+	return (low + high) / 2, true
+}
+
+// Splits investment transactions into external cash flows per account and trade/income flows per holding.
+// Amounts are money put into the account or holding (positive) or taken out (negative).
+func investmentCashFlows(transactions []database.InvestmentTransaction) (map[string][]cashFlow, map[holdingKey][]cashFlow) {
+	byAccount := make(map[string][]cashFlow)
+	byHolding := make(map[holdingKey][]cashFlow)
+	for _, transaction := range transactions {
+		transactionType := strings.ToLower(transaction.Type)
+		subtype := strings.ToLower(transaction.Subtype)
+		date := calendarDay(transaction.Date.Time)
+
+		// In-kind transfers carry no cash, so they are valued at the transfer price.
+		amount := transaction.AmountCents
+		if transactionType == "transfer" && amount == 0 {
+			amount = int64(math.Round(transaction.Quantity * float64(transaction.PriceCents)))
+		}
+
+		if (transactionType == "cash" && externalCashSubtypes[subtype]) || (transactionType == "transfer" && externalTransferSubtypes[subtype]) {
+			if amount != 0 {
+				byAccount[transaction.AccountID] = append(byAccount[transaction.AccountID], cashFlow{Date: date, AmountCents: amount})
+			}
+		}
+
+		if transaction.Symbol == nil {
+			continue
+		}
+		key := holdingKey{AccountID: transaction.AccountID, Symbol: *transaction.Symbol}
+		var holdingAmount int64
+		switch transactionType {
+		case "buy", "sell", "cash":
+			// Buys spend cash into the holding; sells and income pay cash out of it.
+			holdingAmount = -transaction.AmountCents
+		case "transfer":
+			if externalTransferSubtypes[subtype] {
+				holdingAmount = amount
+			}
+		}
+		if holdingAmount != 0 {
+			byHolding[key] = append(byHolding[key], cashFlow{Date: date, AmountCents: holdingAmount})
+		}
+	}
+	return byAccount, byHolding
+}
+
+// Detects deposits and withdrawals from bank-feed transactions on investment accounts that have no
+// investment activity, using the transfer categories. Inflows are positive.
+func detectedDepositFlows(transactions []database.Transaction, accountIDs map[string]bool, transferCategoryIDs map[int64]bool) map[string][]cashFlow {
+	byAccount := make(map[string][]cashFlow)
+	for _, transaction := range transactions {
+		if !accountIDs[transaction.PlaidAccountID] || transaction.Pending || transaction.CategoryID == nil || !transferCategoryIDs[*transaction.CategoryID] {
+			continue
+		}
+		byAccount[transaction.PlaidAccountID] = append(byAccount[transaction.PlaidAccountID], cashFlow{
+			Date:        calendarDay(transaction.Date.Time),
+			AmountCents: transaction.AmountCents,
+		})
+	}
+	return byAccount
+}
+
+// Builds each account's value series from the retention tiers: year-end summaries, monthly snapshots and
+// daily holdings. Each monthly snapshot holds the value on the last day rolled up into it, which is the month
+// end unless that day is still kept as a daily row. Finer tiers win when they share a date.
+func buildAccountValuations(yearly []database.YearlyPortfolioSummary, monthly []database.MonthlySnapshot, daily []database.DailyHolding, dailyStart time.Time) map[string][]valuationPoint {
+	values := make(map[string]map[time.Time]int64)
+	set := func(accountID string, date time.Time, cents int64) {
+		if values[accountID] == nil {
+			values[accountID] = make(map[time.Time]int64)
+		}
+		values[accountID][date] = cents
+	}
+
+	for _, summary := range yearly {
+		set(summary.AccountID, time.Date(summary.Year, time.December, 31, 0, 0, 0, 0, time.UTC), summary.PortfolioValueCents)
+	}
+	lastRolledUp := calendarDay(dailyStart).AddDate(0, 0, -1)
+	for _, snapshot := range monthly {
+		monthStart := time.Date(snapshot.Month.Year(), snapshot.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		date := minTime(monthStart.AddDate(0, 1, -1), lastRolledUp)
+		if date.Before(monthStart) {
+			continue
+		}
+		set(snapshot.AccountID, date, snapshot.PortfolioValueCents)
+	}
+	dailyTotals := make(map[string]map[time.Time]int64)
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		if dailyTotals[holding.AccountID] == nil {
+			dailyTotals[holding.AccountID] = make(map[time.Time]int64)
+		}
+		dailyTotals[holding.AccountID][date] += holding.ValueCents
+	}
+	for accountID, byDate := range dailyTotals {
+		for date, cents := range byDate {
+			set(accountID, date, cents)
+		}
+	}
+
+	series := make(map[string][]valuationPoint, len(values))
+	for accountID, byDate := range values {
+		series[accountID] = sortedValuations(byDate)
+	}
+	return series
+}
+
+// Builds each holding's value series from daily holdings.
+func buildHoldingValuations(daily []database.DailyHolding) map[holdingKey][]valuationPoint {
+	values := make(map[holdingKey]map[time.Time]int64)
+	for _, holding := range daily {
+		key := holdingKey{AccountID: holding.AccountID, Symbol: holding.Symbol}
+		if values[key] == nil {
+			values[key] = make(map[time.Time]int64)
+		}
+		date := calendarDay(holding.Date.Time)
+		values[key][date] += holding.ValueCents
+	}
+	series := make(map[holdingKey][]valuationPoint, len(values))
+	for key, byDate := range values {
+		series[key] = sortedValuations(byDate)
+	}
+	return series
+}
+
+// Sums account series into a total series. Every account is valued on the same dates within a tier,
+// so dates are summed over the accounts valued on them.
+func sumValuations(series map[string][]valuationPoint) []valuationPoint {
+	totals := make(map[time.Time]int64)
+	for _, points := range series {
+		for _, point := range points {
+			totals[point.Date] += point.ValueCents
+		}
+	}
+	return sortedValuations(totals)
+}
+
+// Returns the valuations ordered by date.
+func sortedValuations(byDate map[time.Time]int64) []valuationPoint {
+	points := make([]valuationPoint, 0, len(byDate))
+	for date, cents := range byDate {
+		points = append(points, valuationPoint{Date: date, ValueCents: cents})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Date.Before(points[j].Date)
+	})
+	return points
+}
+
+// Returns the date's calendar day at midnight UTC, so dates from different sources compare equal.
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Returns a copy of the flows ordered by date.
+func sortedCashFlows(flows []cashFlow) []cashFlow {
+	sorted := make([]cashFlow, len(flows))
+	copy(sorted, flows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	return sorted
+}
+
+// Value of an account, holding or the whole portfolio at the end of a day.
+type valuationPoint struct {
+	Date       time.Time
+	ValueCents int64
+}
+
+// Money put into (positive) or taken out of (negative) what is being measured.
+type cashFlow struct {
+	Date        time.Time
+	AmountCents int64
+}
+
+// Investor-side amount for XIRR: paid in is negative, received is positive.
+type datedAmount struct {
+	Date        time.Time
+	AmountCents int64
+}
+
+// Identifies a position: one symbol in one account.
+type holdingKey struct {
+	AccountID string
+	Symbol    string
+}
+
+// Returns over one period. Rates are fractions (0.05 is 5%); the money-weighted return is annualized.
+type periodReturnResult struct {
+	StartDate           time.Time
+	EndDate             time.Time
+	StartValueCents     int64
+	EndValueCents       int64
+	NetCashFlowCents    int64
+	GainCents           int64
+	TimeWeightedReturn  *float64
+	MoneyWeightedReturn *float64
+	Partial             bool
+}
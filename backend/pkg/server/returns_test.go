@@ -0,0 +1,179 @@
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func utcDay(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTimeWeightedReturnIgnoresDeposits(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []valuationPoint
+		flows  []cashFlow
+		want   float64
+	}{
+		{
+			name:   "deposit is not a gain",
+			points: []valuationPoint{{Date: utcDay(2026, 1, 1), ValueCents: 1000000}, {Date: utcDay(2026, 1, 2), ValueCents: 2000000}},
+			flows:  []cashFlow{{Date: utcDay(2026, 1, 2), AmountCents: 1000000}},
+			want:   0,
+		},
+		{
+			name: "growth chains across a deposit",
+			points: []valuationPoint{
+				{Date: utcDay(2026, 1, 1), ValueCents: 10000},
+				{Date: utcDay(2026, 1, 2), ValueCents: 11000},
+				{Date: utcDay(2026, 1, 3), ValueCents: 17600},
+			},
+			// 10%, then 1600 on 11000 with 5000 deposited on the closing date.
+			flows: []cashFlow{{Date: utcDay(2026, 1, 3), AmountCents: 5000}},
+			want:  1.1*(1+1600.0/11000.0) - 1,
+		},
+		{
+			name: "mid-period deposit is weighted by time invested",
+			points: []valuationPoint{
+				{Date: utcDay(2026, 1, 1), ValueCents: 10000},
+				{Date: utcDay(2026, 1, 11), ValueCents: 21000},
+			},
+			// Half the period invested: gain 1000 / (10000 + 10000*0.5).
+			flows: []cashFlow{{Date: utcDay(2026, 1, 6), AmountCents: 10000}},
+			want:  1000.0 / 15000.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := timeWeightedReturn(tt.points, tt.flows)
+			if !ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("timeWeightedReturn() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestXIRR(t *testing.T) {
+	got, ok := xirr([]datedAmount{
+		{Date: utcDay(2025, 1, 1), AmountCents: -100000},
+		{Date: utcDay(2026, 1, 1), AmountCents: 110000},
+	})
+	if !ok || math.Abs(got-0.1) > 1e-6 {
+		t.Errorf("xirr() = %v, %v, want 0.1", got, ok)
+	}
+
+	// A deposit halfway through a year that ends flat returns nothing.
+	got, ok = xirr([]datedAmount{
+		{Date: utcDay(2025, 1, 1), AmountCents: -100000},
+		{Date: utcDay(2025, 7, 1), AmountCents: -50000},
+		{Date: utcDay(2026, 1, 1), AmountCents: 150000},
+	})
+	if !ok || math.Abs(got) > 1e-6 {
+		t.Errorf("xirr() = %v, %v, want 0", got, ok)
+	}
+
+	if _, ok := xirr([]datedAmount{{Date: utcDay(2025, 1, 1), AmountCents: -100}, {Date: utcDay(2026, 1, 1), AmountCents: -100}}); ok {
+		t.Error("xirr() with no inflow should not solve")
+	}
+}
+
+func TestPeriodReturn(t *testing.T) {
+	points := []valuationPoint{
+		{Date: utcDay(2026, 8, 31), ValueCents: 100000},
+		{Date: utcDay(2026, 9, 30), ValueCents: 115000},
+		{Date: utcDay(2026, 10, 17), ValueCents: 126500},
+	}
+	flows := []cashFlow{
+		{Date: utcDay(2026, 8, 15), AmountCents: 50000},
+		{Date: utcDay(2026, 9, 30), AmountCents: 10000},
+	}
+
+	got, ok := periodReturn(points, flows, utcDay(2026, 9, 30), utcDay(2026, 10, 18))
+	if !ok {
+		t.Fatal("periodReturn() found no valuations")
+	}
+	if got.StartValueCents != 115000 || got.EndValueCents != 126500 || got.NetCashFlowCents != 0 || got.GainCents != 11500 || got.Partial {
+		t.Errorf("MTD periodReturn() = %+v", got)
+	}
+	if got.TimeWeightedReturn == nil || math.Abs(*got.TimeWeightedReturn-0.1) > 1e-9 {
+		t.Errorf("MTD time-weighted return = %v, want 0.1", got.TimeWeightedReturn)
+	}
+
+	// The requested start is before the history, so it starts at the first valuation and skips the earlier deposit.
+	got, _ = periodReturn(points, flows, utcDay(2025, 12, 31), utcDay(2026, 10, 18))
+	if !got.Partial || got.StartDate != utcDay(2026, 8, 31) || got.NetCashFlowCents != 10000 || got.GainCents != 16500 {
+		t.Errorf("YTD periodReturn() = %+v", got)
+	}
+
+	if _, ok := periodReturn(points, flows, utcDay(2026, 1, 1), utcDay(2026, 6, 1)); ok {
+		t.Error("periodReturn() before any valuation should report nothing")
+	}
+}
+
+func TestInvestmentCashFlows(t *testing.T) {
+	vti := "VTI"
+	transactions := []database.InvestmentTransaction{
+		{AccountID: "a", Type: "cash", Subtype: "deposit", AmountCents: 500000},
+		{AccountID: "a", Type: "buy", Subtype: "buy", Symbol: &vti, AmountCents: -400000},
+		{AccountID: "a", Type: "cash", Subtype: "dividend", Symbol: &vti, AmountCents: 1200},
+		{AccountID: "a", Type: "fee", Subtype: "account fee", AmountCents: -500},
+		{AccountID: "a", Type: "transfer", Subtype: "split", Symbol: &vti, Quantity: 10},
+		{AccountID: "b", Type: "transfer", Subtype: "transfer", Symbol: &vti, Quantity: 10, PriceCents: 25000},
+		{AccountID: "b", Type: "cash", Subtype: "withdrawal", AmountCents: -30000},
+	}
+
+	byAccount, byHolding := investmentCashFlows(transactions)
+	sum := func(flows []cashFlow) int64 {
+		var total int64
+		for _, flow := range flows {
+			total += flow.AmountCents
+		}
+		return total
+	}
+	if got := sum(byAccount["a"]); got != 500000 {
+		t.Errorf("account a flows = %d, want 500000", got)
+	}
+	if got := sum(byAccount["b"]); got != 250000-30000 {
+		t.Errorf("account b flows = %d, want 220000", got)
+	}
+	if got := sum(byHolding[holdingKey{AccountID: "a", Symbol: "VTI"}]); got != 400000-1200 {
+		t.Errorf("holding a/VTI flows = %d, want 398800", got)
+	}
+	if got := sum(byHolding[holdingKey{AccountID: "b", Symbol: "VTI"}]); got != 250000 {
+		t.Errorf("holding b/VTI flows = %d, want 250000", got)
+	}
+}
+
+func TestBuildAccountValuations(t *testing.T) {
+	dailyStart := utcDay(2026, 9, 18)
+	yearly := []database.YearlyPortfolioSummary{{Year: 2025, AccountID: "a", PortfolioValueCents: 100}}
+	monthly := []database.MonthlySnapshot{
+		{Month: database.DateOnly{Time: utcDay(2026, 8, 1)}, AccountID: "a", PortfolioValueCents: 200},
+		// Still being rolled up: holds the value from the day before the daily window.
+		{Month: database.DateOnly{Time: utcDay(2026, 9, 1)}, AccountID: "a", PortfolioValueCents: 300},
+	}
+	daily := []database.DailyHolding{
+		{Date: database.DateOnly{Time: utcDay(2026, 10, 17)}, AccountID: "a", Symbol: "VTI", ValueCents: 250},
+		{Date: database.DateOnly{Time: utcDay(2026, 10, 17)}, AccountID: "a", Symbol: "BND", ValueCents: 150},
+	}
+
+	got := buildAccountValuations(yearly, monthly, daily, dailyStart)["a"]
+	want := []valuationPoint{
+		{Date: utcDay(2025, 12, 31), ValueCents: 100},
+		{Date: utcDay(2026, 8, 31), ValueCents: 200},
+		{Date: utcDay(2026, 9, 17), ValueCents: 300},
+		{Date: utcDay(2026, 10, 17), ValueCents: 400},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("buildAccountValuations() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !got[i].Date.Equal(want[i].Date) || got[i].ValueCents != want[i].ValueCents {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
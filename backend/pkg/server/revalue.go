@@ -0,0 +1,321 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Where a stored close came from.
+const (
+	priceSourcePlaid    = "plaid"
+	priceSourceCSV      = "csv"
+	priceSourceProvider = "provider"
+)
+
+// A missed day is only revalued with a close at most this many days old.
+const revalueMaxPriceAgeDays = 5
+
+// Days the nightly cron looks back for missed holdings days.
+const revalueCronLookbackDays = 7
+
+// Registers the revaluation routes.
+func registerRevalueRoutes(mux *http.ServeMux, deps apiDependencies) {
+	// POST /api/portfolio/revalue?from=&to= fills missed daily holdings and snapshots from stored prices.
+	mux.Handle("/api/portfolio/revalue", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleRevalue(w, r, deps)
+	})))
+}
+
+// Revalues missed days between from and to (default: the last week through yesterday).
+func handleRevalue(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	today := calendarDay(GetLocalNow())
+	from, to, err := parseRevalueRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"), today)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := revalueMissedDays(r, deps, from, to)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// Parses the revaluation range. Dates before the daily holdings window and after yesterday are clamped.
+func parseRevalueRange(fromValue, toValue string, today time.Time) (time.Time, time.Time, error) {
+	from := today.AddDate(0, 0, -revalueCronLookbackDays)
+	to := today.AddDate(0, 0, -1)
+	var err error
+	if fromValue != "" {
+		from, err = time.Parse(dateLayout, fromValue)
+		if err != nil {
+			return from, to, errors.New("from must be YYYY-MM-DD")
+		}
+	}
+	if toValue != "" {
+		to, err = time.Parse(dateLayout, toValue)
+		if err != nil {
+			return from, to, errors.New("to must be YYYY-MM-DD")
+		}
+	}
+
+	if earliest := dailyHoldingsWindowStart(today); from.Before(earliest) {
+		from = earliest
+	}
+	if latest := today.AddDate(0, 0, -1); to.After(latest) {
+		to = latest
+	}
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// Fills account-days between from and to that have no daily holdings by revaluing the account's last known
+// quantities at stored closes (fetching missing closes from the price provider first), then rebuilds the
+// daily snapshot of every filled day.
+func revalueMissedDays(r *http.Request, deps apiDependencies, from, to time.Time) (revalueResponse, error) {
+	result := revalueResponse{FilledDates: []string{}}
+	if deps.db == nil {
+		return result, nil
+	}
+
+	// Loads the days before the range too, for the last known quantities.
+	daily, err := deps.db.ListDailyHoldings(r.Context(), from.AddDate(0, 0, -revalueCronLookbackDays), to)
+	if err != nil {
+		return result, err
+	}
+	symbols := revaluedSymbols(daily)
+	if len(symbols) == 0 {
+		return result, nil
+	}
+
+	priceStart := from.AddDate(0, 0, -revalueMaxPriceAgeDays)
+	if deps.priceProvider != nil {
+		result.PricesFetched = fetchHoldingPrices(r.Context(), deps, symbols, priceStart, to)
+	}
+	prices, err := deps.db.ListPricesForSymbols(r.Context(), symbols, priceStart, to)
+	if err != nil {
+		return result, err
+	}
+
+	filled := revalueHoldings(daily, prices, from, to)
+	dates := make(map[time.Time]bool)
+	for i := range filled {
+		err = deps.db.UpsertDailyHolding(r.Context(), &filled[i])
+		if err != nil {
+			return result, err
+		}
+		dates[filled[i].Date.Time] = true
+	}
+	result.FilledHoldings = len(filled)
+
+	sortedDates := make([]time.Time, 0, len(dates))
+	for date := range dates {
+		sortedDates = append(sortedDates, date)
+	}
+	sort.Slice(sortedDates, func(i, j int) bool {
+		return sortedDates[i].Before(sortedDates[j])
+	})
+	for _, date := range sortedDates {
+		err = updatePortfolioSnapshots(r, deps, date)
+		if err != nil {
+			return result, err
+		}
+		result.FilledDates = append(result.FilledDates, date.Format(dateLayout))
+	}
+	return result, nil
+}
+
+// Returns the symbols that need a close to be revalued, skipping cash and names Plaid gave no ticker for.
+func revaluedSymbols(daily []database.DailyHolding) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, holding := range daily {
+		if seen[holding.Symbol] || isCashLikeHolding(holding) || strings.ContainsAny(holding.Symbol, " :") {
+			continue
+		}
+		seen[holding.Symbol] = true
+		symbols = append(symbols, holding.Symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Fetches closes the database doesn't have yet for each symbol. Provider failures are logged and skipped.
+func fetchHoldingPrices(ctx context.Context, deps apiDependencies, symbols []string, from, to time.Time) int {
+	fetched := 0
+	for _, symbol := range symbols {
+		start := from
+		latest, err := deps.db.GetLatestPriceDate(ctx, symbol)
+		if err != nil {
+			log.Printf("revalue: latest price date for %s: %v", symbol, err)
+			continue
+		}
+		if latest != nil && calendarDay(*latest).AddDate(0, 0, 1).After(start) {
+			start = calendarDay(*latest).AddDate(0, 0, 1)
+		}
+		if start.After(to) {
+			continue
+		}
+
+		closes, err := deps.priceProvider.DailyCloses(ctx, symbol, start, to)
+		if err != nil {
+			log.Printf("revalue: fetch prices for %s: %v", symbol, err)
+			continue
+		}
+		err = deps.db.UpsertPrices(ctx, dailyClosesToDB(closes, priceSourceProvider))
+		if err != nil {
+			log.Printf("revalue: store prices for %s: %v", symbol, err)
+			continue
+		}
+		fetched += len(closes)
+	}
+	return fetched
+}
+
+// Builds holdings for each account-day between from and to (inclusive) that has none, from the account's
+// latest earlier day. Each holding is valued at the symbol's latest close on or before the day; the known
+// value is kept when that close is no newer than the known day, and cash is always carried forward. Days
+// where any holding has no close within revalueMaxPriceAgeDays are skipped. Closes must be oldest first
+// per symbol.
+func revalueHoldings(daily []database.DailyHolding, prices []database.Price, from, to time.Time) []database.DailyHolding {
+	closesBySymbol := make(map[string][]database.Price)
+	for _, price := range prices {
+		closesBySymbol[price.Symbol] = append(closesBySymbol[price.Symbol], price)
+	}
+
+	byAccountDate := make(map[string]map[time.Time][]database.DailyHolding)
+	for _, holding := range daily {
+		date := calendarDay(holding.Date.Time)
+		if byAccountDate[holding.AccountID] == nil {
+			byAccountDate[holding.AccountID] = make(map[time.Time][]database.DailyHolding)
+		}
+		byAccountDate[holding.AccountID][date] = append(byAccountDate[holding.AccountID][date], holding)
+	}
+	accountIDs := make([]string, 0, len(byAccountDate))
+	for accountID := range byAccountDate {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	var filled []database.DailyHolding
+	for _, accountID := range accountIDs {
+		byDate := byAccountDate[accountID]
+
+		// Finds the latest known day before the range.
+		var knownDate time.Time
+		for date := range byDate {
+			if date.Before(from) && date.After(knownDate) {
+				knownDate = date
+			}
+		}
+
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			if _, ok := byDate[date]; ok {
+				knownDate = date
+				continue
+			}
+			if knownDate.IsZero() {
+				continue
+			}
+			rows, ok := revalueDay(byDate[knownDate], knownDate, date, closesBySymbol)
+			if ok {
+				filled = append(filled, rows...)
+			}
+		}
+	}
+	return filled
+}
+
+// Revalues one account's known holdings on a later day. Returns false when any holding can't be priced.
+func revalueDay(known []database.DailyHolding, knownDate, date time.Time, closesBySymbol map[string][]database.Price) ([]database.DailyHolding, bool) {
+	rows := make([]database.DailyHolding, 0, len(known))
+	for _, holding := range known {
+		row := database.DailyHolding{
+			Date:           database.DateOnly{Time: date},
+			AccountID:      holding.AccountID,
+			Symbol:         holding.Symbol,
+			Quantity:       holding.Quantity,
+			ValueCents:     holding.ValueCents,
+			CostBasisCents: holding.CostBasisCents,
+		}
+		if !isCashLikeHolding(holding) {
+			price, ok := priceOnOrBefore(closesBySymbol[holding.Symbol], date)
+			closeDate := calendarDay(price.Date.Time)
+			if !ok || date.Sub(closeDate) > revalueMaxPriceAgeDays*24*time.Hour {
+				return nil, false
+			}
+			if closeDate.After(knownDate) {
+				row.ValueCents = int64(math.Round(holding.Quantity * float64(price.CloseCents)))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+// Reports whether a holding is worth $1 a share (cash and money market sweeps), so its value carries forward.
+func isCashLikeHolding(holding database.DailyHolding) bool {
+	if isMoneyMarketSymbol(holding.Symbol) {
+		return true
+	}
+	return holding.Quantity != 0 && math.Abs(float64(holding.ValueCents)-holding.Quantity*100) <= 1
+}
+
+// Converts the close prices Plaid reports with holdings into our DB model, dated by the price's as-of date
+// (or the holdings date when Plaid doesn't give one).
+func plaidClosePricesToDB(securities []plaid.PlaidSecurity, date time.Time) []database.Price {
+	seen := make(map[string]bool, len(securities))
+	prices := make([]database.Price, 0, len(securities))
+	for _, security := range securities {
+		symbol := securitySymbol(security)
+		if security.ClosePrice <= 0 || security.Ticker == nil || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		closeDate := calendarDay(date)
+		if security.ClosePriceAsOf != nil {
+			if asOf, err := time.Parse(dateLayout, *security.ClosePriceAsOf); err == nil {
+				closeDate = asOf
+			}
+		}
+		prices = append(prices, database.Price{
+			Symbol:     symbol,
+			Date:       database.DateOnly{Time: closeDate},
+			CloseCents: int64(math.Round(security.ClosePrice * 100)),
+			Source:     priceSourcePlaid,
+		})
+	}
+	return prices
+}
+
+// Revaluation response.
+type revalueResponse struct {
+	FilledHoldings int      `json:"filledHoldings"`
+	FilledDates    []string `json:"filledDates"`
+	PricesFetched  int      `json:"pricesFetched"`
+}
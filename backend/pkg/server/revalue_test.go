@@ -0,0 +1,107 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
+)
+
+func TestRevalueHoldings(t *testing.T) {
+	holding := func(account string, d int, symbol string, quantity float64, cents int64) database.DailyHolding {
+		return database.DailyHolding{Date: database.DateOnly{Time: utcDay(2026, 3, d)}, AccountID: account, Symbol: symbol, Quantity: quantity, ValueCents: cents}
+	}
+	price := func(symbol string, d int, cents int64) database.Price {
+		return database.Price{Symbol: symbol, Date: database.DateOnly{Time: utcDay(2026, 3, d)}, CloseCents: cents}
+	}
+	daily := []database.DailyHolding{
+		// Friday the 6th is known; the 9th and 10th were missed; the 11th is known again.
+		holding("brokerage", 6, "VTI", 10, 300000),
+		holding("brokerage", 6, "SPAXX", 500, 50000),
+		holding("brokerage", 11, "VTI", 10, 320000),
+		holding("brokerage", 11, "SPAXX", 500, 50000),
+		// No close for XYZ, so the IRA can't be revalued.
+		holding("ira", 6, "XYZ", 1, 1000),
+	}
+	prices := []database.Price{price("VTI", 6, 30000), price("VTI", 9, 31000), price("VTI", 10, 31500)}
+
+	got := revalueHoldings(daily, prices, utcDay(2026, 3, 7), utcDay(2026, 3, 11))
+	want := []database.DailyHolding{
+		// The weekend has no newer close, so the known value is kept.
+		holding("brokerage", 7, "VTI", 10, 300000),
+		holding("brokerage", 7, "SPAXX", 500, 50000),
+		holding("brokerage", 8, "VTI", 10, 300000),
+		holding("brokerage", 8, "SPAXX", 500, 50000),
+		holding("brokerage", 9, "VTI", 10, 310000),
+		holding("brokerage", 9, "SPAXX", 500, 50000),
+		holding("brokerage", 10, "VTI", 10, 315000),
+		holding("brokerage", 10, "SPAXX", 500, 50000),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("filled = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !got[i].Date.Equal(want[i].Date.Time) || got[i].AccountID != want[i].AccountID || got[i].Symbol != want[i].Symbol ||
+			got[i].Quantity != want[i].Quantity || got[i].ValueCents != want[i].ValueCents {
+			t.Errorf("holding %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRevalueHoldingsStalePrice(t *testing.T) {
+	daily := []database.DailyHolding{
+		{Date: database.DateOnly{Time: utcDay(2026, 3, 2)}, AccountID: "brokerage", Symbol: "VTI", Quantity: 10, ValueCents: 300000},
+	}
+	prices := []database.Price{{Symbol: "VTI", Date: database.DateOnly{Time: utcDay(2026, 3, 2)}, CloseCents: 30000}}
+
+	got := revalueHoldings(daily, prices, utcDay(2026, 3, 8), utcDay(2026, 3, 8))
+	if len(got) != 0 {
+		t.Errorf("filled = %+v, want none for a close older than %d days", got, revalueMaxPriceAgeDays)
+	}
+}
+
+func TestParseRevalueRange(t *testing.T) {
+	today := utcDay(2026, 3, 20)
+	tests := []struct {
+		name     string
+		from, to string
+		want     [2]time.Time
+		wantErr  bool
+	}{
+		{name: "default", want: [2]time.Time{utcDay(2026, 3, 13), utcDay(2026, 3, 19)}},
+		{name: "clamped", from: "2026-01-01", to: "2026-03-25", want: [2]time.Time{utcDay(2026, 2, 18), utcDay(2026, 3, 19)}},
+		{name: "explicit", from: "2026-03-01", to: "2026-03-05", want: [2]time.Time{utcDay(2026, 3, 1), utcDay(2026, 3, 5)}},
+		{name: "reversed", from: "2026-03-05", to: "2026-03-01", wantErr: true},
+		{name: "bad date", from: "03/01/2026", wantErr: true},
+	}
+	for _, tt := range tests {
+		from, to, err := parseRevalueRange(tt.from, tt.to, today)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (!from.Equal(tt.want[0]) || !to.Equal(tt.want[1])) {
+			t.Errorf("%s: range = %s..%s, want %s..%s", tt.name, from.Format(dateLayout), to.Format(dateLayout),
+				tt.want[0].Format(dateLayout), tt.want[1].Format(dateLayout))
+		}
+	}
+}
+
+func TestPlaidClosePricesToDB(t *testing.T) {
+	ticker, asOf := "VTI", "2026-03-06"
+	name := "Cash"
+	securities := []plaid.PlaidSecurity{
+		{SecurityID: "s1", Ticker: &ticker, ClosePrice: 301.255, ClosePriceAsOf: &asOf},
+		{SecurityID: "s2", Ticker: &ticker, ClosePrice: 299},
+		{SecurityID: "s3", Name: &name, ClosePrice: 1},
+	}
+
+	got := plaidClosePricesToDB(securities, utcDay(2026, 3, 7))
+	if len(got) != 1 {
+		t.Fatalf("prices = %+v, want one", got)
+	}
+	if got[0].Symbol != "VTI" || !got[0].Date.Equal(utcDay(2026, 3, 6)) || got[0].CloseCents != 30126 || got[0].Source != priceSourcePlaid {
+		t.Errorf("price = %+v, want VTI 2026-03-06 30126 from plaid", got[0])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/marketdata"
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/plaid"
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
 	// "github.com/matthewtzong/portfolio-tracker/backend/pkg/snaptrade"
@@ -69,6 +70,14 @@ func NewHandler() (http.Handler, error) {
 		plaidClient = client
 	}
 
+	// Initialize the price provider for benchmark closes
+	var priceProvider marketdata.PriceProvider
+	if provider, err := marketdata.NewProviderFromEnv(); err != nil {
+		log.Printf("price provider not configured: %v", err)
+	} else {
+		priceProvider = provider
+	}
+
 	/*
 		// Initialize Snaptrade client
 		var snaptradeClient *snaptrade.Client
@@ -93,16 +102,24 @@ func NewHandler() (http.Handler, error) {
 	})))
 
 	deps := apiDependencies{
-		db:          dbClient,
-		plaidClient: plaidClient,
+		db:            dbClient,
+		plaidClient:   plaidClient,
+		priceProvider: priceProvider,
 		// snaptradeClient: snaptradeClient,
 	}
 
 	// Register all routes
 	registerLinkManagementRoutes(mux, deps)
 	registerAccountsRoutes(mux, deps)
+	registerLiabilitiesRoutes(mux, deps)
 	registerTransactionsRoutes(mux, deps)
+	registerBudgetRoutes(mux, deps)
+	registerWebhookRoutes(mux, deps)
 	registerPortfolioRoutes(mux, deps)
+	registerTaxRoutes(mux, deps)
+	registerBenchmarkRoutes(mux, deps)
+	registerRevalueRoutes(mux, deps)
+	registerRecomputeRoutes(mux, deps)
 	registerCronRoutes(mux, deps)
 	registerExportRoutes(mux, deps)
 	registerFidelityRoutes(mux, deps)
@@ -0,0 +1,426 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Lists sinking funds with their progress.
+func handleListSinkingFunds(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	funds, err := deps.db.ListSinkingFunds(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	output, err := loadSinkingFundProgress(r.Context(), deps.db, funds, GetLocalNow())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := sinkingFundsResponse{SinkingFunds: output}
+	for _, fund := range output {
+		resp.TotalRequiredMonthlyCents += fund.RequiredMonthlyCents
+	}
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("list sinking funds encode: %v", err)
+	}
+}
+
+// Creates a sinking fund, or updates the one named by the {id} path value.
+func handleSaveSinkingFund(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	// Checks the fund exists when updating.
+	var id int64
+	var existing *database.SinkingFund
+	if idStr := r.PathValue("id"); idStr != "" {
+		parsed, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid sinking fund id")
+			return
+		}
+		existing, err = deps.db.GetSinkingFund(r.Context(), parsed)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if existing == nil {
+			writeJSONError(w, http.StatusNotFound, "sinking fund not found")
+			return
+		}
+		id = parsed
+	}
+
+	var req sinkingFundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	categories, err := deps.db.ListCategories(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// New funds start this month; updates keep the stored start month unless the request sets one.
+	now := GetLocalNow()
+	defaultStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, GetLocalLocation())
+	if existing != nil {
+		defaultStart = existing.StartMonth.Time
+	}
+	fund, problems := sinkingFundFromRequest(req, categories, defaultStart)
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(budgetValidationResponse{Error: "invalid sinking fund", Problems: problems})
+		return
+	}
+	fund.ID = id
+	fund.UpdatedAt = &now
+
+	saved, err := deps.db.SaveSinkingFund(r.Context(), &fund)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSinkingFund(w, r.Context(), deps.db, *saved, http.StatusOK)
+}
+
+// Deletes a sinking fund.
+func handleDeleteSinkingFund(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid sinking fund id")
+		return
+	}
+	if err := deps.db.DeleteSinkingFund(r.Context(), id); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Records a manual contribution (or a withdrawal, if negative) to a sinking fund.
+func handleAddSinkingFundContribution(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid sinking fund id")
+		return
+	}
+	fund, err := deps.db.GetSinkingFund(r.Context(), id)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if fund == nil {
+		writeJSONError(w, http.StatusNotFound, "sinking fund not found")
+		return
+	}
+
+	var req sinkingFundContributionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.AmountCents == 0 {
+		writeJSONError(w, http.StatusBadRequest, "amountCents must be non-zero")
+		return
+	}
+	now := GetLocalNow()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, GetLocalLocation())
+	if req.Date != "" {
+		date, err = time.ParseInLocation(dateLayout, req.Date, GetLocalLocation())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+			return
+		}
+	}
+
+	contribution := &database.SinkingFundContribution{
+		FundID:      fund.ID,
+		Date:        database.DateOnly{Time: date},
+		AmountCents: req.AmountCents,
+		Note:        req.Note,
+	}
+	if err := deps.db.InsertSinkingFundContribution(r.Context(), contribution); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeSinkingFund(w, r.Context(), deps.db, *fund, http.StatusCreated)
+}
+
+// Writes one fund with its progress and the given status; the status is only written once the progress loads.
+func writeSinkingFund(w http.ResponseWriter, ctx context.Context, db *database.Client, fund database.SinkingFund, status int) {
+	output, err := loadSinkingFundProgress(ctx, db, []database.SinkingFund{fund}, GetLocalNow())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(output[0])
+	if err != nil {
+		log.Printf("sinking fund encode: %v", err)
+	}
+}
+
+// Validates a create/update request and converts it into our DB model. defaultStart is used when the request
+// has no startMonth.
+func sinkingFundFromRequest(req sinkingFundRequest, categories []database.Category, defaultStart time.Time) (database.SinkingFund, []budgetProblem) {
+	var problems []budgetProblem
+	fund := database.SinkingFund{
+		Name:        strings.TrimSpace(req.Name),
+		TargetCents: req.TargetCents,
+		CategoryIDs: []int64{},
+		StartMonth:  database.DateOnly{Time: defaultStart},
+	}
+
+	if fund.Name == "" {
+		problems = append(problems, budgetProblem{Field: "name", Code: "required", Message: "name is required"})
+	}
+	if req.TargetCents <= 0 {
+		problems = append(problems, budgetProblem{Field: "targetCents", Code: "invalid", Message: "targetCents must be greater than 0"})
+	}
+	dueDate, err := time.ParseInLocation(dateLayout, req.DueDate, GetLocalLocation())
+	if err != nil {
+		problems = append(problems, budgetProblem{Field: "dueDate", Code: "invalid", Message: "dueDate must be YYYY-MM-DD"})
+	}
+	fund.DueDate = database.DateOnly{Time: dueDate}
+	if req.StartMonth != "" {
+		start, err := parseBudgetMonth(req.StartMonth)
+		if err != nil {
+			problems = append(problems, budgetProblem{Field: "startMonth", Code: "invalid", Message: "startMonth must be YYYY-MM"})
+		}
+		fund.StartMonth = database.DateOnly{Time: start}
+	}
+
+	// Designated categories must exist.
+	known := make(map[int64]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	seen := make(map[int64]bool, len(req.CategoryIDs))
+	for _, categoryID := range req.CategoryIDs {
+		if !known[categoryID] {
+			id := categoryID
+			problems = append(problems, budgetProblem{Field: "categoryIds", CategoryID: &id, Code: "unknown_category", Message: "category " + strconv.FormatInt(categoryID, 10) + " does not exist"})
+			continue
+		}
+		if !seen[categoryID] {
+			seen[categoryID] = true
+			fund.CategoryIDs = append(fund.CategoryIDs, categoryID)
+		}
+	}
+	return fund, problems
+}
+
+// Computes saved amounts and progress for the funds: manual contributions plus outflows in each fund's
+// designated categories since its start month (older months come from monthly expense and transfer summaries).
+func loadSinkingFundProgress(ctx context.Context, db *database.Client, funds []database.SinkingFund, now time.Time) ([]sinkingFundJSON, error) {
+	output := make([]sinkingFundJSON, 0, len(funds))
+	if len(funds) == 0 {
+		return output, nil
+	}
+
+	contributions, err := db.ListSinkingFundContributions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manualByFund := make(map[int64]int64)
+	for _, contribution := range contributions {
+		manualByFund[contribution.FundID] += contribution.AmountCents
+	}
+
+	// Loads category activity from the earliest start month of funds with designated categories.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, GetLocalLocation())
+	var earliest time.Time
+	for _, fund := range funds {
+		if len(fund.CategoryIDs) > 0 && (earliest.IsZero() || fund.StartMonth.Before(earliest)) {
+			earliest = fund.StartMonth.Time
+		}
+	}
+	var transactions []database.Transaction
+	var summaries []database.MonthlyExpenseSummary
+	var transferSummaries []database.MonthlyTransferSummary
+	cutoff := transactionRetentionCutoff(now)
+	if !earliest.IsZero() {
+		liveStart := earliest
+		if liveStart.Before(cutoff) {
+			liveStart = cutoff
+			summaries, err = db.ListMonthlyExpenseSummaries(ctx, earliest, cutoff.AddDate(0, 0, -1))
+			if err != nil {
+				return nil, err
+			}
+			transferSummaries, err = db.ListMonthlyTransferSummaries(ctx, earliest, cutoff.AddDate(0, 0, -1))
+			if err != nil {
+				return nil, err
+			}
+		}
+		transactions, err = db.ListTransactionsBetween(ctx, liveStart, today.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fund := range funds {
+		categoryCents := sinkingFundCategoryContributions(fund, transactions, summaries, transferSummaries, cutoff)
+		saved := manualByFund[fund.ID] + categoryCents
+		status := sinkingFundProgress(fund.TargetCents, saved, fund.DueDate.Time, today)
+		categoryIDs := fund.CategoryIDs
+		if categoryIDs == nil {
+			categoryIDs = []int64{}
+		}
+		output = append(output, sinkingFundJSON{
+			ID:                         fund.ID,
+			Name:                       fund.Name,
+			TargetCents:                fund.TargetCents,
+			DueDate:                    fund.DueDate.Format(dateLayout),
+			StartMonth:                 fund.StartMonth.Format(budgetMonthLayout),
+			CategoryIDs:                categoryIDs,
+			SavedCents:                 saved,
+			ManualContributionsCents:   manualByFund[fund.ID],
+			CategoryContributionsCents: categoryCents,
+			RemainingCents:             status.RemainingCents,
+			MonthsLeft:                 status.MonthsLeft,
+			RequiredMonthlyCents:       status.RequiredMonthlyCents,
+			FundedPercent:              status.FundedPercent,
+		})
+	}
+	return output, nil
+}
+
+// Sums outflows in the fund's designated categories from its start month: live transactions on or after
+// the retention cutoff, expense and transfer summaries before it.
+func sinkingFundCategoryContributions(fund database.SinkingFund, transactions []database.Transaction, summaries []database.MonthlyExpenseSummary, transferSummaries []database.MonthlyTransferSummary, cutoff time.Time) int64 {
+	if len(fund.CategoryIDs) == 0 {
+		return 0
+	}
+	designated := make(map[int64]bool, len(fund.CategoryIDs))
+	for _, categoryID := range fund.CategoryIDs {
+		designated[categoryID] = true
+	}
+	startKey := fund.StartMonth.Format(dateLayout)
+	cutoffKey := cutoff.Format(dateLayout)
+
+	var total int64
+	for _, transaction := range transactions {
+		dateKey := transaction.Date.Format(dateLayout)
+		if transaction.CategoryID == nil || !designated[*transaction.CategoryID] || dateKey < startKey || dateKey < cutoffKey {
+			continue
+		}
+		total -= transaction.AmountCents
+	}
+	for _, summary := range summaries {
+		monthKey := summary.Month.Format(dateLayout)
+		if designated[summary.CategoryID] && monthKey >= startKey && monthKey < cutoffKey {
+			total += summary.TotalCents
+		}
+	}
+	for _, summary := range transferSummaries {
+		monthKey := summary.Month.Format(dateLayout)
+		if designated[summary.CategoryID] && monthKey >= startKey && monthKey < cutoffKey {
+			total += summary.TotalCents
+		}
+	}
+	return total
+}
+
+// Computes what is left to save, the months left (this month through the due month) and the
+// monthly contribution needed to reach the target on time.
+func sinkingFundProgress(targetCents, savedCents int64, dueDate, today time.Time) sinkingFundStatus {
+	status := sinkingFundStatus{RemainingCents: max(targetCents-savedCents, 0)}
+	if targetCents > 0 {
+		status.FundedPercent = math.Round(float64(savedCents)*1000/float64(targetCents)) / 10
+	}
+	if !dueDate.Before(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, dueDate.Location())) {
+		status.MonthsLeft = (dueDate.Year()-today.Year())*12 + int(dueDate.Month()) - int(today.Month()) + 1
+	}
+
+	switch {
+	case status.RemainingCents == 0:
+		status.RequiredMonthlyCents = 0
+	case status.MonthsLeft <= 0:
+		// Overdue: everything left is needed now.
+		status.RequiredMonthlyCents = status.RemainingCents
+	default:
+		months := int64(status.MonthsLeft)
+		status.RequiredMonthlyCents = (status.RemainingCents + months - 1) / months
+	}
+	return status
+}
+
+// Sinking fund create/update request.
+type sinkingFundRequest struct {
+	Name        string  `json:"name"`
+	TargetCents int64   `json:"targetCents"`
+	DueDate     string  `json:"dueDate"`
+	StartMonth  string  `json:"startMonth,omitempty"`
+	CategoryIDs []int64 `json:"categoryIds"`
+}
+
+// Sinking fund contribution request.
+type sinkingFundContributionRequest struct {
+	Date        string  `json:"date,omitempty"`
+	AmountCents int64   `json:"amountCents"`
+	Note        *string `json:"note,omitempty"`
+}
+
+// Progress toward a sinking fund's target.
+type sinkingFundStatus struct {
+	RemainingCents       int64
+	MonthsLeft           int
+	RequiredMonthlyCents int64
+	FundedPercent        float64
+}
+
+// Sinking fund for API.
+type sinkingFundJSON struct {
+	ID                         int64   `json:"id"`
+	Name                       string  `json:"name"`
+	TargetCents                int64   `json:"targetCents"`
+	DueDate                    string  `json:"dueDate"`
+	StartMonth                 string  `json:"startMonth"`
+	CategoryIDs                []int64 `json:"categoryIds"`
+	SavedCents                 int64   `json:"savedCents"`
+	ManualContributionsCents   int64   `json:"manualContributionsCents"`
+	CategoryContributionsCents int64   `json:"categoryContributionsCents"`
+	RemainingCents             int64   `json:"remainingCents"`
+	MonthsLeft                 int     `json:"monthsLeft"`
+	RequiredMonthlyCents       int64   `json:"requiredMonthlyCents"`
+	FundedPercent              float64 `json:"fundedPercent"`
+}
+
+// Sinking funds list response.
+type sinkingFundsResponse struct {
+	SinkingFunds              []sinkingFundJSON `json:"sinkingFunds"`
+	TotalRequiredMonthlyCents int64             `json:"totalRequiredMonthlyCents"`
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestSinkingFundProgress(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	today := date(2026, time.October, 18)
+
+	tests := []struct {
+		name   string
+		target int64
+		saved  int64
+		due    time.Time
+		want   sinkingFundStatus
+	}{
+		{
+			name:   "due in three months",
+			target: 120000, saved: 30000, due: date(2026, time.December, 15),
+			want: sinkingFundStatus{RemainingCents: 90000, MonthsLeft: 3, RequiredMonthlyCents: 30000, FundedPercent: 25},
+		},
+		{
+			name:   "rounds the monthly amount up",
+			target: 100000, saved: 0, due: date(2026, time.December, 1),
+			want: sinkingFundStatus{RemainingCents: 100000, MonthsLeft: 3, RequiredMonthlyCents: 33334, FundedPercent: 0},
+		},
+		{
+			name:   "due today counts this month",
+			target: 50000, saved: 10000, due: today,
+			want: sinkingFundStatus{RemainingCents: 40000, MonthsLeft: 1, RequiredMonthlyCents: 40000, FundedPercent: 20},
+		},
+		{
+			name:   "past due needs everything now",
+			target: 50000, saved: 10000, due: date(2026, time.September, 30),
+			want: sinkingFundStatus{RemainingCents: 40000, MonthsLeft: 0, RequiredMonthlyCents: 40000, FundedPercent: 20},
+		},
+		{
+			name:   "overfunded",
+			target: 40000, saved: 50000, due: date(2027, time.March, 1),
+			want: sinkingFundStatus{RemainingCents: 0, MonthsLeft: 6, RequiredMonthlyCents: 0, FundedPercent: 125},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sinkingFundProgress(tt.target, tt.saved, tt.due, today)
+			if got != tt.want {
+				t.Errorf("sinkingFundProgress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinkingFundCategoryContributions(t *testing.T) {
+	day := func(m time.Month, d int) database.DateOnly {
+		return database.DateOnly{Time: time.Date(2026, m, d, 0, 0, 0, 0, time.UTC)}
+	}
+	travel, food := int64(7), int64(8)
+	fund := database.SinkingFund{
+		StartMonth:  day(time.January, 1),
+		CategoryIDs: []int64{travel},
+	}
+	cutoff := day(time.March, 1).Time
+	transactions := []database.Transaction{
+		{Date: day(time.March, 10), AmountCents: -20000, CategoryID: &travel},
+		{Date: day(time.April, 2), AmountCents: 5000, CategoryID: &travel},
+		{Date: day(time.April, 3), AmountCents: -9999, CategoryID: &food},
+		{Date: day(time.April, 4), AmountCents: -9999},
+	}
+	summaries := []database.MonthlyExpenseSummary{
+		{Month: day(time.January, 1), CategoryID: travel, TotalCents: 10000},
+		{Month: day(time.February, 1), CategoryID: food, TotalCents: 9999},
+		// Overlaps live transactions and is ignored.
+		{Month: day(time.March, 1), CategoryID: travel, TotalCents: 20000},
+	}
+
+	got := sinkingFundCategoryContributions(fund, transactions, summaries, nil, cutoff)
+	if want := int64(25000); got != want {
+		t.Errorf("sinkingFundCategoryContributions() = %d, want %d", got, want)
+	}
+
+	fund.CategoryIDs = nil
+	if got := sinkingFundCategoryContributions(fund, transactions, summaries, nil, cutoff); got != 0 {
+		t.Errorf("sinkingFundCategoryContributions() without categories = %d, want 0", got)
+	}
+}
+
+func TestSinkingFundCategoryContributionsTransferCategory(t *testing.T) {
+	day := func(m time.Month, d int) database.DateOnly {
+		return database.DateOnly{Time: time.Date(2026, m, d, 0, 0, 0, 0, time.UTC)}
+	}
+	// A transfer (non-expense) category, so pruned months are in the transfer summary only.
+	savings := int64(12)
+	fund := database.SinkingFund{
+		StartMonth:  day(time.January, 1),
+		CategoryIDs: []int64{savings},
+	}
+	cutoff := day(time.March, 1).Time
+	transactions := []database.Transaction{
+		{Date: day(time.March, 5), AmountCents: -30000, CategoryID: &savings},
+	}
+	transferSummaries := []database.MonthlyTransferSummary{
+		{Month: day(time.January, 1), CategoryID: savings, TotalCents: 30000},
+		{Month: day(time.February, 1), CategoryID: savings, TotalCents: 30000},
+		// Overlaps live transactions and is ignored.
+		{Month: day(time.March, 1), CategoryID: savings, TotalCents: 30000},
+	}
+
+	got := sinkingFundCategoryContributions(fund, transactions, nil, transferSummaries, cutoff)
+	if want := int64(90000); got != want {
+		t.Errorf("sinkingFundCategoryContributions() = %d, want %d", got, want)
+	}
+}
+
+func TestSinkingFundFromRequest(t *testing.T) {
+	categories := []database.Category{{ID: 1, Name: "Travel", Expense: true}}
+	defaultStart := time.Date(2026, time.October, 1, 0, 0, 0, 0, GetLocalLocation())
+
+	fund, problems := sinkingFundFromRequest(sinkingFundRequest{
+		Name: " Vacation ", TargetCents: 300000, DueDate: "2027-06-01", CategoryIDs: []int64{1, 1},
+	}, categories, defaultStart)
+	if len(problems) != 0 {
+		t.Fatalf("problems = %+v, want none", problems)
+	}
+	if fund.Name != "Vacation" || fund.StartMonth.Format(budgetMonthLayout) != "2026-10" || len(fund.CategoryIDs) != 1 {
+		t.Errorf("fund = %+v", fund)
+	}
+
+	fund, _ = sinkingFundFromRequest(sinkingFundRequest{
+		Name: "Vacation", TargetCents: 300000, DueDate: "2027-06-01", StartMonth: "2026-03",
+	}, categories, defaultStart)
+	if fund.StartMonth.Format(budgetMonthLayout) != "2026-03" {
+		t.Errorf("start month = %s, want the requested 2026-03", fund.StartMonth.Format(budgetMonthLayout))
+	}
+
+	_, problems = sinkingFundFromRequest(sinkingFundRequest{
+		TargetCents: 0, DueDate: "June", StartMonth: "2026-13", CategoryIDs: []int64{9},
+	}, categories, defaultStart)
+	var fields []string
+	for _, problem := range problems {
+		fields = append(fields, problem.Field)
+	}
+	want := []string{"name", "targetCents", "dueDate", "startMonth", "categoryIds"}
+	if len(fields) != len(want) {
+		t.Fatalf("problem fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("problem fields = %v, want %v", fields, want)
+			break
+		}
+	}
+}
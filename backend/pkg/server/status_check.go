@@ -59,6 +59,11 @@ func checkAndUpdatePlaidItemStatuses(ctx context.Context, db *database.Client, p
 			continue
 		}
 
+		// Keeps a PENDING_EXPIRATION set by webhook while consent is still due to expire.
+		if items[i].Status == "PENDING_EXPIRATION" && itemStatus.ConsentExpirationTime != nil {
+			continue
+		}
+
 		// Healthy: no embedded auth error – mark as OK.
 		if items[i].Status != "OK" {
 			items[i].Status = "OK"
@@ -0,0 +1,663 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Date layouts accepted in broker lot CSVs.
+var brokerLotDateLayouts = []string{"01/02/2006", "1/2/2006", dateLayout, "Jan-02-2006", "Jan 2, 2006"}
+
+// Registers the tax routes.
+func registerTaxRoutes(mux *http.ServeMux, deps apiDependencies) {
+	// GET /api/tax/lots lists open lots, filterable by accountId and symbol.
+	mux.Handle("/api/tax/lots", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetTaxLots(w, r, deps)
+	})))
+
+	// POST /api/tax/lots/upload replaces an account's imported lots with a broker lot CSV.
+	mux.Handle("/api/tax/lots/upload", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleUploadTaxLots(w, r, deps)
+	})))
+
+	// PUT /api/tax/lots/selections sets the lots a sale is matched against (specific identification).
+	mux.Handle("/api/tax/lots/selections", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			methodNotAllowed(w, http.MethodPut)
+			return
+		}
+		handleUpdateTaxLotSelections(w, r, deps)
+	})))
+
+	// GET/PUT /api/tax/settings reads or sets an account's lot selection method.
+	mux.Handle("/api/tax/settings", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetTaxSettings(w, r, deps)
+		case http.MethodPut:
+			handleUpdateTaxSettings(w, r, deps)
+		default:
+			methodNotAllowed(w, http.MethodGet)
+		}
+	})))
+
+	// GET /api/tax/realized?year=YYYY returns short- and long-term realized gains per account.
+	mux.Handle("/api/tax/realized", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleGetRealizedGains(w, r, deps)
+	})))
+
+	// GET /api/export/tax/8949?year=YYYY exports the year's realized lots as a Form 8949-style CSV.
+	mux.Handle("/api/export/tax/8949", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleExportForm8949(w, r, deps)
+	})))
+}
+
+// Returns the open lots.
+func handleGetTaxLots(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	ledger, err := loadTaxLedger(r.Context(), deps.db)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to build tax lots: "+err.Error())
+		return
+	}
+	accountNames := loadAccountNames(r.Context(), deps.db)
+
+	accountID := r.URL.Query().Get("accountId")
+	symbol := r.URL.Query().Get("symbol")
+	today := calendarDay(GetLocalNow())
+	lots := make([]taxLotJSON, 0, len(ledger.Open))
+	for _, lot := range ledger.Open {
+		if (accountID != "" && lot.AccountID != accountID) || (symbol != "" && lot.Symbol != symbol) {
+			continue
+		}
+		term := "short"
+		if isLongTerm(lot.AcquiredDate, today) {
+			term = "long"
+		}
+		lots = append(lots, taxLotJSON{
+			ID:           lot.ID,
+			AccountID:    lot.AccountID,
+			AccountName:  accountNames[lot.AccountID],
+			Symbol:       lot.Symbol,
+			AcquiredDate: lot.AcquiredDate.Format(dateLayout),
+			Quantity:     lot.Quantity,
+			CostCents:    lot.CostCents,
+			Term:         term,
+		})
+	}
+
+	err = json.NewEncoder(w).Encode(taxLotsResponse{Lots: lots})
+	if err != nil {
+		log.Printf("tax lots encode: %v", err)
+	}
+}
+
+// Imports a broker lot CSV for the account in the "accountId" form field, replacing its earlier import.
+func handleUploadTaxLots(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to get file from request")
+		return
+	}
+	defer file.Close()
+	accountID := strings.TrimSpace(r.FormValue("accountId"))
+	if accountID == "" {
+		writeJSONError(w, http.StatusBadRequest, "accountId is required")
+		return
+	}
+
+	lots, err := parseBrokerLotsCSV(file)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to parse CSV: "+err.Error())
+		return
+	}
+	for i := range lots {
+		lots[i].AccountID = accountID
+	}
+	assignBrokerTaxLotKeys(lots)
+
+	// Lots imported before lot keys existed are selected by row ID; their selections move to the new IDs.
+	existing, err := deps.db.ListBrokerTaxLots(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list lots: "+err.Error())
+		return
+	}
+	renames := legacyBrokerTaxLotRenames(existing, accountID, lots)
+
+	err = deps.db.ReplaceBrokerTaxLots(r.Context(), accountID, lots)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to save lots: "+err.Error())
+		return
+	}
+	for oldID, newID := range renames {
+		err = deps.db.RenameTaxLotSelections(r.Context(), oldID, newID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "failed to update lot selections: "+err.Error())
+			return
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(taxLotUploadResponse{Imported: len(lots)})
+}
+
+// Replaces the lot selections for a sale.
+func handleUpdateTaxLotSelections(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	var req taxLotSelectionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.SaleTransactionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "saleTransactionId is required")
+		return
+	}
+	selections := make([]database.TaxLotSelection, 0, len(req.Selections))
+	seen := make(map[string]bool, len(req.Selections))
+	for _, selection := range req.Selections {
+		if !strings.HasPrefix(selection.LotID, "txn:") && !strings.HasPrefix(selection.LotID, "csv:") {
+			writeJSONError(w, http.StatusBadRequest, "unknown lot id "+selection.LotID)
+			return
+		}
+		if selection.Quantity <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "quantity must be greater than 0")
+			return
+		}
+		if seen[selection.LotID] {
+			writeJSONError(w, http.StatusBadRequest, "lot "+selection.LotID+" is selected more than once")
+			return
+		}
+		seen[selection.LotID] = true
+		selections = append(selections, database.TaxLotSelection{
+			SaleTransactionID: req.SaleTransactionID,
+			LotID:             selection.LotID,
+			Quantity:          selection.Quantity,
+		})
+	}
+
+	err := deps.db.ReplaceTaxLotSelections(r.Context(), req.SaleTransactionID, selections)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(req)
+}
+
+// Returns each account's lot selection method.
+func handleGetTaxSettings(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	writeTaxSettings(w, r.Context(), deps.db)
+}
+
+// Sets an account's lot selection method.
+func handleUpdateTaxSettings(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+
+	var req taxSettingJSON
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Method = strings.ToLower(req.Method)
+	if req.AccountID == "" {
+		writeJSONError(w, http.StatusBadRequest, "accountId is required")
+		return
+	}
+	if !lotMethods[req.Method] {
+		writeJSONError(w, http.StatusBadRequest, "method must be one of fifo, lifo, hifo, specific")
+		return
+	}
+
+	now := GetLocalNow()
+	err := deps.db.UpsertTaxLotSetting(r.Context(), &database.TaxLotSetting{AccountID: req.AccountID, Method: req.Method, UpdatedAt: &now})
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeTaxSettings(w, r.Context(), deps.db)
+}
+
+// Writes the lot selection method for every account with one set.
+func writeTaxSettings(w http.ResponseWriter, ctx context.Context, db *database.Client) {
+	settings, err := db.ListTaxLotSettings(ctx)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	output := make([]taxSettingJSON, 0, len(settings))
+	for _, setting := range settings {
+		output = append(output, taxSettingJSON{AccountID: setting.AccountID, Method: setting.Method})
+	}
+	err = json.NewEncoder(w).Encode(taxSettingsResponse{DefaultMethod: lotMethodFIFO, Settings: output})
+	if err != nil {
+		log.Printf("tax settings encode: %v", err)
+	}
+}
+
+// Returns the year's realized gains by account and term, with each realized lot.
+func handleGetRealizedGains(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	year, err := parseTaxYear(r.URL.Query().Get("year"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ledger, err := loadTaxLedger(r.Context(), deps.db)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to build tax lots: "+err.Error())
+		return
+	}
+	accountNames := loadAccountNames(r.Context(), deps.db)
+	rows, byAccount := summarizeRealized(ledger.Realized, year)
+
+	resp := realizedGainsResponse{Year: year, Accounts: []realizedAccountJSON{}, Lots: []realizedLotJSON{}}
+	accountIDs := make([]string, 0, len(byAccount))
+	for accountID := range byAccount {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+	for _, accountID := range accountIDs {
+		summary := byAccount[accountID]
+		resp.Accounts = append(resp.Accounts, realizedAccountJSON{
+			AccountID:         accountID,
+			AccountName:       accountNames[accountID],
+			ShortTerm:         summary.ShortTerm,
+			LongTerm:          summary.LongTerm,
+			TotalGainCents:    summary.ShortTerm.GainCents + summary.LongTerm.GainCents,
+			UnknownBasisCount: summary.UnknownBasisCount,
+		})
+		resp.ShortTerm.ProceedsCents += summary.ShortTerm.ProceedsCents
+		resp.ShortTerm.CostCents += summary.ShortTerm.CostCents
+		resp.ShortTerm.WashSaleDisallowedCents += summary.ShortTerm.WashSaleDisallowedCents
+		resp.ShortTerm.GainCents += summary.ShortTerm.GainCents
+		resp.LongTerm.ProceedsCents += summary.LongTerm.ProceedsCents
+		resp.LongTerm.CostCents += summary.LongTerm.CostCents
+		resp.LongTerm.WashSaleDisallowedCents += summary.LongTerm.WashSaleDisallowedCents
+		resp.LongTerm.GainCents += summary.LongTerm.GainCents
+	}
+	resp.TotalGainCents = resp.ShortTerm.GainCents + resp.LongTerm.GainCents
+	for _, row := range rows {
+		output := realizedLotJSON{
+			SaleTransactionID:       row.SaleID,
+			AccountID:               row.AccountID,
+			AccountName:             accountNames[row.AccountID],
+			Symbol:                  row.Symbol,
+			LotID:                   row.LotID,
+			SoldDate:                row.SoldDate.Format(dateLayout),
+			Quantity:                row.Quantity,
+			ProceedsCents:           row.ProceedsCents,
+			CostCents:               row.CostCents,
+			GainCents:               row.GainCents,
+			Term:                    row.Term,
+			WashSale:                row.WashSale,
+			WashSaleDisallowedCents: row.WashSaleDisallowedCents,
+		}
+		if !row.AcquiredDate.IsZero() {
+			output.AcquiredDate = row.AcquiredDate.Format(dateLayout)
+		}
+		resp.Lots = append(resp.Lots, output)
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Printf("realized gains encode: %v", err)
+	}
+}
+
+// Exports the year's realized lots as a Form 8949-style CSV.
+func handleExportForm8949(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database is not configured")
+		return
+	}
+	year, err := parseTaxYear(r.URL.Query().Get("year"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ledger, err := loadTaxLedger(r.Context(), deps.db)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to build tax lots: "+err.Error())
+		return
+	}
+	rows, _ := summarizeRealized(ledger.Realized, year)
+	csvBytes, err := buildForm8949CSV(rows, loadAccountNames(r.Context(), deps.db))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to export Form 8949: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=form-8949-%d.csv", year))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(csvBytes)
+}
+
+// Loads the investment activity, imported lots, methods and selections, and builds the tax-lot ledger.
+func loadTaxLedger(ctx context.Context, db *database.Client) (taxLedger, error) {
+	transactions, err := db.ListInvestmentTransactionsSince(ctx, time.Date(2000, time.January, 1, 0, 0, 0, 0, GetLocalLocation()))
+	if err != nil {
+		return taxLedger{}, err
+	}
+	imported, err := db.ListBrokerTaxLots(ctx)
+	if err != nil {
+		return taxLedger{}, err
+	}
+	settings, err := db.ListTaxLotSettings(ctx)
+	if err != nil {
+		return taxLedger{}, err
+	}
+	methods := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		methods[setting.AccountID] = setting.Method
+	}
+	selectionRows, err := db.ListTaxLotSelections(ctx)
+	if err != nil {
+		return taxLedger{}, err
+	}
+	selections := make(map[string][]database.TaxLotSelection)
+	for _, selection := range selectionRows {
+		selections[selection.SaleTransactionID] = append(selections[selection.SaleTransactionID], selection)
+	}
+	return buildTaxLedger(transactions, imported, methods, selections), nil
+}
+
+// Returns Plaid account names by account ID (empty if they cannot be loaded).
+func loadAccountNames(ctx context.Context, db *database.Client) map[string]string {
+	plaidAccounts, _ := db.ListPlaidAccounts(ctx)
+	names := make(map[string]string, len(plaidAccounts))
+	for _, account := range plaidAccounts {
+		names[account.AccountID] = account.Name
+	}
+	return names
+}
+
+// Parses a required tax year.
+func parseTaxYear(yearStr string) (int, error) {
+	if yearStr == "" {
+		return 0, errors.New("year parameter is required (e.g. 2025)")
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 2000 || year > 2100 {
+		return 0, errors.New("year must be a valid 4-digit year")
+	}
+	return year, nil
+}
+
+// Parses a broker lot CSV. The header row must have symbol, acquired date, quantity and cost basis columns
+// (e.g. "Symbol", "Date Acquired", "Quantity", "Cost Basis Total"); rows without a symbol are skipped.
+func parseBrokerLotsCSV(r io.Reader) ([]database.BrokerTaxLot, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finds the header row and the columns we need.
+	symbolColumn, dateColumn, quantityColumn, costColumn := -1, -1, -1, -1
+	headerRow := -1
+	for i, row := range rows {
+		symbolColumn, dateColumn, quantityColumn, costColumn = -1, -1, -1, -1
+		for column, cell := range row {
+			name := strings.ToLower(strings.TrimSpace(cell))
+			switch {
+			case name == "symbol" || name == "ticker":
+				symbolColumn = column
+			case strings.Contains(name, "acquired") || name == "open date" || name == "date":
+				dateColumn = column
+			case name == "quantity" || name == "shares":
+				quantityColumn = column
+			case strings.Contains(name, "cost basis") && (costColumn < 0 || strings.Contains(name, "total")):
+				costColumn = column
+			}
+		}
+		if symbolColumn >= 0 && dateColumn >= 0 && quantityColumn >= 0 && costColumn >= 0 {
+			headerRow = i
+			break
+		}
+	}
+	if headerRow < 0 {
+		return nil, errors.New("missing header with symbol, date acquired, quantity and cost basis columns")
+	}
+
+	var lots []database.BrokerTaxLot
+	for i, row := range rows[headerRow+1:] {
+		lineNumber := headerRow + i + 2
+		cell := func(column int) string {
+			if column < len(row) {
+				return strings.TrimSpace(row[column])
+			}
+			return ""
+		}
+		symbol := strings.ToUpper(cell(symbolColumn))
+		if symbol == "" || strings.HasPrefix(symbol, "TOTAL") {
+			continue
+		}
+
+		acquired, err := parseBrokerLotDate(cell(dateColumn))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date acquired %q", lineNumber, cell(dateColumn))
+		}
+		quantity, err := strconv.ParseFloat(strings.ReplaceAll(cell(quantityColumn), ",", ""), 64)
+		if err != nil || quantity <= 0 || math.IsInf(quantity, 0) {
+			return nil, fmt.Errorf("line %d: invalid quantity %q", lineNumber, cell(quantityColumn))
+		}
+		lots = append(lots, database.BrokerTaxLot{
+			Symbol:         symbol,
+			AcquiredDate:   database.DateOnly{Time: acquired},
+			Quantity:       quantity,
+			CostBasisCents: parseCents(cell(costColumn)),
+		})
+	}
+	return lots, nil
+}
+
+// Sets each lot's key from a hash of its account, symbol, acquired date, quantity and cost basis, so the
+// same CSV row keeps its "csv:<key>" ID across imports. Identical rows are told apart by a counter.
+func assignBrokerTaxLotKeys(lots []database.BrokerTaxLot) {
+	seen := make(map[string]int, len(lots))
+	for i := range lots {
+		lot := &lots[i]
+		row := strings.Join([]string{
+			lot.AccountID,
+			lot.Symbol,
+			lot.AcquiredDate.Format(dateLayout),
+			strconv.FormatFloat(lot.Quantity, 'f', -1, 64),
+			strconv.FormatInt(lot.CostBasisCents, 10),
+		}, "|")
+		seen[row]++
+		sum := sha256.Sum256([]byte(row + "|" + strconv.Itoa(seen[row])))
+		lot.LotKey = hex.EncodeToString(sum[:8])
+	}
+}
+
+// Returns the lot ID used in the ledger and in lot selections.
+func brokerTaxLotID(lot database.BrokerTaxLot) string {
+	if lot.LotKey != "" {
+		return "csv:" + lot.LotKey
+	}
+	return "csv:" + strconv.FormatInt(lot.ID, 10)
+}
+
+// Maps the account's stored lots that have no key ("csv:<id>") to the ID the same row gets in the new
+// import, for rows the new import still has.
+func legacyBrokerTaxLotRenames(existing []database.BrokerTaxLot, accountID string, lots []database.BrokerTaxLot) map[string]string {
+	var legacy []database.BrokerTaxLot
+	for _, lot := range existing {
+		if lot.AccountID == accountID && lot.LotKey == "" {
+			legacy = append(legacy, lot)
+		}
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+	imported := make(map[string]bool, len(lots))
+	for _, lot := range lots {
+		imported[lot.LotKey] = true
+	}
+	keyed := append([]database.BrokerTaxLot(nil), legacy...)
+	assignBrokerTaxLotKeys(keyed)
+	renames := make(map[string]string, len(legacy))
+	for i, lot := range legacy {
+		if imported[keyed[i].LotKey] {
+			renames[brokerTaxLotID(lot)] = brokerTaxLotID(keyed[i])
+		}
+	}
+	return renames
+}
+
+// Parses a broker lot date in any of the accepted layouts.
+func parseBrokerLotDate(s string) (time.Time, error) {
+	for _, layout := range brokerLotDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// Open lot for API. Term is the holding period as of today.
+type taxLotJSON struct {
+	ID           string  `json:"id"`
+	AccountID    string  `json:"accountId"`
+	AccountName  string  `json:"accountName,omitempty"`
+	Symbol       string  `json:"symbol"`
+	AcquiredDate string  `json:"acquiredDate"`
+	Quantity     float64 `json:"quantity"`
+	CostCents    int64   `json:"costCents"`
+	Term         string  `json:"term"`
+}
+
+// Open lots response.
+type taxLotsResponse struct {
+	Lots []taxLotJSON `json:"lots"`
+}
+
+// Broker lot CSV upload response.
+type taxLotUploadResponse struct {
+	Imported int `json:"imported"`
+}
+
+// One lot chosen for a sale.
+type taxLotSelectionJSON struct {
+	LotID    string  `json:"lotId"`
+	Quantity float64 `json:"quantity"`
+}
+
+// Specific-identification request: the lots a sale should be matched against.
+type taxLotSelectionsRequest struct {
+	SaleTransactionID string                `json:"saleTransactionId"`
+	Selections        []taxLotSelectionJSON `json:"selections"`
+}
+
+// Account lot selection method for API.
+type taxSettingJSON struct {
+	AccountID string `json:"accountId"`
+	Method    string `json:"method"`
+}
+
+// Tax settings response.
+type taxSettingsResponse struct {
+	DefaultMethod string           `json:"defaultMethod"`
+	Settings      []taxSettingJSON `json:"settings"`
+}
+
+// Realized lot for API. Gain excludes the wash-sale adjustment.
+type realizedLotJSON struct {
+	SaleTransactionID       string  `json:"saleTransactionId"`
+	AccountID               string  `json:"accountId"`
+	AccountName             string  `json:"accountName,omitempty"`
+	Symbol                  string  `json:"symbol"`
+	LotID                   string  `json:"lotId,omitempty"`
+	AcquiredDate            string  `json:"acquiredDate,omitempty"`
+	SoldDate                string  `json:"soldDate"`
+	Quantity                float64 `json:"quantity"`
+	ProceedsCents           int64   `json:"proceedsCents"`
+	CostCents               int64   `json:"costCents"`
+	GainCents               int64   `json:"gainCents"`
+	Term                    string  `json:"term"`
+	WashSale                bool    `json:"washSale"`
+	WashSaleDisallowedCents int64   `json:"washSaleDisallowedCents"`
+}
+
+// Realized gains for one account.
+type realizedAccountJSON struct {
+	AccountID         string         `json:"accountId"`
+	AccountName       string         `json:"accountName,omitempty"`
+	ShortTerm         realizedTotals `json:"shortTerm"`
+	LongTerm          realizedTotals `json:"longTerm"`
+	TotalGainCents    int64          `json:"totalGainCents"`
+	UnknownBasisCount int            `json:"unknownBasisCount"`
+}
+
+// Realized gains response.
+type realizedGainsResponse struct {
+	Year           int                   `json:"year"`
+	ShortTerm      realizedTotals        `json:"shortTerm"`
+	LongTerm       realizedTotals        `json:"longTerm"`
+	TotalGainCents int64                 `json:"totalGainCents"`
+	Accounts       []realizedAccountJSON `json:"accounts"`
+	Lots           []realizedLotJSON     `json:"lots"`
+}
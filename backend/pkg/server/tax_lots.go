@@ -0,0 +1,518 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Lot selection methods for sales.
+const (
+	lotMethodFIFO     = "fifo"
+	lotMethodLIFO     = "lifo"
+	lotMethodHIFO     = "hifo"
+	lotMethodSpecific = "specific"
+)
+
+// Days before or after a loss sale in which acquiring the same security makes it a wash sale.
+const washSaleWindowDays = 30
+
+// Accepted lot selection methods.
+var lotMethods = map[string]bool{
+	lotMethodFIFO:     true,
+	lotMethodLIFO:     true,
+	lotMethodHIFO:     true,
+	lotMethodSpecific: true,
+}
+
+// Builds the tax-lot ledger: each buy or transfer in opens a lot, and each sale consumes lots using the
+// account's method (FIFO when unset). Imported broker lots seed a holding for shares acquired before its first
+// synced transaction. Specific identification uses the saved selections, then FIFO for any shares left over.
+// Loss sales are then checked for wash sales.
+func buildTaxLedger(transactions []database.InvestmentTransaction, imported []database.BrokerTaxLot, methods map[string]string, selections map[string][]database.TaxLotSelection) taxLedger {
+	transactionsByHolding := make(map[holdingKey][]database.InvestmentTransaction)
+	for _, transaction := range transactions {
+		if transaction.Symbol != nil {
+			key := holdingKey{AccountID: transaction.AccountID, Symbol: *transaction.Symbol}
+			transactionsByHolding[key] = append(transactionsByHolding[key], transaction)
+		}
+	}
+	importedByHolding := make(map[holdingKey][]database.BrokerTaxLot)
+	for _, lot := range imported {
+		key := holdingKey{AccountID: lot.AccountID, Symbol: lot.Symbol}
+		importedByHolding[key] = append(importedByHolding[key], lot)
+	}
+
+	keys := make([]holdingKey, 0, len(transactionsByHolding)+len(importedByHolding))
+	for key := range transactionsByHolding {
+		keys = append(keys, key)
+	}
+	for key := range importedByHolding {
+		if _, ok := transactionsByHolding[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].AccountID != keys[j].AccountID {
+			return keys[i].AccountID < keys[j].AccountID
+		}
+		return keys[i].Symbol < keys[j].Symbol
+	})
+
+	var ledger taxLedger
+	var acquisitions []taxLot
+	for _, key := range keys {
+		method := methods[key.AccountID]
+		if !lotMethods[method] {
+			method = lotMethodFIFO
+		}
+		open, realized, opened := replayHolding(key, transactionsByHolding[key], importedByHolding[key], method, selections)
+		ledger.Open = append(ledger.Open, open...)
+		ledger.Realized = append(ledger.Realized, realized...)
+		acquisitions = append(acquisitions, opened...)
+	}
+	flagWashSales(ledger.Realized, acquisitions)
+	return ledger
+}
+
+// Replays one holding's activity. Returns the lots still open, the lots realized by sales and every lot opened.
+func replayHolding(key holdingKey, transactions []database.InvestmentTransaction, imported []database.BrokerTaxLot, method string, selections map[string][]database.TaxLotSelection) ([]taxLot, []realizedLot, []taxLot) {
+	ordered := make([]database.InvestmentTransaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if !ordered[i].Date.Equal(ordered[j].Date.Time) {
+			return ordered[i].Date.Before(ordered[j].Date.Time)
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	// Seeds the imported lots acquired before the synced history begins.
+	var lots, opened []taxLot
+	for _, lot := range imported {
+		acquired := calendarDay(lot.AcquiredDate.Time)
+		if len(ordered) > 0 && !acquired.Before(calendarDay(ordered[0].Date.Time)) {
+			continue
+		}
+		lots = append(lots, taxLot{
+			ID:           brokerTaxLotID(lot),
+			AccountID:    key.AccountID,
+			Symbol:       key.Symbol,
+			AcquiredDate: acquired,
+			Quantity:     lot.Quantity,
+			CostCents:    lot.CostBasisCents,
+		})
+	}
+	sort.SliceStable(lots, func(i, j int) bool {
+		return lots[i].AcquiredDate.Before(lots[j].AcquiredDate)
+	})
+	opened = append(opened, lots...)
+
+	var realized []realizedLot
+	for _, transaction := range ordered {
+		transactionType := strings.ToLower(transaction.Type)
+		subtype := strings.ToLower(transaction.Subtype)
+		quantity := math.Abs(transaction.Quantity)
+		if quantity == 0 {
+			continue
+		}
+		date := calendarDay(transaction.Date.Time)
+		externalTransfer := transactionType == "transfer" && externalTransferSubtypes[subtype]
+
+		switch {
+		case transactionType == "buy", externalTransfer && transaction.Quantity > 0:
+			cost := -transaction.AmountCents
+			if cost <= 0 {
+				// In-kind transfers are valued at the transfer price.
+				cost = int64(math.Round(quantity * float64(transaction.PriceCents)))
+			}
+			lot := taxLot{
+				ID:           "txn:" + transaction.PlaidInvestmentTransactionID,
+				AccountID:    key.AccountID,
+				Symbol:       key.Symbol,
+				AcquiredDate: date,
+				Quantity:     quantity,
+				CostCents:    cost,
+			}
+			lots = append(lots, lot)
+			opened = append(opened, lot)
+
+		case transactionType == "sell":
+			var picks []lotPick
+			picks, lots = pickLots(lots, quantity, method, selections[transaction.PlaidInvestmentTransactionID])
+			realized = append(realized, realizeSale(transaction, key, date, quantity, picks)...)
+
+		case externalTransfer && transaction.Quantity < 0:
+			// Shares moved out keep their basis elsewhere; nothing is realized.
+			_, lots = pickLots(lots, quantity, lotMethodFIFO, nil)
+
+		case transactionType == "transfer" && subtype == "split":
+			lots = splitLots(lots, transaction.Quantity)
+		}
+	}
+	return lots, realized, opened
+}
+
+// Takes quantity from the lots in the method's order. Returns the shares taken from each lot (with their
+// share of its cost) and the lots left. Shares no lot covers are returned as a pick with no lot.
+func pickLots(lots []taxLot, quantity float64, method string, selected []database.TaxLotSelection) ([]lotPick, []taxLot) {
+	remaining := make([]taxLot, len(lots))
+	copy(remaining, lots)
+
+	// Orders the lots for the method; specific selections go first.
+	order := make([]int, len(remaining))
+	for i := range order {
+		order[i] = i
+	}
+	switch method {
+	case lotMethodLIFO:
+		sort.SliceStable(order, func(i, j int) bool {
+			return remaining[order[i]].AcquiredDate.After(remaining[order[j]].AcquiredDate)
+		})
+	case lotMethodHIFO:
+		sort.SliceStable(order, func(i, j int) bool {
+			return remaining[order[i]].costPerShare() > remaining[order[j]].costPerShare()
+		})
+	default:
+		sort.SliceStable(order, func(i, j int) bool {
+			return remaining[order[i]].AcquiredDate.Before(remaining[order[j]].AcquiredDate)
+		})
+	}
+
+	var picks []lotPick
+	take := func(index int, want float64) {
+		lot := &remaining[index]
+		shares := math.Min(want, lot.Quantity)
+		if shares <= lotQuantityTolerance {
+			return
+		}
+		cost := lot.CostCents
+		if lot.Quantity-shares > lotQuantityTolerance {
+			cost = int64(math.Round(float64(lot.CostCents) * shares / lot.Quantity))
+		} else {
+			shares = lot.Quantity
+		}
+		picks = append(picks, lotPick{Lot: *lot, Quantity: shares, CostCents: cost})
+		lot.Quantity -= shares
+		lot.CostCents -= cost
+		quantity -= shares
+	}
+
+	if method == lotMethodSpecific {
+		indexByID := make(map[string]int, len(remaining))
+		for i, lot := range remaining {
+			indexByID[lot.ID] = i
+		}
+		for _, selection := range selected {
+			if index, ok := indexByID[selection.LotID]; ok && quantity > lotQuantityTolerance {
+				take(index, math.Min(selection.Quantity, quantity))
+			}
+		}
+	}
+	for _, index := range order {
+		if quantity <= lotQuantityTolerance {
+			break
+		}
+		take(index, quantity)
+	}
+	if quantity > lotQuantityTolerance {
+		picks = append(picks, lotPick{Quantity: quantity})
+	}
+
+	left := remaining[:0]
+	for _, lot := range remaining {
+		if lot.Quantity > lotQuantityTolerance {
+			left = append(left, lot)
+		}
+	}
+	return picks, left
+}
+
+// Turns a sale's picks into realized lots, splitting the proceeds by shares (the last pick takes the rounding).
+func realizeSale(transaction database.InvestmentTransaction, key holdingKey, soldDate time.Time, quantity float64, picks []lotPick) []realizedLot {
+	proceeds := transaction.AmountCents
+	rows := make([]realizedLot, 0, len(picks))
+	var allocated int64
+	for i, pick := range picks {
+		share := int64(math.Round(float64(proceeds) * pick.Quantity / quantity))
+		if i == len(picks)-1 {
+			share = proceeds - allocated
+		}
+		allocated += share
+
+		row := realizedLot{
+			SaleID:        transaction.PlaidInvestmentTransactionID,
+			AccountID:     key.AccountID,
+			Symbol:        key.Symbol,
+			LotID:         pick.Lot.ID,
+			AcquiredDate:  pick.Lot.AcquiredDate,
+			SoldDate:      soldDate,
+			Quantity:      pick.Quantity,
+			ProceedsCents: share,
+			CostCents:     pick.CostCents,
+			GainCents:     share - pick.CostCents,
+			Term:          "short",
+		}
+		switch {
+		case pick.Lot.ID == "":
+			// No lot covered these shares, so the basis is unknown.
+			row.Term = "unknown"
+			row.GainCents = 0
+		case isLongTerm(pick.Lot.AcquiredDate, soldDate):
+			row.Term = "long"
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Applies a split's share change to the open lots in proportion, keeping their cost.
+func splitLots(lots []taxLot, quantityChange float64) []taxLot {
+	var total float64
+	for _, lot := range lots {
+		total += lot.Quantity
+	}
+	if total <= 0 || total+quantityChange <= 0 {
+		return lots
+	}
+	ratio := (total + quantityChange) / total
+	for i := range lots {
+		lots[i].Quantity *= ratio
+	}
+	return lots
+}
+
+// Flags realized losses where the same security was acquired within 30 days before or after the sale,
+// in any account, and records the loss disallowed in proportion to the replacement shares. Each acquired
+// share replaces at most one sold share; shares sold in the same sale are not replacements.
+func flagWashSales(realized []realizedLot, acquisitions []taxLot) {
+	capacity := make(map[string]float64, len(acquisitions))
+	for _, lot := range acquisitions {
+		capacity[lot.ID] += lot.Quantity
+	}
+	soldInSale := make(map[string]map[string]bool)
+	for _, row := range realized {
+		if soldInSale[row.SaleID] == nil {
+			soldInSale[row.SaleID] = make(map[string]bool)
+		}
+		soldInSale[row.SaleID][row.LotID] = true
+	}
+	candidates := make([]taxLot, len(acquisitions))
+	copy(candidates, acquisitions)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].AcquiredDate.Before(candidates[j].AcquiredDate)
+	})
+
+	order := make([]int, 0, len(realized))
+	for i, row := range realized {
+		if row.GainCents < 0 && row.LotID != "" {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return realized[order[i]].SoldDate.Before(realized[order[j]].SoldDate)
+	})
+
+	for _, index := range order {
+		row := &realized[index]
+		windowStart := row.SoldDate.AddDate(0, 0, -washSaleWindowDays)
+		windowEnd := row.SoldDate.AddDate(0, 0, washSaleWindowDays)
+		needed := row.Quantity
+		var matched float64
+		for _, candidate := range candidates {
+			if needed <= lotQuantityTolerance {
+				break
+			}
+			if candidate.Symbol != row.Symbol || soldInSale[row.SaleID][candidate.ID] || capacity[candidate.ID] <= lotQuantityTolerance ||
+				candidate.AcquiredDate.Before(windowStart) || candidate.AcquiredDate.After(windowEnd) {
+				continue
+			}
+			shares := math.Min(needed, capacity[candidate.ID])
+			capacity[candidate.ID] -= shares
+			needed -= shares
+			matched += shares
+		}
+		if matched > lotQuantityTolerance {
+			row.WashSale = true
+			row.WashSaleDisallowedCents = int64(math.Round(float64(-row.GainCents) * math.Min(1, matched/row.Quantity)))
+		}
+	}
+}
+
+// Adds up the realized lots sold in the year by account and term. Lots with an unknown basis are only counted.
+func summarizeRealized(realized []realizedLot, year int) ([]realizedLot, map[string]*realizedAccountSummary) {
+	var rows []realizedLot
+	byAccount := make(map[string]*realizedAccountSummary)
+	for _, row := range realized {
+		if row.SoldDate.Year() != year {
+			continue
+		}
+		rows = append(rows, row)
+		summary := byAccount[row.AccountID]
+		if summary == nil {
+			summary = &realizedAccountSummary{}
+			byAccount[row.AccountID] = summary
+		}
+		switch row.Term {
+		case "short":
+			summary.ShortTerm.add(row)
+		case "long":
+			summary.LongTerm.add(row)
+		default:
+			summary.UnknownBasisCount++
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].SoldDate.Equal(rows[j].SoldDate) {
+			return rows[i].SoldDate.Before(rows[j].SoldDate)
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+	return rows, byAccount
+}
+
+// Builds a Form 8949-style CSV: short-term lots (Part I) then long-term (Part II), with wash sales coded W.
+// Lots with an unknown basis are listed last with blank dates and basis.
+func buildForm8949CSV(rows []realizedLot, accountNames map[string]string) ([]byte, error) {
+	parts := map[string]int{"short": 0, "long": 1, "unknown": 2}
+	ordered := make([]realizedLot, len(rows))
+	copy(ordered, rows)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return parts[ordered[i].Term] < parts[ordered[j].Term]
+	})
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	err := writer.Write([]string{
+		"Part", "Account", "(a) Description of property", "(b) Date acquired", "(c) Date sold or disposed of",
+		"(d) Proceeds", "(e) Cost or other basis", "(f) Code", "(g) Amount of adjustment", "(h) Gain or (loss)",
+	})
+	if err != nil {
+		return nil, err
+	}
+	dollars := func(cents int64) string {
+		return strconv.FormatFloat(float64(cents)/100.0, 'f', 2, 64)
+	}
+	for _, row := range ordered {
+		account := row.AccountID
+		if name, ok := accountNames[row.AccountID]; ok && name != "" {
+			account = name
+		}
+		part, acquired, basis, code, adjustment := "I", "", "", "", ""
+		gain := row.GainCents
+		switch row.Term {
+		case "long":
+			part = "II"
+		case "unknown":
+			part = ""
+		}
+		if row.Term != "unknown" {
+			acquired = row.AcquiredDate.Format("01/02/2006")
+			basis = dollars(row.CostCents)
+		}
+		if row.WashSale {
+			code = "W"
+			adjustment = dollars(row.WashSaleDisallowedCents)
+			gain += row.WashSaleDisallowedCents
+		}
+		gainColumn := dollars(gain)
+		if row.Term == "unknown" {
+			gainColumn = ""
+		}
+		err = writer.Write([]string{
+			part,
+			account,
+			fmt.Sprintf("%s sh. %s", strconv.FormatFloat(row.Quantity, 'f', -1, 64), row.Symbol),
+			acquired,
+			row.SoldDate.Format("01/02/2006"),
+			dollars(row.ProceedsCents),
+			basis,
+			code,
+			adjustment,
+			gainColumn,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// Returns the lot's cost per share.
+func (lot taxLot) costPerShare() float64 {
+	if lot.Quantity <= 0 {
+		return 0
+	}
+	return float64(lot.CostCents) / lot.Quantity
+}
+
+// Adds a realized lot to the totals.
+func (totals *realizedTotals) add(row realizedLot) {
+	totals.ProceedsCents += row.ProceedsCents
+	totals.CostCents += row.CostCents
+	totals.WashSaleDisallowedCents += row.WashSaleDisallowedCents
+	totals.GainCents += row.GainCents + row.WashSaleDisallowedCents
+}
+
+// Shares still held from one acquisition. ID is "txn:<plaid investment transaction ID>" for synced buys
+// and "csv:<lot key>" for imported lots ("csv:<broker_tax_lots ID>" for lots imported before lot keys).
+type taxLot struct {
+	ID           string
+	AccountID    string
+	Symbol       string
+	AcquiredDate time.Time
+	Quantity     float64
+	CostCents    int64
+}
+
+// Shares taken from one lot by a sale. Lot.ID is empty when no lot covered the shares.
+type lotPick struct {
+	Lot       taxLot
+	Quantity  float64
+	CostCents int64
+}
+
+// Shares of one lot disposed of by a sale. Gain excludes any wash-sale adjustment.
+type realizedLot struct {
+	SaleID                  string
+	AccountID               string
+	Symbol                  string
+	LotID                   string
+	AcquiredDate            time.Time
+	SoldDate                time.Time
+	Quantity                float64
+	ProceedsCents           int64
+	CostCents               int64
+	GainCents               int64
+	Term                    string
+	WashSale                bool
+	WashSaleDisallowedCents int64
+}
+
+// Open and realized lots across all accounts.
+type taxLedger struct {
+	Open     []taxLot
+	Realized []realizedLot
+}
+
+// Realized totals for one term. Gain includes wash-sale adjustments.
+type realizedTotals struct {
+	ProceedsCents           int64 `json:"proceedsCents"`
+	CostCents               int64 `json:"costCents"`
+	WashSaleDisallowedCents int64 `json:"washSaleDisallowedCents"`
+	GainCents               int64 `json:"gainCents"`
+}
+
+// Realized totals for one account.
+type realizedAccountSummary struct {
+	ShortTerm         realizedTotals
+	LongTerm          realizedTotals
+	UnknownBasisCount int
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestBuildTaxLedgerFIFO(t *testing.T) {
+	symbol := "VTI"
+	dateOnly := func(y int, m time.Month, d int) database.DateOnly {
+		return database.DateOnly{Time: utcDay(y, m, d)}
+	}
+	transactions := []database.InvestmentTransaction{
+		// Out of order on purpose.
+		{ID: 3, PlaidInvestmentTransactionID: "s1", AccountID: "a", Symbol: &symbol, Date: dateOnly(2026, 3, 1), Type: "sell", Subtype: "sell", Quantity: -15, AmountCents: 300000},
+		{ID: 1, PlaidInvestmentTransactionID: "b1", AccountID: "a", Symbol: &symbol, Date: dateOnly(2024, 5, 1), Type: "buy", Subtype: "buy", Quantity: 10, AmountCents: -100000},
+		{ID: 2, PlaidInvestmentTransactionID: "b2", AccountID: "a", Symbol: &symbol, Date: dateOnly(2025, 6, 1), Type: "buy", Subtype: "buy", Quantity: 10, AmountCents: -150000},
+		{ID: 4, PlaidInvestmentTransactionID: "t1", AccountID: "a", Symbol: &symbol, Date: dateOnly(2026, 4, 1), Type: "transfer", Subtype: "transfer", Quantity: 5, PriceCents: 20000},
+		{ID: 5, PlaidInvestmentTransactionID: "d1", AccountID: "a", Symbol: &symbol, Date: dateOnly(2026, 4, 2), Type: "cash", Subtype: "dividend", AmountCents: 1000},
+	}
+
+	ledger := buildTaxLedger(transactions, nil, nil, nil)
+	wantOpen := []taxLot{
+		{ID: "txn:b2", AcquiredDate: utcDay(2025, 6, 1), Quantity: 5, CostCents: 75000},
+		{ID: "txn:t1", AcquiredDate: utcDay(2026, 4, 1), Quantity: 5, CostCents: 100000},
+	}
+	if len(ledger.Open) != len(wantOpen) {
+		t.Fatalf("open = %+v, want %+v", ledger.Open, wantOpen)
+	}
+	for i, want := range wantOpen {
+		got := ledger.Open[i]
+		if got.ID != want.ID || !got.AcquiredDate.Equal(want.AcquiredDate) || got.Quantity != want.Quantity || got.CostCents != want.CostCents {
+			t.Errorf("open lot %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	wantRealized := []realizedLot{
+		{LotID: "txn:b1", Quantity: 10, ProceedsCents: 200000, CostCents: 100000, GainCents: 100000, Term: "long"},
+		{LotID: "txn:b2", Quantity: 5, ProceedsCents: 100000, CostCents: 75000, GainCents: 25000, Term: "short"},
+	}
+	if len(ledger.Realized) != len(wantRealized) {
+		t.Fatalf("realized = %+v, want %+v", ledger.Realized, wantRealized)
+	}
+	for i, want := range wantRealized {
+		got := ledger.Realized[i]
+		if got.SaleID != "s1" || got.LotID != want.LotID || got.Quantity != want.Quantity || got.ProceedsCents != want.ProceedsCents ||
+			got.CostCents != want.CostCents || got.GainCents != want.GainCents || got.Term != want.Term || got.WashSale {
+			t.Errorf("realized lot %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestBuildTaxLedgerImportedLots(t *testing.T) {
+	symbol := "AAPL"
+	transactions := []database.InvestmentTransaction{
+		{ID: 1, PlaidInvestmentTransactionID: "b1", AccountID: "a", Symbol: &symbol, Date: database.DateOnly{Time: utcDay(2025, 6, 1)}, Type: "buy", Subtype: "buy", Quantity: 5, AmountCents: -50000},
+		{ID: 2, PlaidInvestmentTransactionID: "s1", AccountID: "a", Symbol: &symbol, Date: database.DateOnly{Time: utcDay(2026, 2, 1)}, Type: "sell", Subtype: "sell", Quantity: -8, AmountCents: 160000},
+	}
+	imported := []database.BrokerTaxLot{
+		{ID: 7, AccountID: "a", Symbol: "AAPL", AcquiredDate: database.DateOnly{Time: utcDay(2020, 1, 1)}, Quantity: 5, CostBasisCents: 25000},
+		// Acquired after the synced history starts, so the buy above already covers it.
+		{ID: 8, AccountID: "a", Symbol: "AAPL", AcquiredDate: database.DateOnly{Time: utcDay(2025, 6, 1)}, Quantity: 5, CostBasisCents: 50000},
+	}
+
+	ledger := buildTaxLedger(transactions, imported, map[string]string{"a": lotMethodFIFO}, nil)
+	if len(ledger.Realized) != 2 {
+		t.Fatalf("realized = %+v, want 2 lots", ledger.Realized)
+	}
+	first, second := ledger.Realized[0], ledger.Realized[1]
+	if first.LotID != "csv:7" || first.ProceedsCents != 100000 || first.CostCents != 25000 || first.Term != "long" {
+		t.Errorf("imported lot realized = %+v", first)
+	}
+	if second.LotID != "txn:b1" || second.Quantity != 3 || second.ProceedsCents != 60000 || second.CostCents != 30000 || second.Term != "short" {
+		t.Errorf("synced lot realized = %+v", second)
+	}
+	if len(ledger.Open) != 1 || ledger.Open[0].ID != "txn:b1" || ledger.Open[0].Quantity != 2 || ledger.Open[0].CostCents != 20000 {
+		t.Errorf("open = %+v, want 2 shares of txn:b1 costing 20000", ledger.Open)
+	}
+}
+
+func TestPickLots(t *testing.T) {
+	lots := []taxLot{
+		{ID: "l1", AcquiredDate: utcDay(2024, 1, 1), Quantity: 10, CostCents: 100000},
+		{ID: "l2", AcquiredDate: utcDay(2024, 6, 1), Quantity: 10, CostCents: 200000},
+		{ID: "l3", AcquiredDate: utcDay(2025, 1, 1), Quantity: 10, CostCents: 150000},
+	}
+	tests := []struct {
+		name     string
+		quantity float64
+		method   string
+		selected []database.TaxLotSelection
+		want     []lotPick
+	}{
+		{"fifo", 12, lotMethodFIFO, nil, []lotPick{{Lot: taxLot{ID: "l1"}, Quantity: 10, CostCents: 100000}, {Lot: taxLot{ID: "l2"}, Quantity: 2, CostCents: 40000}}},
+		{"lifo", 12, lotMethodLIFO, nil, []lotPick{{Lot: taxLot{ID: "l3"}, Quantity: 10, CostCents: 150000}, {Lot: taxLot{ID: "l2"}, Quantity: 2, CostCents: 40000}}},
+		{"hifo", 12, lotMethodHIFO, nil, []lotPick{{Lot: taxLot{ID: "l2"}, Quantity: 10, CostCents: 200000}, {Lot: taxLot{ID: "l3"}, Quantity: 2, CostCents: 30000}}},
+		{"specific then fifo", 12, lotMethodSpecific, []database.TaxLotSelection{{LotID: "l3", Quantity: 4}, {LotID: "missing", Quantity: 1}},
+			[]lotPick{{Lot: taxLot{ID: "l3"}, Quantity: 4, CostCents: 60000}, {Lot: taxLot{ID: "l1"}, Quantity: 8, CostCents: 80000}}},
+		{"uncovered shares", 35, lotMethodFIFO, nil, []lotPick{
+			{Lot: taxLot{ID: "l1"}, Quantity: 10, CostCents: 100000}, {Lot: taxLot{ID: "l2"}, Quantity: 10, CostCents: 200000},
+			{Lot: taxLot{ID: "l3"}, Quantity: 10, CostCents: 150000}, {Quantity: 5},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picks, left := pickLots(lots, tt.quantity, tt.method, tt.selected)
+			if len(picks) != len(tt.want) {
+				t.Fatalf("picks = %+v, want %+v", picks, tt.want)
+			}
+			var picked float64
+			for i, want := range tt.want {
+				if picks[i].Lot.ID != want.Lot.ID || picks[i].Quantity != want.Quantity || picks[i].CostCents != want.CostCents {
+					t.Errorf("pick %d = %+v, want %+v", i, picks[i], want)
+				}
+				if picks[i].Lot.ID != "" {
+					picked += picks[i].Quantity
+				}
+			}
+			var remaining float64
+			for _, lot := range left {
+				remaining += lot.Quantity
+			}
+			if remaining+picked != 30 {
+				t.Errorf("remaining %v + picked %v, want 30 shares", remaining, picked)
+			}
+		})
+	}
+	if lots[0].Quantity != 10 || lots[1].Quantity != 10 {
+		t.Errorf("pickLots modified its input: %+v", lots)
+	}
+}
+
+func TestFlagWashSales(t *testing.T) {
+	acquisitions := []taxLot{
+		{ID: "txn:b1", AccountID: "a", Symbol: "X", AcquiredDate: utcDay(2026, 1, 2), Quantity: 10, CostCents: 100000},
+		// Replacement bought in another account two weeks after the loss.
+		{ID: "txn:b2", AccountID: "c", Symbol: "X", AcquiredDate: utcDay(2026, 2, 15), Quantity: 5, CostCents: 40000},
+		{ID: "txn:b3", AccountID: "a", Symbol: "Y", AcquiredDate: utcDay(2026, 2, 10), Quantity: 10, CostCents: 10000},
+	}
+	realized := []realizedLot{
+		{SaleID: "s1", AccountID: "a", Symbol: "X", LotID: "txn:b1", SoldDate: utcDay(2026, 2, 1), Quantity: 10, ProceedsCents: 80000, CostCents: 100000, GainCents: -20000, Term: "short"},
+		{SaleID: "s2", AccountID: "a", Symbol: "Y", LotID: "txn:y0", SoldDate: utcDay(2026, 2, 1), Quantity: 10, ProceedsCents: 20000, CostCents: 10000, GainCents: 10000, Term: "long"},
+		{SaleID: "s3", AccountID: "a", Symbol: "X", LotID: "txn:x0", SoldDate: utcDay(2026, 2, 20), Quantity: 5, ProceedsCents: 30000, CostCents: 40000, GainCents: -10000, Term: "long"},
+	}
+
+	flagWashSales(realized, acquisitions)
+	if !realized[0].WashSale || realized[0].WashSaleDisallowedCents != 10000 {
+		t.Errorf("loss sale = %+v, want wash sale with 10000 disallowed", realized[0])
+	}
+	if realized[1].WashSale {
+		t.Errorf("gain sale = %+v, want no wash sale", realized[1])
+	}
+	// The replacement shares were used up by the earlier loss.
+	if realized[2].WashSale {
+		t.Errorf("second loss sale = %+v, want no wash sale", realized[2])
+	}
+}
+
+func TestSummarizeRealized(t *testing.T) {
+	realized := []realizedLot{
+		{AccountID: "a", Symbol: "X", SoldDate: utcDay(2026, 3, 1), ProceedsCents: 80000, CostCents: 100000, GainCents: -20000, Term: "short", WashSale: true, WashSaleDisallowedCents: 10000},
+		{AccountID: "a", Symbol: "Y", SoldDate: utcDay(2026, 1, 5), ProceedsCents: 20000, CostCents: 10000, GainCents: 10000, Term: "long"},
+		{AccountID: "b", Symbol: "Z", SoldDate: utcDay(2026, 2, 1), ProceedsCents: 5000, Term: "unknown"},
+		{AccountID: "a", Symbol: "X", SoldDate: utcDay(2025, 12, 31), ProceedsCents: 1000, CostCents: 500, GainCents: 500, Term: "short"},
+	}
+
+	rows, byAccount := summarizeRealized(realized, 2026)
+	if len(rows) != 3 || rows[0].Symbol != "Y" || rows[2].Symbol != "X" {
+		t.Errorf("rows = %+v, want the 2026 sales by date", rows)
+	}
+	a := byAccount["a"]
+	if a == nil || a.ShortTerm.GainCents != -10000 || a.ShortTerm.WashSaleDisallowedCents != 10000 || a.LongTerm.GainCents != 10000 {
+		t.Errorf("account a = %+v", a)
+	}
+	if b := byAccount["b"]; b == nil || b.UnknownBasisCount != 1 || b.ShortTerm.ProceedsCents != 0 {
+		t.Errorf("account b = %+v", b)
+	}
+}
+
+func TestBuildForm8949CSV(t *testing.T) {
+	rows := []realizedLot{
+		{AccountID: "a", Symbol: "Y", AcquiredDate: utcDay(2020, 1, 2), SoldDate: utcDay(2026, 1, 5), Quantity: 10, ProceedsCents: 20000, CostCents: 10000, GainCents: 10000, Term: "long"},
+		{AccountID: "a", Symbol: "X", AcquiredDate: utcDay(2026, 1, 2), SoldDate: utcDay(2026, 3, 1), Quantity: 2.5, ProceedsCents: 80000, CostCents: 100000, GainCents: -20000, Term: "short", WashSale: true, WashSaleDisallowedCents: 10000},
+		{AccountID: "b", Symbol: "Z", SoldDate: utcDay(2026, 2, 1), Quantity: 1, ProceedsCents: 5000, Term: "unknown"},
+	}
+
+	got, err := buildForm8949CSV(rows, map[string]string{"a": "Brokerage"})
+	if err != nil {
+		t.Fatalf("buildForm8949CSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	want := []string{
+		"Part,Account,(a) Description of property,(b) Date acquired,(c) Date sold or disposed of,(d) Proceeds,(e) Cost or other basis,(f) Code,(g) Amount of adjustment,(h) Gain or (loss)",
+		"I,Brokerage,2.5 sh. X,01/02/2026,03/01/2026,800.00,1000.00,W,100.00,-100.00",
+		"II,Brokerage,10 sh. Y,01/02/2020,01/05/2026,200.00,100.00,,,100.00",
+		",b,1 sh. Z,,02/01/2026,50.00,,,,",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("CSV = %q, want %d lines", got, len(want))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+func TestParseBrokerLotsCSV(t *testing.T) {
+	csvData := `Cost Basis Report
+Account,X1234
+
+Symbol,Description,Date Acquired,Quantity,Cost Basis Per Share,Cost Basis Total
+vti,VANGUARD TOTAL STOCK MARKET,03/15/2021,"1,000.5",$200.00,"$200,100.00"
+AAPL,APPLE INC,2024-02-01,10,$150.00,"$1,500.00"
+,,,,,
+Total,,,,,"$201,600.00"
+`
+
+	lots, err := parseBrokerLotsCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseBrokerLotsCSV() error = %v", err)
+	}
+	if len(lots) != 2 {
+		t.Fatalf("got %d lots, want 2: %+v", len(lots), lots)
+	}
+	if lots[0].Symbol != "VTI" || lots[0].AcquiredDate.Format(dateLayout) != "2021-03-15" || lots[0].Quantity != 1000.5 || lots[0].CostBasisCents != 20010000 {
+		t.Errorf("lot 0 = %+v", lots[0])
+	}
+	if lots[1].Symbol != "AAPL" || lots[1].AcquiredDate.Format(dateLayout) != "2024-02-01" || lots[1].CostBasisCents != 150000 {
+		t.Errorf("lot 1 = %+v", lots[1])
+	}
+
+	if _, err := parseBrokerLotsCSV(strings.NewReader("Symbol,Quantity\nVTI,1\n")); err == nil {
+		t.Error("expected an error for a CSV without date and cost basis columns")
+	}
+	if _, err := parseBrokerLotsCSV(strings.NewReader("Symbol,Date Acquired,Quantity,Cost Basis\nVTI,someday,1,$1.00\n")); err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
+
+func TestParseTaxYear(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"2025", 2025, false},
+		{"", 0, true},
+		{"25", 0, true},
+		{"abcd", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTaxYear(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTaxYear(%q) = %d, %v; want %d, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestAssignBrokerTaxLotKeys(t *testing.T) {
+	lot := func(symbol string, quantity float64) database.BrokerTaxLot {
+		return database.BrokerTaxLot{AccountID: "a", Symbol: symbol, AcquiredDate: database.DateOnly{Time: utcDay(2020, 1, 1)}, Quantity: quantity, CostBasisCents: 25000}
+	}
+	first := []database.BrokerTaxLot{lot("AAPL", 5), lot("AAPL", 5), lot("MSFT", 2)}
+	assignBrokerTaxLotKeys(first)
+	if first[0].LotKey == "" || first[0].LotKey == first[1].LotKey {
+		t.Fatalf("keys = %q, %q, want distinct keys for identical rows", first[0].LotKey, first[1].LotKey)
+	}
+
+	// A re-import with a new row keeps the existing rows' keys.
+	second := []database.BrokerTaxLot{lot("MSFT", 2), lot("VTI", 1), lot("AAPL", 5), lot("AAPL", 5)}
+	assignBrokerTaxLotKeys(second)
+	if second[0].LotKey != first[2].LotKey || second[2].LotKey != first[0].LotKey || second[3].LotKey != first[1].LotKey {
+		t.Errorf("re-import keys = %+v, want the first import's keys", second)
+	}
+	if got := brokerTaxLotID(second[0]); got != "csv:"+first[2].LotKey {
+		t.Errorf("lot id = %q, want csv:%s", got, first[2].LotKey)
+	}
+}
+
+func TestLegacyBrokerTaxLotRenames(t *testing.T) {
+	lot := func(id int64, account, symbol string) database.BrokerTaxLot {
+		return database.BrokerTaxLot{ID: id, AccountID: account, Symbol: symbol, AcquiredDate: database.DateOnly{Time: utcDay(2020, 1, 1)}, Quantity: 5, CostBasisCents: 25000}
+	}
+	existing := []database.BrokerTaxLot{
+		lot(7, "a", "AAPL"),
+		// Dropped from the new import.
+		lot(8, "a", "MSFT"),
+		// Another account.
+		lot(9, "b", "AAPL"),
+	}
+	lots := []database.BrokerTaxLot{lot(0, "a", "AAPL")}
+	assignBrokerTaxLotKeys(lots)
+
+	renames := legacyBrokerTaxLotRenames(existing, "a", lots)
+	if len(renames) != 1 || renames["csv:7"] != brokerTaxLotID(lots[0]) {
+		t.Errorf("renames = %v, want csv:7 -> %s", renames, brokerTaxLotID(lots[0]))
+	}
+}
@@ -3,12 +3,15 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
@@ -16,12 +19,8 @@ import (
 	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
 )
 
-// Registers webhook and transaction API routes.
+// Registers transaction API routes.
 func registerTransactionsRoutes(mux *http.ServeMux, deps apiDependencies) {
-	// Webhook: called by Plaid.
-	mux.HandleFunc("/api/webhooks/plaid", func(w http.ResponseWriter, r *http.Request) {
-		HandlePlaidWebhook(w, r, deps)
-	})
 	// Protected transaction routes.
 	mux.Handle("/api/transactions", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleListTransactions(w, r, deps)
@@ -35,62 +34,25 @@ func registerTransactionsRoutes(mux *http.ServeMux, deps apiDependencies) {
 	mux.Handle("/api/transactions/sync", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleSyncTransactions(w, r, deps)
 	})))
+	// PUT /api/transactions/{id}/category sets a transaction's category by hand; syncs and resyncs keep it.
+	mux.Handle("/api/transactions/{id}/category", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			methodNotAllowed(w, http.MethodPut)
+			return
+		}
+		handleUpdateTransactionCategory(w, r, deps)
+	})))
 	mux.Handle("/api/categories", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleListCategories(w, r, deps)
 	})))
-	mux.Handle("/api/budget", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		handleBudget(w, r, deps)
-	})))
-}
-
-// Handles Plaid webhook for transaction sync updates.
-func HandlePlaidWebhook(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
-	if r.Method != http.MethodPost {
-		methodNotAllowed(w, http.MethodPost)
-		return
-	}
-
-	// Decodes the request body into a plaidWebhookPayload.
-	var payload plaidWebhookPayload
-	err := json.NewDecoder(r.Body).Decode(&payload)
-	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
-		return
-	}
-
-	// Returns if not a transaction sync update.
-	if payload.WebhookCode != "SYNC_UPDATES_AVAILABLE" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	// Returns if item_id is missing.
-	if payload.ItemID == "" {
-		writeJSONError(w, http.StatusBadRequest, "missing item_id")
-		return
-	}
-
-	// Returns if database is not configured.
-	if deps.db == nil {
-		writeJSONError(w, http.StatusInternalServerError, "database not configured")
-		return
-	}
-
-	// Sets new_transactions_pending flag for the item.
-	err = deps.db.SetItemNewTransactionsPending(r.Context(), payload.ItemID, true)
-	if err != nil {
-		log.Printf("webhook: set new_transactions_pending for item %s: %v", payload.ItemID, err)
-		writeJSONError(w, http.StatusInternalServerError, "failed to record webhook")
-		return
-	}
-	w.WriteHeader(http.StatusOK)
 }
 
-// Runs cursor-based sync and upserts or removes from DB.
-func SyncTransactionsForItem(ctx context.Context, db *database.Client, plaidClient *plaid.Client, item *database.PlaidItem) error {
+// Runs cursor-based sync and upserts or removes from DB, returning the number of added/modified/removed transactions.
+func SyncTransactionsForItem(ctx context.Context, db *database.Client, plaidClient *plaid.Client, item *database.PlaidItem) (TransactionSyncCounts, error) {
+	var counts TransactionSyncCounts
 	// Returns if missing dependencies.
 	if plaidClient == nil || db == nil || item.AccessToken == "manual" {
-		return nil
+		return counts, nil
 	}
 	cursor := ""
 	if item.TransactionsCursor != nil {
@@ -98,24 +60,9 @@ func SyncTransactionsForItem(ctx context.Context, db *database.Client, plaidClie
 	}
 
 	// Gets categories and rules.
-	categories, err := db.ListCategories(ctx)
+	mapping, err := loadCategoryMapping(ctx, db)
 	if err != nil {
-		return err
-	}
-	rules, err := db.ListCategoryRules(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Maps Plaid primary category names to our category IDs.
-	plaidNameToCategoryID := make(map[string]int64)
-	var uncategorizedID int64
-	for _, category := range categories {
-		if category.PlaidName != nil {
-			plaidNameToCategoryID[*category.PlaidName] = category.ID
-		} else if category.Name == "Uncategorized" {
-			uncategorizedID = category.ID
-		}
+		return counts, err
 	}
 
 	// Loops until no more transactions.
@@ -123,25 +70,33 @@ func SyncTransactionsForItem(ctx context.Context, db *database.Client, plaidClie
 		// Gets transactions from Plaid.
 		result, err := plaidClient.TransactionsSync(ctx, item.AccessToken, cursor)
 		if err != nil {
-			return err
+			return counts, err
 		}
 
 		// Converts Plaid transactions to our DB model.
 		var toUpsert []database.Transaction
 		for _, item := range result.Added {
-			transaction := plaidTransactionToDB(item, plaidNameToCategoryID, uncategorizedID, rules)
+			transaction := plaidTransactionToDB(item, mapping.plaidNameToCategoryID, mapping.uncategorizedID, mapping.rules)
 			toUpsert = append(toUpsert, transaction)
 		}
 		for _, item := range result.Modified {
-			transaction := plaidTransactionToDB(item, plaidNameToCategoryID, uncategorizedID, rules)
+			transaction := plaidTransactionToDB(item, mapping.plaidNameToCategoryID, mapping.uncategorizedID, mapping.rules)
 			toUpsert = append(toUpsert, transaction)
 		}
 
-		// Upserts the transactions.
+		counts.Added += len(result.Added)
+		counts.Modified += len(result.Modified)
+		counts.Removed += len(result.Removed)
+
+		// Upserts the transactions, keeping categories the user set by hand.
 		if len(toUpsert) > 0 {
+			err = preserveCategoryOverrides(ctx, db, toUpsert)
+			if err != nil {
+				return counts, err
+			}
 			err = db.UpsertTransactions(ctx, toUpsert)
 			if err != nil {
-				return err
+				return counts, err
 			}
 		}
 
@@ -153,7 +108,7 @@ func SyncTransactionsForItem(ctx context.Context, db *database.Client, plaidClie
 			}
 			err = db.DeleteTransactionsByPlaidIDs(ctx, ids)
 			if err != nil {
-				return err
+				return counts, err
 			}
 		}
 
@@ -165,7 +120,151 @@ func SyncTransactionsForItem(ctx context.Context, db *database.Client, plaidClie
 	}
 
 	// Updates the cursor and clears the new_transactions_pending flag.
-	return db.UpdatePlaidItemCursorAndPending(ctx, item.ItemID, cursor, false)
+	return counts, db.UpdatePlaidItemCursorAndPending(ctx, item.ItemID, cursor, false)
+}
+
+// Loads categories and rules and maps Plaid primary category names to our category IDs.
+func loadCategoryMapping(ctx context.Context, db *database.Client) (categoryMapping, error) {
+	var mapping categoryMapping
+	categories, err := db.ListCategories(ctx)
+	if err != nil {
+		return mapping, err
+	}
+	mapping.rules, err = db.ListCategoryRules(ctx)
+	if err != nil {
+		return mapping, err
+	}
+
+	mapping.plaidNameToCategoryID = make(map[string]int64)
+	for _, category := range categories {
+		if category.PlaidName != nil {
+			mapping.plaidNameToCategoryID[*category.PlaidName] = category.ID
+		} else if category.Name == "Uncategorized" {
+			mapping.uncategorizedID = category.ID
+		}
+	}
+	return mapping, nil
+}
+
+// Copies the stored category onto transactions whose category was overridden by the user.
+func preserveCategoryOverrides(ctx context.Context, db *database.Client, transactions []database.Transaction) error {
+	// Looks up the stored rows in chunks to keep the query string short.
+	overridden := make(map[string]*int64)
+	for start := 0; start < len(transactions); start += transactionLookupChunkSize {
+		end := min(start+transactionLookupChunkSize, len(transactions))
+		ids := make([]string, 0, end-start)
+		for _, transaction := range transactions[start:end] {
+			ids = append(ids, transaction.PlaidTransactionID)
+		}
+		stored, err := db.ListTransactionsByPlaidIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range stored {
+			if transaction.CategoryOverridden {
+				overridden[transaction.PlaidTransactionID] = transaction.CategoryID
+			}
+		}
+	}
+
+	for i := range transactions {
+		if categoryID, ok := overridden[transactions[i].PlaidTransactionID]; ok {
+			transactions[i].CategoryID = categoryID
+			transactions[i].CategoryOverridden = true
+		}
+	}
+	return nil
+}
+
+// Syncs transactions for each item with bounded concurrency and a per-item timeout.
+// A failing item does not stop the others; each item gets its own result.
+func syncPlaidItems(ctx context.Context, deps apiDependencies, items []database.PlaidItem) []plaidItemSyncResult {
+	results := []plaidItemSyncResult{}
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, plaidSyncConcurrency)
+
+	for _, item := range items {
+		if item.AccessToken == "manual" {
+			continue
+		}
+		wg.Add(1)
+		go func(item database.PlaidItem) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			result := syncPlaidItem(ctx, deps, item)
+			resultsMu.Lock()
+			results = append(results, result)
+			resultsMu.Unlock()
+		}(item)
+	}
+	wg.Wait()
+
+	// Sorts by item ID so responses are stable.
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ItemID < results[j].ItemID
+	})
+	return results
+}
+
+// Syncs a single item and records its counts or error.
+func syncPlaidItem(ctx context.Context, deps apiDependencies, item database.PlaidItem) plaidItemSyncResult {
+	result := plaidItemSyncResult{ItemID: item.ItemID}
+	if item.InstitutionName != nil {
+		result.InstitutionName = *item.InstitutionName
+	}
+
+	itemCtx, cancel := context.WithTimeout(ctx, plaidSyncItemTimeout)
+	defer cancel()
+
+	counts, err := SyncTransactionsForItem(itemCtx, deps.db, deps.plaidClient, &item)
+	result.Added = counts.Added
+	result.Modified = counts.Modified
+	result.Removed = counts.Removed
+	if err == nil {
+		result.Synced = true
+		return result
+	}
+
+	log.Printf("sync transactions for item %s: %v", item.ItemID, err)
+	result.ErrorCode = plaidSyncErrorCode(err)
+	result.Error = err.Error()
+
+	// Marks the item as needing reconnection on auth errors.
+	var plaidErr *plaid.PlaidConnectionError
+	if errors.As(err, &plaidErr) && plaidErr.IsAuthError {
+		err = deps.db.UpdatePlaidItemStatus(ctx, item.ItemID, plaidErr.ErrorCode, GetLocalNow())
+		if err != nil {
+			log.Printf("sync transactions: update plaid item %s status: %v", item.ItemID, err)
+		}
+	}
+	return result
+}
+
+// Returns the error code reported for a failed item sync.
+func plaidSyncErrorCode(err error) string {
+	var plaidErr *plaid.PlaidConnectionError
+	switch {
+	case errors.As(err, &plaidErr) && plaidErr.ErrorCode != "":
+		return plaidErr.ErrorCode
+	case errors.Is(err, context.DeadlineExceeded):
+		return "TIMEOUT"
+	default:
+		return "SYNC_ERROR"
+	}
+}
+
+// Counts the items that synced successfully.
+func countSyncedItems(results []plaidItemSyncResult) int {
+	synced := 0
+	for _, result := range results {
+		if result.Synced {
+			synced++
+		}
+	}
+	return synced
 }
 
 // pfcPrimaryToPlaidName maps Plaid's category names to our category names.
@@ -484,6 +583,12 @@ func handleSyncTransactions(w http.ResponseWriter, r *http.Request, deps apiDepe
 		return
 	}
 
+	// Refreshes holdings queued by holdings webhooks.
+	_, err := refreshPendingHoldings(r, deps)
+	if err != nil {
+		log.Printf("sync transactions: refresh pending holdings: %v", err)
+	}
+
 	// Gets the items with pending transactions.
 	items, err := deps.db.ListPlaidItemsWithPendingTransactions(r.Context())
 	if err != nil {
@@ -493,147 +598,108 @@ func handleSyncTransactions(w http.ResponseWriter, r *http.Request, deps apiDepe
 	// If no new transactions, return.
 	if len(items) == 0 {
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"synced":0,"message":"no items with pending transactions"}`))
+		_ = json.NewEncoder(w).Encode(syncTransactionsResponse{Items: []plaidItemSyncResult{}, Message: "no items with pending transactions"})
 		return
 	}
 
-	// Syncs transactions for each item.
-	for _, item := range items {
-		err = SyncTransactionsForItem(r.Context(), deps.db, deps.plaidClient, &item)
-		if err != nil {
-			log.Printf("sync transactions for item %s: %v", item.ItemID, err)
-			writeJSONError(w, http.StatusInternalServerError, "sync failed: "+err.Error())
-			return
-		}
-	}
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"synced":` + strconv.Itoa(len(items)) + `}`))
-}
+	// Syncs transactions for each item; failed items are reported rather than aborting the sync.
+	results := syncPlaidItems(r.Context(), deps, items)
+	synced := countSyncedItems(results)
 
-// Returns all categories.
-func handleListCategories(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
-	if r.Method != http.MethodGet {
-		methodNotAllowed(w, http.MethodGet)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	if deps.db == nil {
-		writeJSONError(w, http.StatusInternalServerError, "database not configured")
-		return
-	}
-	list, err := deps.db.ListCategories(r.Context())
+	// Emails budget alerts for thresholds the new transactions crossed.
+	alertsSent, err := evaluateBudgetAlerts(r.Context(), deps.db, GetLocalNow())
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	output := make([]categoryJSON, len(list))
-	for i, category := range list {
-		output[i] = categoryJSON{ID: category.ID, Name: category.Name, Expense: category.Expense}
+		log.Printf("sync transactions: budget alerts: %v", err)
 	}
-	err = json.NewEncoder(w).Encode(map[string]interface{}{"categories": output})
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(syncTransactionsResponse{
+		Synced:           synced,
+		Failed:           len(results) - synced,
+		Items:            results,
+		BudgetAlertsSent: alertsSent,
+	})
 	if err != nil {
-		log.Printf("list categories encode: %v", err)
+		log.Printf("sync transactions encode: %v", err)
 	}
 }
 
-// Returns or updates the current budget.
-func handleBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+// Sets a transaction's category and marks it as a user override.
+func handleUpdateTransactionCategory(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
 	if deps.db == nil {
 		writeJSONError(w, http.StatusInternalServerError, "database not configured")
 		return
 	}
-
-	switch r.Method {
-	case http.MethodGet:
-		handleGetBudget(w, r, deps)
-	case http.MethodPost, http.MethodPut:
-		handleUpdateBudget(w, r, deps)
-	default:
-		methodNotAllowed(w, http.MethodGet)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid transaction id")
+		return
 	}
-}
-
-// Returns the budget allocations and spent-by-category for the requested month.
-func handleGetBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
-	w.Header().Set("Content-Type", "application/json")
-
-	month := r.URL.Query().Get("month")
-	if month == "" {
-		writeJSONError(w, http.StatusBadRequest, "month required (YYYY-MM)")
+	var req updateTransactionCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CategoryID == nil {
+		writeJSONError(w, http.StatusBadRequest, "categoryId is required")
 		return
 	}
 
-	// Load the current budget
-	budget, err := deps.db.GetBudget(r.Context())
+	// Checks the category exists.
+	categories, err := deps.db.ListCategories(r.Context())
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-
-	// Maps the allocations to a map.
-	allocations := map[string]int64{}
-	if budget != nil && budget.Allocations != nil {
-		for k, v := range budget.Allocations {
-			allocations[k] = v
+	var category *database.Category
+	for i := range categories {
+		if categories[i].ID == *req.CategoryID {
+			category = &categories[i]
 		}
 	}
+	if category == nil {
+		writeJSONError(w, http.StatusBadRequest, "unknown category")
+		return
+	}
 
-	// Computes the monthly spent by category.
-	spendingMap, err := calculateMonthlySpentByCategory(r.Context(), deps.db, month)
+	found, err := deps.db.UpdateTransactionCategory(r.Context(), id, category.ID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-
-	// Returns the response.
-	resp := budgetResponse{
-		Month:       month,
-		Allocations: allocations,
-		Spent:       spendingMap,
-	}
-	err = json.NewEncoder(w).Encode(resp)
-	if err != nil {
-		log.Printf("get budget encode: %v", err)
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "transaction not found")
+		return
 	}
+	_ = json.NewEncoder(w).Encode(categoryJSON{ID: category.ID, Name: category.Name, Expense: category.Expense})
 }
 
-// Updates the budget allocations.
-func handleUpdateBudget(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
-	w.Header().Set("Content-Type", "application/json")
-
-	// Decodes the request body into an updateBudgetRequest.
-	var req updateBudgetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+// Returns all categories.
+func handleListCategories(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
 		return
 	}
-
-	// Sets the allocations to an empty map if nil.
-	if req.Allocations == nil {
-		req.Allocations = map[string]int64{}
-	}
-
-	// Creates the new budget.
-	budget := &database.Budget{
-		ID:          1,
-		Allocations: req.Allocations,
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
 	}
-
-	if err := deps.db.UpsertBudget(r.Context(), budget); err != nil {
+	list, err := deps.db.ListCategories(r.Context())
+	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-
-	// Return the updated budget
-	err := json.NewEncoder(w).Encode(budget)
+	output := make([]categoryJSON, len(list))
+	for i, category := range list {
+		output[i] = categoryJSON{ID: category.ID, Name: category.Name, Expense: category.Expense}
+	}
+	err = json.NewEncoder(w).Encode(map[string]interface{}{"categories": output})
 	if err != nil {
-		log.Printf("update budget encode: %v", err)
+		log.Printf("list categories encode: %v", err)
 	}
 }
 
-// Computes the monthly spent by category.
-func calculateMonthlySpentByCategory(ctx context.Context, dbClient *database.Client, month string) (map[string]int64, error) {
-	monthlySpending := make(map[string]int64)
+// Computes the monthly spent by expense category ID (negative = net outflow).
+func calculateMonthlySpentByCategoryID(ctx context.Context, dbClient *database.Client, month string, categoriesByID map[int64]database.Category) (map[int64]int64, error) {
+	monthlySpending := make(map[int64]int64)
 	if month == "" {
 		return monthlySpending, nil
 	}
@@ -647,16 +713,7 @@ func calculateMonthlySpentByCategory(ctx context.Context, dbClient *database.Cli
 		return nil, err
 	}
 
-	// Fetch categories and filter to expense categories.
-	categories, err := dbClient.ListCategories(ctx)
-	if err != nil {
-		return nil, err
-	}
-	categoriesByID := make(map[int64]database.Category, len(categories))
-	for _, category := range categories {
-		categoriesByID[category.ID] = category
-	}
-	// Loops through the transactions and calculates the monthly spent by category.
+	// Loops through the transactions and calculates the monthly spent by expense category.
 	for _, transaction := range transactions {
 		if transaction.CategoryID == nil {
 			continue
@@ -666,9 +723,8 @@ func calculateMonthlySpentByCategory(ctx context.Context, dbClient *database.Cli
 			continue
 		}
 
-		categoryName := category.Name
 		// We sum the negative amounts (outflows) and will handle display as positive in the UI.
-		monthlySpending[categoryName] += transaction.AmountCents
+		monthlySpending[category.ID] += transaction.AmountCents
 	}
 	return monthlySpending, nil
 }
@@ -680,13 +736,6 @@ type categoryJSON struct {
 	Expense bool   `json:"expense"`
 }
 
-// Plaid webhook payload.
-type plaidWebhookPayload struct {
-	WebhookType string `json:"webhook_type"`
-	WebhookCode string `json:"webhook_code"`
-	ItemID      string `json:"item_id"`
-}
-
 // Transactions response for API.
 type transactionsResponse struct {
 	Transactions []transactionJSON `json:"transactions"`
@@ -705,6 +754,11 @@ type transactionJSON struct {
 	Pending      bool    `json:"pending"`
 }
 
+// Transaction category update request.
+type updateTransactionCategoryRequest struct {
+	CategoryID *int64 `json:"categoryId"`
+}
+
 // Monthly summary response: income (inflows), expenses (outflows to expense categories), invested (outflows to Investments).
 type transactionsSummaryResponse struct {
 	IncomeCents   int64 `json:"incomeCents"`
@@ -726,14 +780,46 @@ type yearlyExpenseSummaryResponse struct {
 	ByCategory []yearlyExpenseCategoryJSON `json:"byCategory"`
 }
 
-// Budget API response
-type budgetResponse struct {
-	Month       string           `json:"month"`
-	Allocations map[string]int64 `json:"allocations"`
-	Spent       map[string]int64 `json:"spent"`
+// Number of items synced at once.
+const plaidSyncConcurrency = 4
+
+// Maximum time spent syncing a single item.
+const plaidSyncItemTimeout = 2 * time.Minute
+
+// Number of Plaid transaction IDs looked up per request.
+const transactionLookupChunkSize = 100
+
+// Category lookups used to categorize Plaid transactions.
+type categoryMapping struct {
+	plaidNameToCategoryID map[string]int64
+	uncategorizedID       int64
+	rules                 []database.CategoryRule
+}
+
+// Number of transactions changed by a sync.
+type TransactionSyncCounts struct {
+	Added    int
+	Modified int
+	Removed  int
+}
+
+// Per-item sync result for API.
+type plaidItemSyncResult struct {
+	ItemID          string `json:"itemId"`
+	InstitutionName string `json:"institutionName,omitempty"`
+	Synced          bool   `json:"synced"`
+	Added           int    `json:"added"`
+	Modified        int    `json:"modified"`
+	Removed         int    `json:"removed"`
+	ErrorCode       string `json:"errorCode,omitempty"`
+	Error           string `json:"error,omitempty"`
 }
 
-// Budget update request
-type updateBudgetRequest struct {
-	Allocations map[string]int64 `json:"allocations"`
+// Transactions sync response.
+type syncTransactionsResponse struct {
+	Synced           int                   `json:"synced"`
+	Failed           int                   `json:"failed"`
+	Items            []plaidItemSyncResult `json:"items"`
+	BudgetAlertsSent int                   `json:"budgetAlertsSent"`
+	Message          string                `json:"message,omitempty"`
 }
@@ -1,6 +1,13 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -297,3 +304,72 @@ func summarizeTransactionsForTest(transactions []database.Transaction, categorie
 
 	return incomeCents, expensesCents, investedCents
 }
+
+func TestPlaidSyncErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"plaid auth error", &plaid.PlaidConnectionError{ErrorCode: "ITEM_LOGIN_REQUIRED", IsAuthError: true}, "ITEM_LOGIN_REQUIRED"},
+		{"wrapped plaid error", fmt.Errorf("sync: %w", &plaid.PlaidConnectionError{ErrorCode: "RATE_LIMIT_EXCEEDED"}), "RATE_LIMIT_EXCEEDED"},
+		{"timeout", fmt.Errorf("request: %w", context.DeadlineExceeded), "TIMEOUT"},
+		{"other", errors.New("supabase upsert failed"), "SYNC_ERROR"},
+	}
+
+	for _, tt := range tests {
+		if got := plaidSyncErrorCode(tt.err); got != tt.want {
+			t.Errorf("%s: plaidSyncErrorCode = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountSyncedItems(t *testing.T) {
+	results := []plaidItemSyncResult{
+		{ItemID: "a", Synced: true, Added: 3},
+		{ItemID: "b", ErrorCode: "ITEM_LOGIN_REQUIRED"},
+		{ItemID: "c", Synced: true},
+	}
+	if got := countSyncedItems(results); got != 2 {
+		t.Errorf("countSyncedItems = %d, want 2", got)
+	}
+}
+
+func TestHandleUpdateTransactionCategory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/rest/v1/categories" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode([]database.Category{{ID: 2, Name: "Food", Expense: true}})
+		case r.URL.Path == "/rest/v1/transactions" && r.Method == http.MethodPatch:
+			// Only transaction 1 exists.
+			updated := []database.Transaction{}
+			if r.URL.Query().Get("id") == "eq.1" {
+				updated = append(updated, database.Transaction{ID: 1})
+			}
+			_ = json.NewEncoder(w).Encode(updated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("SUPABASE_URL", server.URL)
+	t.Setenv("SUPABASE_SERVICE_ROLE_KEY", "test")
+	db, err := database.NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := func(id string) int {
+		request := httptest.NewRequest(http.MethodPut, "/api/transactions/"+id+"/category", strings.NewReader(`{"categoryId":2}`))
+		request.SetPathValue("id", id)
+		recorder := httptest.NewRecorder()
+		handleUpdateTransactionCategory(recorder, request, apiDependencies{db: db})
+		return recorder.Code
+	}
+	if code := update("1"); code != http.StatusOK {
+		t.Errorf("known transaction status = %d, want 200", code)
+	}
+	if code := update("99"); code != http.StatusNotFound {
+		t.Errorf("unknown transaction status = %d, want 404", code)
+	}
+}
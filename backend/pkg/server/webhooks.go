@@ -0,0 +1,408 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/serverauth"
+)
+
+// Largest webhook body we read and store.
+const maxWebhookBodyBytes = 1 << 20
+
+// Processing outcomes stored on webhook_events rows.
+const (
+	webhookOutcomeReceived  = "received"
+	webhookOutcomeProcessed = "processed"
+	webhookOutcomeIgnored   = "ignored"
+	webhookOutcomeFailed    = "failed"
+)
+
+// Deliveries of the same body for the same item within this long of the stored event's receipt are treated as retries.
+const webhookDedupeWindow = 5 * time.Minute
+
+// Returned when a handled webhook has no item_id.
+var errWebhookMissingItemID = errors.New("missing item_id")
+
+// Registers Plaid webhook and webhook event log routes.
+func registerWebhookRoutes(mux *http.ServeMux, deps apiDependencies) {
+	// Webhook: called by Plaid.
+	mux.HandleFunc("/api/webhooks/plaid", func(w http.ResponseWriter, r *http.Request) {
+		HandlePlaidWebhook(w, r, deps)
+	})
+
+	// GET /api/webhooks/events lists stored webhook events.
+	mux.Handle("/api/webhooks/events", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		handleListWebhookEvents(w, r, deps)
+	})))
+
+	// POST /api/webhooks/events/{id}/replay re-runs processing for a stored event.
+	mux.Handle("/api/webhooks/events/{id}/replay", serverauth.JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		handleReplayWebhookEvent(w, r, deps)
+	})))
+}
+
+// Handles Plaid webhooks for transactions, holdings, and item status updates.
+// Every delivery is stored in webhook_events; repeats of an already handled delivery are acknowledged without reprocessing.
+func HandlePlaidWebhook(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	// Reads the raw body so it can be stored with the event.
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	// Decodes the request body into a plaidWebhookPayload.
+	var payload plaidWebhookPayload
+	decodeErr := json.Unmarshal(body, &payload)
+
+	// Returns if database is not configured (only an error for webhooks we act on).
+	if deps.db == nil {
+		if decodeErr != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if !isHandledPlaidWebhook(payload) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	// Stores the delivery, or finds the earlier attempt of the same delivery.
+	event, duplicate, err := recordWebhookEvent(r.Context(), deps.db, r.Header, body, payload)
+	if err != nil {
+		log.Printf("webhook: record event: %v", err)
+	}
+	if duplicate {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if decodeErr != nil {
+		finishWebhookEvent(r.Context(), deps.db, event, webhookOutcomeFailed, errors.New("invalid JSON"))
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	// Processes the webhook and stores the outcome on the event.
+	outcome, err := applyPlaidWebhook(r.Context(), deps.db, payload)
+	finishWebhookEvent(r.Context(), deps.db, event, outcome, err)
+	switch {
+	case errors.Is(err, errWebhookMissingItemID):
+		writeJSONError(w, http.StatusBadRequest, "missing item_id")
+	case err != nil:
+		log.Printf("webhook: %s/%s for item %s: %v", payload.WebhookType, payload.WebhookCode, payload.ItemID, err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to record webhook")
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// Lists stored webhook events, newest first.
+func handleListWebhookEvents(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	// Parses the query parameters.
+	query := r.URL.Query()
+	filter := database.ListWebhookEventsFilter{
+		Outcome: query.Get("outcome"),
+		ItemID:  query.Get("itemId"),
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 500 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := deps.db.ListWebhookEvents(r.Context(), filter)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to list webhook events: "+err.Error())
+		return
+	}
+
+	output := make([]webhookEventJSON, len(events))
+	for i, event := range events {
+		output[i] = toWebhookEventJSON(event)
+	}
+	err = json.NewEncoder(w).Encode(webhookEventsResponse{Events: output})
+	if err != nil {
+		log.Printf("list webhook events encode: %v", err)
+	}
+}
+
+// Re-runs processing for a stored webhook event and returns the updated event.
+func handleReplayWebhookEvent(w http.ResponseWriter, r *http.Request, deps apiDependencies) {
+	w.Header().Set("Content-Type", "application/json")
+	if deps.db == nil {
+		writeJSONError(w, http.StatusInternalServerError, "database not configured")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+
+	event, err := deps.db.GetWebhookEvent(r.Context(), id)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to get webhook event: "+err.Error())
+		return
+	}
+	if event == nil {
+		writeJSONError(w, http.StatusNotFound, "webhook event not found")
+		return
+	}
+
+	// Decodes the stored body and processes it again.
+	var payload plaidWebhookPayload
+	err = json.Unmarshal([]byte(event.RawBody), &payload)
+	outcome := webhookOutcomeFailed
+	if err != nil {
+		err = errors.New("invalid JSON")
+	} else {
+		outcome, err = applyPlaidWebhook(r.Context(), deps.db, payload)
+	}
+	finishWebhookEvent(r.Context(), deps.db, event, outcome, err)
+
+	err = json.NewEncoder(w).Encode(toWebhookEventJSON(*event))
+	if err != nil {
+		log.Printf("replay webhook event encode: %v", err)
+	}
+}
+
+// Stores a webhook delivery, returning the existing event and duplicate=true when the same delivery was already handled.
+func recordWebhookEvent(ctx context.Context, db *database.Client, header http.Header, body []byte, payload plaidWebhookPayload) (*database.WebhookEvent, bool, error) {
+	receivedAt := GetLocalNow()
+	dedupeKey := webhookDedupeKey(body, payload.ItemID)
+	existing, err := db.GetLatestWebhookEventByDedupeKey(ctx, dedupeKey, receivedAt.Add(-webhookDedupeWindow))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Repeat delivery: acknowledge if handled, otherwise process it again.
+	if existing != nil {
+		existing.DuplicateCount++
+		if existing.Outcome == webhookOutcomeProcessed || existing.Outcome == webhookOutcomeIgnored {
+			err = db.UpdateWebhookEventOutcome(ctx, existing)
+			return existing, true, err
+		}
+		return existing, false, nil
+	}
+
+	event := &database.WebhookEvent{
+		Source:      "plaid",
+		DedupeKey:   dedupeKey,
+		WebhookType: payload.WebhookType,
+		WebhookCode: payload.WebhookCode,
+		RawBody:     string(body),
+		Headers:     webhookHeaders(header),
+		ReceivedAt:  receivedAt,
+		Outcome:     webhookOutcomeReceived,
+	}
+	if payload.ItemID != "" {
+		event.ItemID = &payload.ItemID
+	}
+	stored, err := db.InsertWebhookEvent(ctx, event)
+	if err != nil {
+		return nil, false, err
+	}
+	return stored, false, nil
+}
+
+// Records the processing outcome on a webhook event (no-op if the event could not be stored).
+func finishWebhookEvent(ctx context.Context, db *database.Client, event *database.WebhookEvent, outcome string, processErr error) {
+	if event == nil {
+		return
+	}
+	now := GetLocalNow()
+	event.Outcome = outcome
+	event.ProcessedAt = &now
+	event.Attempts++
+	event.Error = nil
+	if processErr != nil {
+		message := processErr.Error()
+		event.Error = &message
+	}
+	err := db.UpdateWebhookEventOutcome(ctx, event)
+	if err != nil {
+		log.Printf("webhook: update event %d outcome: %v", event.ID, err)
+	}
+}
+
+// Validates and processes a decoded webhook, returning the outcome to store.
+func applyPlaidWebhook(ctx context.Context, db *database.Client, payload plaidWebhookPayload) (string, error) {
+	if !isHandledPlaidWebhook(payload) {
+		return webhookOutcomeIgnored, nil
+	}
+	if payload.ItemID == "" {
+		return webhookOutcomeFailed, errWebhookMissingItemID
+	}
+	err := processPlaidWebhook(ctx, db, payload)
+	if err != nil {
+		return webhookOutcomeFailed, err
+	}
+	return webhookOutcomeProcessed, nil
+}
+
+// Returns the dedupe key for one webhook delivery: the item plus the body's sha256. Plaid signs each retry with a
+// new Plaid-Verification token but resends the same body, so the key stays stable across retries; recordWebhookEvent
+// only matches it against events received within webhookDedupeWindow, so later notifications with the same body
+// are processed again.
+func webhookDedupeKey(body []byte, itemID string) string {
+	sum := sha256.Sum256(body)
+	return itemID + ":" + hex.EncodeToString(sum[:])
+}
+
+// Flattens request headers into a single value per header name.
+func webhookHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// Converts a webhook event row to its API model.
+func toWebhookEventJSON(event database.WebhookEvent) webhookEventJSON {
+	return webhookEventJSON{
+		ID:             event.ID,
+		Source:         event.Source,
+		WebhookType:    event.WebhookType,
+		WebhookCode:    event.WebhookCode,
+		ItemID:         event.ItemID,
+		RawBody:        event.RawBody,
+		Headers:        event.Headers,
+		ReceivedAt:     event.ReceivedAt,
+		ProcessedAt:    event.ProcessedAt,
+		Outcome:        event.Outcome,
+		Error:          event.Error,
+		Attempts:       event.Attempts,
+		DuplicateCount: event.DuplicateCount,
+	}
+}
+
+// Applies a Plaid webhook to the matching item in the database.
+func processPlaidWebhook(ctx context.Context, db *database.Client, payload plaidWebhookPayload) error {
+	switch plaidWebhookKey(payload) {
+	case "TRANSACTIONS/SYNC_UPDATES_AVAILABLE":
+		// Marks the item so the next sync pulls the new transactions.
+		return db.SetItemNewTransactionsPending(ctx, payload.ItemID, true)
+	case "ITEM/ERROR", "ITEM/PENDING_EXPIRATION", "ITEM/USER_PERMISSION_REVOKED":
+		// Updates the item status right away so the links page shows it as broken.
+		return db.UpdatePlaidItemStatus(ctx, payload.ItemID, plaidWebhookItemStatus(payload), GetLocalNow())
+	case "ITEM/NEW_ACCOUNTS_AVAILABLE":
+		// Flags that the item has accounts that can be added through Link update mode.
+		return db.SetItemNewAccountsAvailable(ctx, payload.ItemID, true)
+	case "HOLDINGS/DEFAULT_UPDATE", "INVESTMENTS_TRANSACTIONS/DEFAULT_UPDATE":
+		// Queues a holdings refresh for the item.
+		return db.SetItemHoldingsRefreshPending(ctx, payload.ItemID, true)
+	}
+	return nil
+}
+
+// Returns true if the webhook type and code are ones we act on.
+func isHandledPlaidWebhook(payload plaidWebhookPayload) bool {
+	switch plaidWebhookKey(payload) {
+	case "TRANSACTIONS/SYNC_UPDATES_AVAILABLE",
+		"ITEM/ERROR",
+		"ITEM/PENDING_EXPIRATION",
+		"ITEM/USER_PERMISSION_REVOKED",
+		"ITEM/NEW_ACCOUNTS_AVAILABLE",
+		"HOLDINGS/DEFAULT_UPDATE",
+		"INVESTMENTS_TRANSACTIONS/DEFAULT_UPDATE":
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns the TYPE/CODE key for a webhook payload.
+func plaidWebhookKey(payload plaidWebhookPayload) string {
+	return payload.WebhookType + "/" + payload.WebhookCode
+}
+
+// Returns the plaid_items status to store for an ITEM webhook.
+func plaidWebhookItemStatus(payload plaidWebhookPayload) string {
+	switch payload.WebhookCode {
+	case "PENDING_EXPIRATION":
+		return "PENDING_EXPIRATION"
+	case "USER_PERMISSION_REVOKED":
+		return "USER_PERMISSION_REVOKED"
+	}
+	// ITEM/ERROR carries the Plaid error code (e.g. ITEM_LOGIN_REQUIRED).
+	if payload.Error != nil && payload.Error.ErrorCode != "" {
+		return payload.Error.ErrorCode
+	}
+	return "ITEM_ERROR"
+}
+
+// Plaid webhook payload.
+type plaidWebhookPayload struct {
+	WebhookType           string             `json:"webhook_type"`
+	WebhookCode           string             `json:"webhook_code"`
+	ItemID                string             `json:"item_id"`
+	Error                 *plaidWebhookError `json:"error,omitempty"`
+	ConsentExpirationTime *string            `json:"consent_expiration_time,omitempty"`
+}
+
+// Error object embedded in ITEM webhooks.
+type plaidWebhookError struct {
+	ErrorType    string `json:"error_type"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// Webhook event for API.
+type webhookEventJSON struct {
+	ID             int64             `json:"id"`
+	Source         string            `json:"source"`
+	WebhookType    string            `json:"webhookType"`
+	WebhookCode    string            `json:"webhookCode"`
+	ItemID         *string           `json:"itemId,omitempty"`
+	RawBody        string            `json:"rawBody"`
+	Headers        map[string]string `json:"headers"`
+	ReceivedAt     time.Time         `json:"receivedAt"`
+	ProcessedAt    *time.Time        `json:"processedAt,omitempty"`
+	Outcome        string            `json:"outcome"`
+	Error          *string           `json:"error,omitempty"`
+	Attempts       int               `json:"attempts"`
+	DuplicateCount int               `json:"duplicateCount"`
+}
+
+// Webhook events list response.
+type webhookEventsResponse struct {
+	Events []webhookEventJSON `json:"events"`
+}
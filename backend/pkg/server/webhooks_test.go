@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthewtzong/portfolio-tracker/backend/pkg/database"
+)
+
+// Tests that only the supported webhook type/code pairs are handled.
+func TestIsHandledPlaidWebhook(t *testing.T) {
+	tests := []struct {
+		webhookType string
+		webhookCode string
+		want        bool
+	}{
+		{"TRANSACTIONS", "SYNC_UPDATES_AVAILABLE", true},
+		{"ITEM", "ERROR", true},
+		{"ITEM", "PENDING_EXPIRATION", true},
+		{"ITEM", "USER_PERMISSION_REVOKED", true},
+		{"ITEM", "NEW_ACCOUNTS_AVAILABLE", true},
+		{"HOLDINGS", "DEFAULT_UPDATE", true},
+		{"INVESTMENTS_TRANSACTIONS", "DEFAULT_UPDATE", true},
+		{"TRANSACTIONS", "DEFAULT_UPDATE", false},
+		{"ITEM", "WEBHOOK_UPDATE_ACKNOWLEDGED", false},
+		{"", "SYNC_UPDATES_AVAILABLE", false},
+	}
+
+	for _, tt := range tests {
+		payload := plaidWebhookPayload{WebhookType: tt.webhookType, WebhookCode: tt.webhookCode}
+		if got := isHandledPlaidWebhook(payload); got != tt.want {
+			t.Errorf("isHandledPlaidWebhook(%s/%s) = %v, want %v", tt.webhookType, tt.webhookCode, got, tt.want)
+		}
+	}
+}
+
+// Tests the item status stored for each ITEM webhook.
+func TestPlaidWebhookItemStatus(t *testing.T) {
+	tests := []struct {
+		payload plaidWebhookPayload
+		want    string
+	}{
+		{plaidWebhookPayload{WebhookType: "ITEM", WebhookCode: "ERROR", Error: &plaidWebhookError{ErrorCode: "ITEM_LOGIN_REQUIRED"}}, "ITEM_LOGIN_REQUIRED"},
+		{plaidWebhookPayload{WebhookType: "ITEM", WebhookCode: "ERROR"}, "ITEM_ERROR"},
+		{plaidWebhookPayload{WebhookType: "ITEM", WebhookCode: "PENDING_EXPIRATION"}, "PENDING_EXPIRATION"},
+		{plaidWebhookPayload{WebhookType: "ITEM", WebhookCode: "USER_PERMISSION_REVOKED", Error: &plaidWebhookError{ErrorCode: "USER_PERMISSION_REVOKED"}}, "USER_PERMISSION_REVOKED"},
+	}
+
+	for _, tt := range tests {
+		if got := plaidWebhookItemStatus(tt.payload); got != tt.want {
+			t.Errorf("plaidWebhookItemStatus(%s) = %q, want %q", tt.payload.WebhookCode, got, tt.want)
+		}
+	}
+}
+
+// Tests that deliveries of the same body share a dedupe key whatever their token, and other items' do not.
+func TestWebhookDedupeKey(t *testing.T) {
+	body := []byte(`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item-1"}`)
+	other := []byte(`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item-2"}`)
+
+	if webhookDedupeKey(body, "item-1") != webhookDedupeKey(body, "item-1") {
+		t.Errorf("webhookDedupeKey differs for the same body")
+	}
+	if webhookDedupeKey(body, "item-1") == webhookDedupeKey(other, "item-2") {
+		t.Errorf("webhookDedupeKey matches for different items")
+	}
+}
+
+// Tests that a retry signed with a new token is not processed again, while the same body after the dedupe window is.
+func TestHandlePlaidWebhookRepeatedBody(t *testing.T) {
+	var mu sync.Mutex
+	var events []database.WebhookEvent
+	processed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/rest/v1/webhook_events" && r.Method == http.MethodGet:
+			since, err := time.Parse(time.RFC3339, strings.TrimPrefix(r.URL.Query().Get("received_at"), "gte."))
+			if err != nil {
+				t.Errorf("received_at filter: %v", err)
+			}
+			found := []database.WebhookEvent{}
+			for i := len(events) - 1; i >= 0; i-- {
+				if "eq."+events[i].DedupeKey == r.URL.Query().Get("dedupe_key") && !events[i].ReceivedAt.Before(since) {
+					found = append(found, events[i])
+				}
+			}
+			_ = json.NewEncoder(w).Encode(found)
+		case r.URL.Path == "/rest/v1/webhook_events" && r.Method == http.MethodPost:
+			var event database.WebhookEvent
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			event.ID = int64(len(events) + 1)
+			events = append(events, event)
+			_ = json.NewEncoder(w).Encode([]database.WebhookEvent{event})
+		case r.URL.Path == "/rest/v1/webhook_events" && r.Method == http.MethodPatch:
+			var update struct {
+				Outcome        string `json:"outcome"`
+				DuplicateCount int    `json:"duplicate_count"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			for i := range events {
+				if r.URL.Query().Get("id") == "eq."+strconv.FormatInt(events[i].ID, 10) {
+					events[i].Outcome = update.Outcome
+					events[i].DuplicateCount = update.DuplicateCount
+				}
+			}
+		case r.URL.Path == "/rest/v1/plaid_items" && r.Method == http.MethodPatch:
+			processed++
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("SUPABASE_URL", server.URL)
+	t.Setenv("SUPABASE_SERVICE_ROLE_KEY", "test")
+	db, err := database.NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item-1"}`
+	deliver := func(iat int64) {
+		request := httptest.NewRequest(http.MethodPost, "/api/webhooks/plaid", strings.NewReader(body))
+		request.Header.Set("Plaid-Verification", plaidVerificationToken(iat))
+		recorder := httptest.NewRecorder()
+		HandlePlaidWebhook(recorder, request, apiDependencies{db: db})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("delivery iat=%d status = %d, want 200", iat, recorder.Code)
+		}
+	}
+
+	// Plaid signs the retry with a new token.
+	deliver(1772445660)
+	deliver(1772445690)
+	if processed != 1 || len(events) != 1 || events[0].DuplicateCount != 1 {
+		t.Fatalf("processed = %d, events = %d, want one processed event with one duplicate", processed, len(events))
+	}
+
+	// The same body once the window has passed from the stored event's receipt is a new notification.
+	mu.Lock()
+	events[0].ReceivedAt = events[0].ReceivedAt.Add(-webhookDedupeWindow - time.Second)
+	mu.Unlock()
+	deliver(1772446000)
+	if processed != 2 || len(events) != 2 {
+		t.Errorf("processed = %d, events = %d, want 2 and 2", processed, len(events))
+	}
+}
+
+// Returns an unsigned Plaid-Verification style JWT with the given iat claim.
+func plaidVerificationToken(iat int64) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","kid":"test"}`))
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"iat":` + strconv.FormatInt(iat, 10) + `,"request_body_sha256":"x"}`))
+	return header + "." + claims + ".signature"
+}
+
+// Tests that multi-value headers are joined into one value.
+func TestWebhookHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Add("X-Forwarded-For", "10.0.0.1")
+	header.Add("X-Forwarded-For", "10.0.0.2")
+
+	got := webhookHeaders(header)
+	if got["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got["Content-Type"])
+	}
+	if got["X-Forwarded-For"] != "10.0.0.1, 10.0.0.2" {
+		t.Errorf("X-Forwarded-For = %q, want %q", got["X-Forwarded-For"], "10.0.0.1, 10.0.0.2")
+	}
+}
+
+// Tests the outcome for webhooks that are not processed against the database.
+func TestApplyPlaidWebhookWithoutProcessing(t *testing.T) {
+	outcome, err := applyPlaidWebhook(context.Background(), nil, plaidWebhookPayload{WebhookType: "ITEM", WebhookCode: "WEBHOOK_UPDATE_ACKNOWLEDGED"})
+	if outcome != webhookOutcomeIgnored || err != nil {
+		t.Errorf("unhandled webhook = (%q, %v), want (%q, nil)", outcome, err, webhookOutcomeIgnored)
+	}
+
+	outcome, err = applyPlaidWebhook(context.Background(), nil, plaidWebhookPayload{WebhookType: "TRANSACTIONS", WebhookCode: "SYNC_UPDATES_AVAILABLE"})
+	if outcome != webhookOutcomeFailed || !errors.Is(err, errWebhookMissingItemID) {
+		t.Errorf("missing item_id = (%q, %v), want (%q, %v)", outcome, err, webhookOutcomeFailed, errWebhookMissingItemID)
+	}
+}
@@ -12,8 +12,14 @@ interface Category {
 // Budget API response type.
 interface BudgetResponse {
   month: string
+  effectiveMonth?: string
+  totalCents?: number
   allocations: Record<string, number>
   spent: Record<string, number>
+  available?: Record<string, number>
+  carriedIn?: Record<string, number>
+  projectedCents?: Record<string, number>
+  projectedOverUnder?: Record<string, number>
 }
 
 // Categories response type.
@@ -40,11 +46,16 @@ export function BudgetTracker() {
   })
   const [allocations, setAllocations] = useState<Record<string, number>>({})
   const [spent, setSpent] = useState<Record<string, number>>({})
+  const [available, setAvailable] = useState<Record<string, number>>({})
+  const [carriedIn, setCarriedIn] = useState<Record<string, number>>({})
+  const [projected, setProjected] = useState<Record<string, number>>({})
+  const [projectedOverUnder, setProjectedOverUnder] = useState<Record<string, number>>({})
   const [loading, setLoading] = useState(false)
   const [saving, setSaving] = useState(false)
   const [error, setError] = useState<string | null>(null)
   const [successMessage, setSuccessMessage] = useState<string | null>(null)
   const [totalBudget, setTotalBudget] = useState<string>('')
+  const [effectiveMonth, setEffectiveMonth] = useState<string | null>(null)
 
   // Loads the categories from the backend.
   const loadCategories = useCallback(async () => {
@@ -74,28 +85,35 @@ export function BudgetTracker() {
       const res = await apiRequest<BudgetResponse>(`/api/budget?month=${encodeURIComponent(month)}`)
       const nextAllocations = res.allocations ?? {}
       setAllocations(nextAllocations)
+      setEffectiveMonth(res.effectiveMonth ?? null)
+      setAvailable(res.available ?? {})
+      setCarriedIn(res.carriedIn ?? {})
+      setProjected(month === currentMonth() ? (res.projectedCents ?? {}) : {})
+      setProjectedOverUnder(res.projectedOverUnder ?? {})
 
-      // Map data from name to ID
-      const spentByName = res.spent ?? {}
-      const spentById: Record<string, number> = {}
+      // Keeps spend for expense categories only.
+      const spentById = res.spent ?? {}
+      const expenseSpent: Record<string, number> = {}
       categories.forEach((cat) => {
         if (cat.expense) {
-          const val = spentByName[cat.name] ?? 0
-          spentById[String(cat.id)] = val
+          expenseSpent[String(cat.id)] = spentById[String(cat.id)] ?? 0
         }
       })
-      setSpent(spentById)
+      setSpent(expenseSpent)
 
-      // Computes the current budget allocations.
-      const totalAllocatedCents = Object.values(nextAllocations).reduce(
-        (sum, value) => sum + (typeof value === 'number' ? value : 0),
-        0,
-      )
+      // Uses the stored total, falling back to the sum of allocations.
+      const totalAllocatedCents =
+        res.totalCents ??
+        Object.values(nextAllocations).reduce(
+          (sum, value) => sum + (typeof value === 'number' ? value : 0),
+          0,
+        )
       setTotalBudget(totalAllocatedCents > 0 ? (totalAllocatedCents / 100).toString() : '')
     } catch (err: unknown) {
       setError(err instanceof Error ? err.message : 'Failed to load budget')
       setAllocations({})
       setSpent({})
+      setEffectiveMonth(null)
     } finally {
       setLoading(false)
     }
@@ -203,9 +221,14 @@ export function BudgetTracker() {
     try {
       await apiRequest('/api/budget', {
         method: 'PUT',
-        body: JSON.stringify({ allocations: normalizedAllocations }),
+        body: JSON.stringify({
+          allocations: normalizedAllocations,
+          totalCents: targetCents,
+          effectiveMonth: month,
+        }),
       })
-      setSuccessMessage('Budget saved. It applies to all months until you change it.')
+      setEffectiveMonth(month)
+      setSuccessMessage(`Budget saved. It applies from ${month} until the next change.`)
     } catch (err: unknown) {
       setError(err instanceof Error ? err.message : 'Failed to save budget')
     } finally {
@@ -246,7 +269,9 @@ export function BudgetTracker() {
               ))}
             </select>
             <p className="mt-2 text-sm text-zinc-500 font-medium ml-1">
-              Budget numbers are global; the month only changes the spent column.
+              {effectiveMonth
+                ? `Budget in effect since ${effectiveMonth}; saving creates a version starting ${month}.`
+                : `No budget set for ${month} yet; saving creates one starting this month.`}
             </p>
           </div>
           <div className="flex-1 min-w-[240px]">
@@ -333,7 +358,10 @@ export function BudgetTracker() {
                         const key = String(category.id)
                         const allocatedCents = allocations[key] ?? 0
                         const spentCents = spent[key] ?? 0
-                        const remainingCents = allocatedCents + spentCents
+                        const carriedInCents = carriedIn[key] ?? 0
+                        const projectedCents = projected[key]
+                        const overUnderCents = projectedOverUnder[key] ?? 0
+                        const remainingCents = (available[key] ?? allocatedCents) + spentCents
 
                         return (
                           <tr
@@ -342,6 +370,11 @@ export function BudgetTracker() {
                           >
                             <td className="px-6 py-4 font-bold text-white text-sm">
                               {category.name}
+                              {carriedInCents !== 0 && (
+                                <span className="block text-xs font-medium text-zinc-500">
+                                  {formatCurrency(carriedInCents)} carried in
+                                </span>
+                              )}
                             </td>
                             <td className="px-6 py-4 text-right text-sm">
                               <div className="flex justify-end items-center">
@@ -372,9 +405,16 @@ export function BudgetTracker() {
                                   {formatCurrency(spentCents)}
                                 </span>
                               )}
+                              {projectedCents !== undefined && projectedCents !== spentCents && (
+                                <span
+                                  className={`block text-xs font-medium ${overUnderCents < 0 ? 'text-red-400' : 'text-zinc-500'}`}
+                                >
+                                  Projected {formatCurrency(projectedCents)}
+                                </span>
+                              )}
                             </td>
                             <td className="px-6 py-4 text-right text-sm">
-                              {allocatedCents === 0 ? (
+                              {allocatedCents === 0 && carriedInCents === 0 ? (
                                 <span className="text-zinc-600 text-sm font-bold uppercase tracking-tighter">
                                   No budget
                                 </span>
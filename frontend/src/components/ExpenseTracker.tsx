@@ -128,6 +128,26 @@ export function ExpenseTracker() {
     }
   }, [month, categoryId, search])
 
+  // Sets a transaction's category by hand; syncs keep the choice.
+  const updateCategory = async (transaction: Transaction, newCategoryId: number) => {
+    try {
+      const path = `/api/transactions/${transaction.id}/category`
+      const category = await apiRequest<Category>(path, {
+        method: 'PUT',
+        body: JSON.stringify({ categoryId: newCategoryId }),
+      })
+      setTransactions((current) =>
+        current.map((tx) =>
+          tx.id === transaction.id
+            ? { ...tx, categoryId: category.id, categoryName: category.name }
+            : tx,
+        ),
+      )
+    } catch (err: unknown) {
+      setError(err instanceof Error ? err.message : 'Failed to update category')
+    }
+  }
+
   // Loads the transactions on mount and when filters change.
   useEffect(() => {
     void loadTransactions()
@@ -454,9 +474,18 @@ export function ExpenseTracker() {
                       )}
                     </td>
                     <td className="px-6 py-4">
-                      <span className="bg-zinc-800 text-zinc-300 px-3 py-1 rounded-full text-[10px] font-bold border border-border">
-                        {tx.categoryName ?? 'UNCATEGORIZED'}
-                      </span>
+                      <select
+                        value={tx.categoryId != null ? String(tx.categoryId) : ''}
+                        onChange={(e) => void updateCategory(tx, Number(e.target.value))}
+                        className="bg-zinc-800 text-zinc-300 px-3 py-1 rounded-full text-[10px] font-bold border border-border focus:outline-none cursor-pointer"
+                      >
+                        {tx.categoryId == null && <option value="">UNCATEGORIZED</option>}
+                        {categories.map((c) => (
+                          <option key={c.id} value={String(c.id)}>
+                            {c.name}
+                          </option>
+                        ))}
+                      </select>
                       {tx.pending && (
                         <span className="ml-2 text-[10px] font-bold text-orange-400 uppercase">
                           Pending
@@ -36,6 +36,8 @@ interface HoldingDataPoint {
   symbol: string
   quantity?: number
   valueCents: number
+  // Tier the point comes from; weekly and monthly points stand in for pruned daily rows.
+  tier?: 'daily' | 'weekly' | 'monthly'
 }
 
 // Holdings history response in JSON format.
@@ -95,6 +97,9 @@ export function Portfolio() {
   const [accountMonthly, setAccountMonthly] = useState<SnapshotDataPoint[]>([])
   const [accountMonthlyLoading, setAccountMonthlyLoading] = useState(false)
 
+  const [holdingMonthly, setHoldingMonthly] = useState<HoldingDataPoint[]>([])
+  const [holdingMonthlyLoading, setHoldingMonthlyLoading] = useState(false)
+
   const [yearlySummaryYear, setYearlySummaryYear] = useState<string>('')
   const [yearlySummary, setYearlySummary] = useState<YearlyPortfolioSummaryResponse | null>(null)
   const [yearlySummaryLoading, setYearlySummaryLoading] = useState(false)
@@ -174,10 +179,12 @@ export function Portfolio() {
     if (!selected || selected.type === 'total') {
       setHistoryDaily([])
       setAccountMonthly([])
+      setHoldingMonthly([])
       return
     }
     // Loads the holdings history for an account.
     if (selected.type === 'account') {
+      setHoldingMonthly([])
       setHistoryLoading(true)
       apiRequest<HoldingsHistoryResponse>(
         `/api/portfolio/holdings/history?accountId=${encodeURIComponent(selected.accountId)}`,
@@ -204,6 +211,19 @@ export function Portfolio() {
         .then((data) => setHistoryDaily(data.daily))
         .catch(() => setHistoryDaily([]))
         .finally(() => setHistoryLoading(false))
+      // Loads a year of history, stitched from the daily, weekly and monthly tiers.
+      const yearAgo = new Date()
+      yearAgo.setFullYear(yearAgo.getFullYear() - 1)
+      const month = String(yearAgo.getMonth() + 1).padStart(2, '0')
+      const day = String(yearAgo.getDate()).padStart(2, '0')
+      const from = `${yearAgo.getFullYear()}-${month}-${day}`
+      setHoldingMonthlyLoading(true)
+      apiRequest<HoldingsHistoryResponse>(
+        `/api/portfolio/holdings/history?symbol=${encodeURIComponent(selected.symbol)}&from=${from}`,
+      )
+        .then((data) => setHoldingMonthly(data.daily))
+        .catch(() => setHoldingMonthly([]))
+        .finally(() => setHoldingMonthlyLoading(false))
       return
     }
   }, [selected])
@@ -268,6 +288,32 @@ export function Portfolio() {
       .sort((a, b) => a.date.localeCompare(b.date))
   })()
 
+  // Gets the month-end values for the selected holding (each account's latest point in a month).
+  const holdingMonthlySeries = (() => {
+    if (!selected || selected.type !== 'holding' || !holdingMonthly.length) {
+      return []
+    }
+    const latestByMonthAccount: Record<string, HoldingDataPoint[]> = {}
+    holdingMonthly.forEach((holding) => {
+      const key = `${holding.date.slice(0, 7)}|${holding.accountId}`
+      const current = latestByMonthAccount[key]
+      if (!current || holding.date > current[0].date) {
+        latestByMonthAccount[key] = [holding]
+      } else if (holding.date === current[0].date) {
+        current.push(holding)
+      }
+    })
+    const byMonth: Record<string, number> = {}
+    Object.entries(latestByMonthAccount).forEach(([key, points]) => {
+      const month = `${key.slice(0, 7)}-01`
+      const total = points.reduce((sum, point) => sum + point.valueCents, 0)
+      byMonth[month] = (byMonth[month] ?? 0) + total
+    })
+    return Object.entries(byMonth)
+      .map(([date, valueCents]) => ({ date, valueCents }))
+      .sort((a, b) => a.date.localeCompare(b.date))
+  })()
+
   // Returns the label for the selected item.
   const selectedLabel = (() => {
     if (selected === null) {
@@ -804,12 +850,26 @@ export function Portfolio() {
                     </>
                   )}
                   {selected.type === 'holding' && (
-                    <div className="h-full flex items-center justify-center border border-dashed border-border rounded-3xl p-10 text-center">
-                      <p className="text-xs text-zinc-500 font-medium">
-                        Monthly performance per holding is not stored; only total portfolio and
-                        per-account monthly history are available.
-                      </p>
-                    </div>
+                    <>
+                      {holdingMonthlyLoading ? (
+                        <div className="h-full bg-zinc-800/50 animate-pulse rounded-3xl" />
+                      ) : holdingMonthlySeries.length === 0 ? (
+                        <div className="h-full flex items-center justify-center border border-dashed border-border rounded-3xl">
+                          <p className="text-sm text-zinc-600 font-medium">
+                            No monthly history for this holding yet.
+                          </p>
+                        </div>
+                      ) : (
+                        <TimeSeriesChart
+                          title=""
+                          data={holdingMonthlySeries.map((point) => ({
+                            date: point.date,
+                            value: point.valueCents / 100,
+                          }))}
+                          isMonthly={true}
+                        />
+                      )}
+                    </>
                   )}
                 </div>
               </div>
@@ -0,0 +1,9 @@
+-- Real-time balance refresh (POST /api/accounts/refresh)
+
+-- available_balance / balance_limit are reported by Plaid on every accounts call (NULL when the institution omits them).
+-- balances_as_of is only set by a real-time /accounts/balance/get refresh and drives the per-item rate limit;
+-- every other write of current_balance (the cached /accounts/get in the cron and token exchange) clears it.
+ALTER TABLE plaid_accounts
+  ADD COLUMN IF NOT EXISTS available_balance DECIMAL(15, 2),
+  ADD COLUMN IF NOT EXISTS balance_limit DECIMAL(15, 2),
+  ADD COLUMN IF NOT EXISTS balances_as_of TIMESTAMPTZ;
@@ -0,0 +1,23 @@
+-- Securities seen in Plaid holdings and investment activity, keyed by the symbol stored in daily_holdings.
+-- plaid_type is Plaid's security type (equity, etf, mutual fund, fixed income, cash, cryptocurrency, ...).
+CREATE TABLE IF NOT EXISTS securities (
+  symbol TEXT PRIMARY KEY,
+  security_id TEXT,
+  name TEXT,
+  plaid_type TEXT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- User-chosen asset class for a symbol; takes precedence over the class derived from Plaid's type.
+CREATE TABLE IF NOT EXISTS asset_class_overrides (
+  symbol TEXT PRIMARY KEY,
+  asset_class TEXT NOT NULL CHECK (asset_class IN ('us_equity', 'intl_equity', 'bonds', 'cash', 'crypto', 'real_estate')),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Target allocation: percent of the portfolio per asset class. Rows add up to 100.
+CREATE TABLE IF NOT EXISTS allocation_targets (
+  asset_class TEXT PRIMARY KEY CHECK (asset_class IN ('us_equity', 'intl_equity', 'bonds', 'cash', 'crypto', 'real_estate')),
+  target_percent NUMERIC(6, 3) NOT NULL CHECK (target_percent >= 0 AND target_percent <= 100),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Daily closing prices, imported from CSVs or fetched from the configured price provider.
+CREATE TABLE IF NOT EXISTS prices (
+  id BIGSERIAL PRIMARY KEY,
+  symbol TEXT NOT NULL,
+  date DATE NOT NULL,
+  close_cents BIGINT NOT NULL CHECK (close_cents > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (symbol, date)
+);
+
+-- Index funds the portfolio is compared against (e.g. VTI, SPY, AGG).
+CREATE TABLE IF NOT EXISTS benchmarks (
+  symbol TEXT PRIMARY KEY,
+  name TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
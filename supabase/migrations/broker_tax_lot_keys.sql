@@ -0,0 +1,10 @@
+-- Stable IDs for imported broker lots (POST /api/tax/lots/upload)
+
+-- Hash of account, symbol, acquired date, quantity and cost basis (plus a counter for identical rows), so a
+-- re-import keeps each lot's "csv:<lot_key>" ID and tax_lot_selections still point at it. Rows imported before
+-- this column existed are NULL and keep "csv:<id>" until the account is re-imported; the import then moves
+-- their selections to the new ID.
+ALTER TABLE broker_tax_lots
+  ADD COLUMN IF NOT EXISTS lot_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS broker_tax_lots_account_lot_key_idx ON broker_tax_lots (account_id, lot_key);
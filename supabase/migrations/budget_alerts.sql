@@ -0,0 +1,17 @@
+-- Budget threshold alerts (emailed after transaction syncs and in the nightly cron)
+
+-- Percent-of-allocation thresholds per category, e.g. {80,100}. Empty = no alerts.
+ALTER TABLE budget_category_settings
+  ADD COLUMN IF NOT EXISTS alert_thresholds INTEGER[] NOT NULL DEFAULT '{}';
+
+-- One row per alert sent; the unique key keeps an alert from sending twice in a month.
+CREATE TABLE IF NOT EXISTS budget_alerts_sent (
+  id BIGSERIAL PRIMARY KEY,
+  month DATE NOT NULL,
+  category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  threshold_percent INTEGER NOT NULL,
+  spent_cents BIGINT NOT NULL,
+  allocation_cents BIGINT NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (month, category_id, threshold_percent)
+);
@@ -0,0 +1,27 @@
+-- Category-ID-keyed budget allocations with a stored total (validated by PUT /api/budget)
+
+ALTER TABLE budget_versions
+  ADD COLUMN IF NOT EXISTS total_cents BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS legacy_allocations JSONB;
+
+-- Rewrites allocation keys to category IDs (name keys are matched by category name; keys matching no
+-- category are dropped). The original allocations are kept in legacy_allocations.
+UPDATE budget_versions bv
+SET legacy_allocations = bv.allocations,
+    allocations = COALESCE((
+      SELECT jsonb_object_agg(m.category_id::text, m.cents)
+      FROM (
+        SELECT c.id AS category_id, SUM((e.value #>> '{}')::bigint) AS cents
+        FROM jsonb_each(bv.allocations) e
+        JOIN categories c ON c.id::text = e.key OR c.name = e.key
+        GROUP BY c.id
+      ) m
+    ), '{}'::jsonb)
+WHERE bv.legacy_allocations IS NULL;
+
+-- Stores the total for existing versions.
+UPDATE budget_versions bv
+SET total_cents = COALESCE((
+  SELECT SUM((e.value #>> '{}')::bigint) FROM jsonb_each(bv.allocations) e
+), 0)
+WHERE bv.total_cents = 0;
@@ -0,0 +1,6 @@
+-- Month-end budget forecasting (GET /api/budget?month=)
+
+-- Spend per day of month (index 0 = day 1), kept when transactions are pruned so forecasts can use
+-- older months' day-of-month distribution. NULL for summaries written before this column existed.
+ALTER TABLE monthly_expense_summary
+  ADD COLUMN IF NOT EXISTS daily_cents BIGINT[];
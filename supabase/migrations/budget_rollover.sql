@@ -0,0 +1,14 @@
+-- Envelope-style rollover budgets (GET/PUT /api/budget/settings)
+
+-- Per-category budget settings. Categories without a row never roll over.
+-- max_carryover_cents caps how much unspent money carries into the next month (NULL = no cap); overspending always carries.
+-- reset_month (1-12) zeroes the carryover at the start of that month each year (NULL = never).
+-- start_month is the first month carryover accumulates from (NULL = first budget version).
+CREATE TABLE IF NOT EXISTS budget_category_settings (
+  category_id BIGINT PRIMARY KEY REFERENCES categories(id) ON DELETE CASCADE,
+  rollover BOOLEAN NOT NULL DEFAULT false,
+  max_carryover_cents BIGINT CHECK (max_carryover_cents IS NULL OR max_carryover_cents >= 0),
+  reset_month SMALLINT CHECK (reset_month IS NULL OR reset_month BETWEEN 1 AND 12),
+  start_month DATE,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
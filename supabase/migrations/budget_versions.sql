@@ -0,0 +1,17 @@
+-- Budget versions (GET /api/budget?month=, GET /api/budget/history)
+
+-- Each row holds the allocations in effect from effective_month (first day of the month) until the next version.
+CREATE TABLE IF NOT EXISTS budget_versions (
+  id BIGSERIAL PRIMARY KEY,
+  effective_month DATE NOT NULL UNIQUE,
+  allocations JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Carries the old global budget over as the first version, effective from the epoch so it covers all history.
+INSERT INTO budget_versions (effective_month, allocations, updated_at)
+SELECT DATE '1970-01-01', allocations, updated_at
+FROM budgets
+WHERE id = 1
+ON CONFLICT (effective_month) DO NOTHING;
@@ -0,0 +1,5 @@
+-- CUSIP from Plaid's security details, so the same fund held under different tickers (or under its CUSIP in
+-- a Fidelity statement) is grouped together.
+ALTER TABLE securities ADD COLUMN IF NOT EXISTS cusip TEXT;
+
+CREATE INDEX IF NOT EXISTS securities_cusip_idx ON securities (cusip);
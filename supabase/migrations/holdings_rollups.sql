@@ -0,0 +1,18 @@
+-- Holdings kept after their daily rows are pruned: the last held day of each week ('weekly') and of each month
+-- ('monthly'), per symbol per account. period_start is the week's Monday or the month's first day; as_of is the
+-- day the row was taken from.
+CREATE TABLE IF NOT EXISTS holdings_rollups (
+  id BIGSERIAL PRIMARY KEY,
+  tier TEXT NOT NULL CHECK (tier IN ('weekly', 'monthly')),
+  period_start DATE NOT NULL,
+  as_of DATE NOT NULL,
+  account_id TEXT NOT NULL,
+  symbol TEXT NOT NULL,
+  quantity NUMERIC(20, 8) NOT NULL,
+  value_cents BIGINT NOT NULL,
+  cost_basis_cents BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(tier, period_start, account_id, symbol)
+);
+
+CREATE INDEX IF NOT EXISTS holdings_rollups_tier_as_of_idx ON holdings_rollups (tier, as_of);
@@ -0,0 +1,22 @@
+-- Investment activity from Plaid /investments/transactions/get (buys, sells, dividends, fees, transfers).
+-- amount_cents follows the app convention: inflow to the account is positive, outflow is negative.
+CREATE TABLE IF NOT EXISTS investment_transactions (
+  id BIGSERIAL PRIMARY KEY,
+  plaid_investment_transaction_id TEXT NOT NULL UNIQUE,
+  account_id TEXT NOT NULL,
+  security_id TEXT,
+  symbol TEXT,
+  security_name TEXT,
+  date DATE NOT NULL,
+  name TEXT NOT NULL,
+  type TEXT NOT NULL,
+  subtype TEXT NOT NULL,
+  quantity NUMERIC(20, 8) NOT NULL,
+  price_cents BIGINT NOT NULL,
+  fees_cents BIGINT NOT NULL DEFAULT 0,
+  amount_cents BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS investment_transactions_date_idx ON investment_transactions (date DESC);
+CREATE INDEX IF NOT EXISTS investment_transactions_account_idx ON investment_transactions (account_id);
@@ -0,0 +1,6 @@
+-- Progress of scheduled jobs, e.g. the last date the daily sync processed, so missed days can be caught up.
+CREATE TABLE IF NOT EXISTS job_state (
+  name TEXT PRIMARY KEY,
+  last_processed_date DATE,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
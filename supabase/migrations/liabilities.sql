@@ -0,0 +1,23 @@
+-- Liabilities from Plaid /liabilities/get (credit cards, student loans, mortgages), refreshed by the nightly cron.
+-- One row per account; fields that do not apply to the liability type are NULL.
+CREATE TABLE IF NOT EXISTS liabilities (
+  id BIGSERIAL PRIMARY KEY,
+  account_id TEXT NOT NULL UNIQUE REFERENCES plaid_accounts(account_id) ON DELETE CASCADE,
+  liability_type TEXT NOT NULL, -- 'credit', 'student' or 'mortgage'
+  aprs JSONB NOT NULL DEFAULT '[]'::jsonb, -- credit only: [{apr_percentage, apr_type, balance_subject_to_apr_cents, interest_charge_cents}]
+  interest_rate_percentage NUMERIC(8, 4),
+  interest_rate_type TEXT,
+  last_statement_balance_cents BIGINT,
+  last_statement_issue_date DATE,
+  minimum_payment_cents BIGINT,
+  next_payment_due_date DATE,
+  last_payment_amount_cents BIGINT,
+  last_payment_date DATE,
+  is_overdue BOOLEAN,
+  credit_limit_cents BIGINT,
+  origination_principal_cents BIGINT,
+  outstanding_interest_cents BIGINT,
+  payoff_date DATE,
+  loan_name TEXT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Where each close came from: 'plaid' (security close prices from holdings syncs), 'csv' (uploaded files)
+-- or 'provider' (the configured market-data provider).
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'csv';
+
+CREATE INDEX IF NOT EXISTS prices_date_idx ON prices (date);
@@ -0,0 +1,26 @@
+-- Sinking funds and annual budget goals (/api/budget/sinking-funds, GET /api/budget/annual)
+
+-- Savings goals for irregular expenses (insurance premiums, gifts, registration) due on a date.
+-- Transactions in category_ids (e.g. a transfer category for the fund) count as contributions from start_month on.
+CREATE TABLE IF NOT EXISTS sinking_funds (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  target_cents BIGINT NOT NULL CHECK (target_cents > 0),
+  due_date DATE NOT NULL,
+  start_month DATE NOT NULL,
+  category_ids BIGINT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Manual contributions (negative amounts record withdrawals).
+CREATE TABLE IF NOT EXISTS sinking_fund_contributions (
+  id BIGSERIAL PRIMARY KEY,
+  fund_id BIGINT NOT NULL REFERENCES sinking_funds(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  amount_cents BIGINT NOT NULL,
+  note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sinking_fund_contributions_fund_id ON sinking_fund_contributions(fund_id);
@@ -0,0 +1,30 @@
+-- Open lots imported from broker lot CSVs. They seed the tax-lot ledger for shares acquired
+-- before the synced investment activity begins.
+CREATE TABLE IF NOT EXISTS broker_tax_lots (
+  id BIGSERIAL PRIMARY KEY,
+  account_id TEXT NOT NULL,
+  symbol TEXT NOT NULL,
+  acquired_date DATE NOT NULL,
+  quantity NUMERIC(20, 8) NOT NULL CHECK (quantity > 0),
+  cost_basis_cents BIGINT NOT NULL,
+  imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS broker_tax_lots_account_symbol_idx ON broker_tax_lots (account_id, symbol);
+
+-- Lot selection method per account; accounts without a row use FIFO.
+CREATE TABLE IF NOT EXISTS tax_lot_settings (
+  account_id TEXT PRIMARY KEY,
+  method TEXT NOT NULL DEFAULT 'fifo' CHECK (method IN ('fifo', 'lifo', 'hifo', 'specific')),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Specific identification: the lots (and shares of each) a sale was matched against.
+-- sale_transaction_id is the sale's plaid_investment_transaction_id.
+CREATE TABLE IF NOT EXISTS tax_lot_selections (
+  id BIGSERIAL PRIMARY KEY,
+  sale_transaction_id TEXT NOT NULL,
+  lot_id TEXT NOT NULL,
+  quantity NUMERIC(20, 8) NOT NULL CHECK (quantity > 0),
+  UNIQUE (sale_transaction_id, lot_id)
+);
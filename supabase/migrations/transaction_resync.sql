@@ -0,0 +1,6 @@
+-- Full transaction resync
+
+-- Set when a transaction's category is changed by hand. Sync and resync keep the stored
+-- category for these rows instead of re-applying rules / Plaid categories.
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS category_overridden BOOLEAN NOT NULL DEFAULT false;
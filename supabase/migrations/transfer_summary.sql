@@ -0,0 +1,14 @@
+-- Transfer category history for sinking funds (/api/budget/sinking-funds)
+
+-- Monthly totals for non-expense (transfer) categories, kept when transactions are pruned so funds on a
+-- transfer category keep their older contributions. Same sign as monthly_expense_summary (outflows positive).
+-- Months pruned before this table existed have no rows.
+CREATE TABLE IF NOT EXISTS monthly_transfer_summary (
+  id BIGSERIAL PRIMARY KEY,
+  month DATE NOT NULL,
+  category_id BIGINT NOT NULL REFERENCES categories(id),
+  total_cents BIGINT NOT NULL DEFAULT 0,
+  transaction_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(month, category_id)
+);
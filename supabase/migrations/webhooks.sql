@@ -0,0 +1,34 @@
+-- Plaid webhook handling
+
+-- Flags set by webhooks: HOLDINGS / INVESTMENTS_TRANSACTIONS queue a holdings refresh,
+-- ITEM NEW_ACCOUNTS_AVAILABLE marks that accounts can be added through Link update mode.
+ALTER TABLE plaid_items
+  ADD COLUMN IF NOT EXISTS holdings_refresh_pending BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS new_accounts_available BOOLEAN NOT NULL DEFAULT false;
+
+-- Log of every inbound webhook delivery. dedupe_key is the item id plus the sha256 of the raw body;
+-- a delivery matching an event received in the last 5 minutes is a retry of it (Plaid signs each retry
+-- with a new token, but the body is the same), while later notifications with the same body get their own row.
+CREATE TABLE IF NOT EXISTS webhook_events (
+  id BIGSERIAL PRIMARY KEY,
+  source TEXT NOT NULL DEFAULT 'plaid',
+  dedupe_key TEXT NOT NULL,
+  webhook_type TEXT NOT NULL DEFAULT '',
+  webhook_code TEXT NOT NULL DEFAULT '',
+  item_id TEXT,
+  raw_body TEXT NOT NULL,
+  headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  processed_at TIMESTAMPTZ,
+  outcome TEXT NOT NULL DEFAULT 'received',
+  error TEXT,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  duplicate_count INTEGER NOT NULL DEFAULT 0
+);
+
+-- Earlier versions of this table made dedupe_key unique.
+ALTER TABLE webhook_events DROP CONSTRAINT IF EXISTS webhook_events_dedupe_key_key;
+
+CREATE INDEX IF NOT EXISTS webhook_events_dedupe_key_idx ON webhook_events (dedupe_key, received_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_events_received_at_idx ON webhook_events (received_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_events_item_id_idx ON webhook_events (item_id);